@@ -0,0 +1,74 @@
+package google
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-provider-google/google/tpgresource"
+	transport_tpg "github.com/hashicorp/terraform-provider-google/google/transport"
+)
+
+// artifactRegistryRepositoryUrl returns the URL of the repository of an Artifact
+// Registry data source, with its project.
+func artifactRegistryRepositoryUrl(d *schema.ResourceData, config *transport_tpg.Config) (string, string, error) {
+	project, err := tpgresource.GetProject(d, config)
+	if err != nil {
+		return "", "", err
+	}
+	return fmt.Sprintf("%sprojects/%s/locations/%s/repositories/%s", config.ArtifactRegistryBasePath, project, d.Get("location"), d.Get("repository_id")), project, nil
+}
+
+// artifactRegistryPackageUrl returns the URL of a package in repositoryUrl.
+// Package IDs such as Docker image names may contain slashes, which have to be
+// escaped.
+func artifactRegistryPackageUrl(repositoryUrl, pkg string) string {
+	return fmt.Sprintf("%s/packages/%s", repositoryUrl, url.PathEscape(pkg))
+}
+
+// artifactRegistryResourceId returns the unescaped ID at the end of the name of
+// an Artifact Registry resource, e.g. the package ID of a package.
+func artifactRegistryResourceId(name string) string {
+	id := name[strings.LastIndex(name, "/")+1:]
+	if unescaped, err := url.PathUnescape(id); err == nil {
+		return unescaped
+	}
+	return id
+}
+
+// listArtifactRegistryResources returns the resources in the field key of all
+// the pages of a list request.
+func listArtifactRegistryResources(config *transport_tpg.Config, userAgent, project, rawUrl, key string, params map[string]string) ([]interface{}, error) {
+	var items []interface{}
+	query := make(map[string]string)
+	for k, v := range params {
+		if v != "" {
+			query[k] = v
+		}
+	}
+	for {
+		u, err := transport_tpg.AddQueryParams(rawUrl, query)
+		if err != nil {
+			return nil, err
+		}
+		res, err := transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
+			Config:    config,
+			Method:    "GET",
+			Project:   project,
+			RawURL:    u,
+			UserAgent: userAgent,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if page, ok := res[key].([]interface{}); ok {
+			items = append(items, page...)
+		}
+		token, _ := res["nextPageToken"].(string)
+		if token == "" {
+			return items, nil
+		}
+		query["pageToken"] = token
+	}
+}
@@ -0,0 +1,379 @@
+package google
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"google.golang.org/api/bigquery/v2"
+	"google.golang.org/api/googleapi"
+
+	"github.com/hashicorp/terraform-provider-google/google/tpgresource"
+	transport_tpg "github.com/hashicorp/terraform-provider-google/google/transport"
+)
+
+// bigQueryDryRunRequest describes a query to validate with a BigQuery dry run
+// job during plan.
+type bigQueryDryRunRequest struct {
+	// Field is the attribute the query comes from, used in error messages.
+	Field          string
+	Project        string
+	Location       string
+	Query          string
+	UseLegacySql   bool
+	DefaultDataset *bigquery.DatasetReference
+	Parameters     []*bigquery.QueryParameter
+	// IgnoreNotFound is a substring of "Not found" errors to ignore, for
+	// routines created in a dataset created in the same apply.
+	IgnoreNotFound string
+}
+
+// bigQueryDryRunCustomizeDiffs are the dry runs of the generated BigQuery
+// resources holding SQL.
+var bigQueryDryRunCustomizeDiffs = map[string]schema.CustomizeDiffFunc{
+	"google_bigquery_data_transfer_config": bigQueryDataTransferConfigDryRunCustomizeDiff,
+	"google_bigquery_job":                  bigQueryJobDryRunCustomizeDiff,
+	"google_bigquery_routine":              bigQueryRoutineDryRunCustomizeDiff,
+}
+
+// withBigQueryDryRun adds the dry run to the resources of
+// bigQueryDryRunCustomizeDiffs, together with the dry_run_bytes_processed
+// attribute showing its estimate in the plan. It takes the result of
+// mergeResourceMaps, so that it wraps the provider's resource map.
+func withBigQueryDryRun(resources map[string]*schema.Resource, err error) (map[string]*schema.Resource, error) {
+	if err != nil {
+		return resources, err
+	}
+	for name, dryRun := range bigQueryDryRunCustomizeDiffs {
+		r, ok := resources[name]
+		if !ok {
+			return nil, fmt.Errorf("BigQuery dry run: resource %s not found", name)
+		}
+		r.Schema["dry_run_bytes_processed"] = &schema.Schema{
+			Type:        schema.TypeInt,
+			Computed:    true,
+			Description: `The bytes the SQL processes, as estimated by the BigQuery dry run during the last plan that changed it. Only set when the provider's bigquery_dry_run is enabled.`,
+		}
+		if r.CustomizeDiff != nil {
+			r.CustomizeDiff = customdiff.All(r.CustomizeDiff, dryRun)
+		} else {
+			r.CustomizeDiff = dryRun
+		}
+	}
+	return resources, nil
+}
+
+// bigQueryDryRunEnabled returns whether the provider is configured to dry run
+// the SQL of BigQuery resources during plan.
+func bigQueryDryRunEnabled(meta interface{}) bool {
+	config, ok := meta.(*transport_tpg.Config)
+	return ok && config.BigQueryDryRun
+}
+
+// bigQueryDryRun runs the query as a dry run job, which validates it and
+// estimates the bytes it processes without running it. The estimate is
+// checked against the provider's bigquery_dry_run_max_bytes_billed, if set,
+// and shown in the plan as dry_run_bytes_processed.
+func bigQueryDryRun(diff *schema.ResourceDiff, config *transport_tpg.Config, userAgent string, req bigQueryDryRunRequest) error {
+	useLegacySql := req.UseLegacySql
+	job := &bigquery.Job{
+		JobReference: &bigquery.JobReference{
+			ProjectId: req.Project,
+			Location:  req.Location,
+		},
+		Configuration: &bigquery.JobConfiguration{
+			DryRun: true,
+			Query: &bigquery.JobConfigurationQuery{
+				Query:           req.Query,
+				UseLegacySql:    &useLegacySql,
+				DefaultDataset:  req.DefaultDataset,
+				QueryParameters: req.Parameters,
+				ForceSendFields: []string{"UseLegacySql"},
+			},
+		},
+	}
+	if len(req.Parameters) > 0 {
+		job.Configuration.Query.ParameterMode = "NAMED"
+	}
+
+	log.Printf("[DEBUG] Dry running BigQuery query from %s", req.Field)
+	res, err := config.NewBigQueryClient(userAgent).Jobs.Insert(req.Project, job).Do()
+	if err != nil {
+		if req.IgnoreNotFound != "" && isBigQueryDryRunNotFound(err, req.IgnoreNotFound) {
+			log.Printf("[DEBUG] Skipping BigQuery dry run of %s, %s does not exist yet: %s", req.Field, req.IgnoreNotFound, err)
+			return diff.SetNewComputed("dry_run_bytes_processed")
+		}
+		if isBigQueryDryRunNotFound(err, "") {
+			// Tables created in the same apply are only skipped when the
+			// query references them through their attributes, which makes
+			// it unknown until then.
+			return fmt.Errorf("BigQuery dry run of %s failed: %s. If it's created in the same apply, reference it through the attributes of its resource", req.Field, bigQueryDryRunErrorMessage(err))
+		}
+		return fmt.Errorf("BigQuery dry run of %s failed: %s", req.Field, bigQueryDryRunErrorMessage(err))
+	}
+
+	var bytesProcessed int64
+	if res.Statistics != nil {
+		bytesProcessed = res.Statistics.TotalBytesProcessed
+	}
+	log.Printf("[INFO] BigQuery dry run of %s succeeded, the query will process an estimated %d bytes", req.Field, bytesProcessed)
+	if err := diff.SetNew("dry_run_bytes_processed", int(bytesProcessed)); err != nil {
+		return err
+	}
+
+	if config.BigQueryDryRunMaxBytesBilled > 0 && bytesProcessed > config.BigQueryDryRunMaxBytesBilled {
+		return fmt.Errorf("BigQuery dry run of %s estimated %d bytes processed, which exceeds the provider's bigquery_dry_run_max_bytes_billed of %d bytes", req.Field, bytesProcessed, config.BigQueryDryRunMaxBytesBilled)
+	}
+	return nil
+}
+
+func bigQueryDryRunErrorMessage(err error) string {
+	if gerr, ok := errwrap.GetType(err, &googleapi.Error{}).(*googleapi.Error); ok && gerr.Message != "" {
+		return gerr.Message
+	}
+	return err.Error()
+}
+
+// isBigQueryDryRunNotFound reports whether a dry run failed because the query
+// references a table, view, routine or dataset that doesn't exist, and whose
+// name contains resource.
+func isBigQueryDryRunNotFound(err error, resource string) bool {
+	gerr, ok := errwrap.GetType(err, &googleapi.Error{}).(*googleapi.Error)
+	return ok && gerr.Code == 404 && strings.HasPrefix(gerr.Message, "Not found:") && strings.Contains(gerr.Message, resource)
+}
+
+// bigQueryScheduledQueryParameters returns the parameters that the Data
+// Transfer Service passes to scheduled queries that reference them.
+func bigQueryScheduledQueryParameters(query string, now time.Time) []*bigquery.QueryParameter {
+	var params []*bigquery.QueryParameter
+	if strings.Contains(query, "@run_time") {
+		params = append(params, &bigquery.QueryParameter{
+			Name:           "run_time",
+			ParameterType:  &bigquery.QueryParameterType{Type: "TIMESTAMP"},
+			ParameterValue: &bigquery.QueryParameterValue{Value: now.UTC().Format("2006-01-02 15:04:05")},
+		})
+	}
+	if strings.Contains(query, "@run_date") {
+		params = append(params, &bigquery.QueryParameter{
+			Name:           "run_date",
+			ParameterType:  &bigquery.QueryParameterType{Type: "DATE"},
+			ParameterValue: &bigquery.QueryParameterValue{Value: now.UTC().Format("2006-01-02")},
+		})
+	}
+	return params
+}
+
+// bigQueryStandardSqlTypeDdl converts a StandardSqlDataType JSON, as used by
+// routine arguments and return types, to its GoogleSQL type name.
+func bigQueryStandardSqlTypeDdl(dataType string) (string, error) {
+	var t map[string]interface{}
+	if err := json.Unmarshal([]byte(dataType), &t); err != nil {
+		return "", err
+	}
+	return bigQueryStandardSqlTypeDdlFromMap(t)
+}
+
+func bigQueryStandardSqlTypeDdlFromMap(t map[string]interface{}) (string, error) {
+	typeKind, _ := t["typeKind"].(string)
+	switch typeKind {
+	case "":
+		return "", fmt.Errorf("data type %v has no typeKind", t)
+	case "ARRAY":
+		element, ok := t["arrayElementType"].(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("ARRAY data type has no arrayElementType")
+		}
+		elementType, err := bigQueryStandardSqlTypeDdlFromMap(element)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("ARRAY<%s>", elementType), nil
+	case "STRUCT":
+		structType, _ := t["structType"].(map[string]interface{})
+		fields, _ := structType["fields"].([]interface{})
+		var parts []string
+		for _, raw := range fields {
+			field, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			fieldType, ok := field["type"].(map[string]interface{})
+			if !ok {
+				return "", fmt.Errorf("STRUCT field %v has no type", field["name"])
+			}
+			ddl, err := bigQueryStandardSqlTypeDdlFromMap(fieldType)
+			if err != nil {
+				return "", err
+			}
+			if name, ok := field["name"].(string); ok && name != "" {
+				ddl = fmt.Sprintf("`%s` %s", name, ddl)
+			}
+			parts = append(parts, ddl)
+		}
+		return fmt.Sprintf("STRUCT<%s>", strings.Join(parts, ", ")), nil
+	default:
+		return typeKind, nil
+	}
+}
+
+// bigQueryRoutineDryRunDdl returns the DDL statement that creates the routine,
+// so that dry running it validates the routine body.
+func bigQueryRoutineDryRunDdl(project, dataset, routine, routineType, body string, arguments []interface{}, returnType string) (string, error) {
+	var args []string
+	for _, raw := range arguments {
+		arg, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		argType := "ANY TYPE"
+		if arg["argument_kind"] != "ANY_TYPE" {
+			var err error
+			argType, err = bigQueryStandardSqlTypeDdl(arg["data_type"].(string))
+			if err != nil {
+				return "", fmt.Errorf("Error parsing data_type of argument %v: %s", arg["name"], err)
+			}
+		}
+		ddl := fmt.Sprintf("`%s` %s", arg["name"], argType)
+		if mode, ok := arg["mode"].(string); ok && mode != "" && routineType == "PROCEDURE" {
+			ddl = mode + " " + ddl
+		}
+		args = append(args, ddl)
+	}
+	name := fmt.Sprintf("`%s.%s.%s`(%s)", project, dataset, routine, strings.Join(args, ", "))
+
+	switch routineType {
+	case "PROCEDURE":
+		return fmt.Sprintf("CREATE OR REPLACE PROCEDURE %s\nBEGIN\n%s\nEND", name, body), nil
+	case "TABLE_VALUED_FUNCTION":
+		return fmt.Sprintf("CREATE OR REPLACE TABLE FUNCTION %s AS\n%s", name, body), nil
+	default:
+		returns := ""
+		if returnType != "" {
+			ddl, err := bigQueryStandardSqlTypeDdl(returnType)
+			if err != nil {
+				return "", fmt.Errorf("Error parsing return_type: %s", err)
+			}
+			returns = " RETURNS " + ddl
+		}
+		return fmt.Sprintf("CREATE OR REPLACE FUNCTION %s%s AS (\n%s\n)", name, returns, body), nil
+	}
+}
+
+// bigQueryDataTransferConfigDryRunCustomizeDiff dry runs the query of
+// scheduled queries when it changes.
+func bigQueryDataTransferConfigDryRunCustomizeDiff(_ context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+	if !bigQueryDryRunEnabled(meta) || diff.Get("data_source_id").(string) != "scheduled_query" {
+		return nil
+	}
+	if !diff.NewValueKnown("params") {
+		// Estimated once the query is known, during the next plan.
+		return diff.SetNewComputed("dry_run_bytes_processed")
+	}
+	if diff.Id() != "" && !diff.HasChange("params.query") {
+		return nil
+	}
+	query := diff.Get("params.query").(string)
+	if query == "" {
+		return nil
+	}
+
+	config := meta.(*transport_tpg.Config)
+	project, err := tpgresource.GetProjectFromDiff(diff, config)
+	if err != nil {
+		return err
+	}
+	return bigQueryDryRun(diff, config, config.UserAgent, bigQueryDryRunRequest{
+		Field:      "params.query",
+		Project:    project,
+		Location:   diff.Get("location").(string),
+		Query:      query,
+		Parameters: bigQueryScheduledQueryParameters(query, time.Now()),
+	})
+}
+
+// bigQueryRoutineDryRunCustomizeDiff dry runs the DDL that creates SQL
+// routines when their definition changes.
+func bigQueryRoutineDryRunCustomizeDiff(_ context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+	if !bigQueryDryRunEnabled(meta) {
+		return nil
+	}
+	if language := diff.Get("language").(string); language != "" && language != "SQL" {
+		return nil
+	}
+	for _, field := range []string{"definition_body", "arguments", "return_type", "routine_type", "dataset_id", "routine_id"} {
+		if !diff.NewValueKnown(field) {
+			return diff.SetNewComputed("dry_run_bytes_processed")
+		}
+	}
+	if diff.Id() != "" && !diff.HasChanges("definition_body", "arguments", "return_type", "routine_type") {
+		return nil
+	}
+
+	config := meta.(*transport_tpg.Config)
+	project, err := tpgresource.GetProjectFromDiff(diff, config)
+	if err != nil {
+		return err
+	}
+	dataset := diff.Get("dataset_id").(string)
+	ddl, err := bigQueryRoutineDryRunDdl(project, dataset, diff.Get("routine_id").(string), diff.Get("routine_type").(string),
+		diff.Get("definition_body").(string), diff.Get("arguments").([]interface{}), diff.Get("return_type").(string))
+	if err != nil {
+		return err
+	}
+	return bigQueryDryRun(diff, config, config.UserAgent, bigQueryDryRunRequest{
+		Field:          "definition_body",
+		Project:        project,
+		Query:          ddl,
+		IgnoreNotFound: fmt.Sprintf("Dataset %s:%s", project, dataset),
+	})
+}
+
+// bigQueryJobDryRunCustomizeDiff dry runs the query of query jobs before they
+// are created.
+func bigQueryJobDryRunCustomizeDiff(_ context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+	if !bigQueryDryRunEnabled(meta) || diff.Id() != "" {
+		return nil
+	}
+	if _, ok := diff.GetOk("query"); !ok {
+		return nil
+	}
+	// Values referencing resources created in the same apply are unknown.
+	for _, field := range []string{"query.0.query", "query.0.use_legacy_sql", "query.0.default_dataset", "location"} {
+		if !diff.NewValueKnown(field) {
+			return nil
+		}
+	}
+
+	config := meta.(*transport_tpg.Config)
+	project, err := tpgresource.GetProjectFromDiff(diff, config)
+	if err != nil {
+		return err
+	}
+	req := bigQueryDryRunRequest{
+		Field:        "query.0.query",
+		Project:      project,
+		Location:     diff.Get("location").(string),
+		Query:        diff.Get("query.0.query").(string),
+		UseLegacySql: diff.Get("query.0.use_legacy_sql").(bool),
+	}
+	if datasetId := diff.Get("query.0.default_dataset.0.dataset_id").(string); datasetId != "" {
+		req.DefaultDataset = &bigquery.DatasetReference{
+			ProjectId: diff.Get("query.0.default_dataset.0.project_id").(string),
+			DatasetId: datasetId,
+		}
+		if parts := bigqueryDatasetRegexp.FindStringSubmatch(datasetId); parts != nil {
+			req.DefaultDataset.ProjectId = parts[1]
+			req.DefaultDataset.DatasetId = parts[2]
+		}
+		if req.DefaultDataset.ProjectId == "" {
+			req.DefaultDataset.ProjectId = project
+		}
+	}
+	return bigQueryDryRun(diff, config, config.UserAgent, req)
+}
@@ -0,0 +1,154 @@
+package google
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"google.golang.org/api/googleapi"
+)
+
+func TestBigQueryRoutineDryRunDdl(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		RoutineType string
+		Body        string
+		Arguments   []interface{}
+		ReturnType  string
+		Expected    string
+	}{
+		"scalar function": {
+			RoutineType: "SCALAR_FUNCTION",
+			Body:        "x + y",
+			Arguments: []interface{}{
+				map[string]interface{}{"name": "x", "data_type": `{"typeKind":"INT64"}`},
+				map[string]interface{}{"name": "y", "argument_kind": "ANY_TYPE"},
+			},
+			ReturnType: `{"typeKind":"INT64"}`,
+			Expected:   "CREATE OR REPLACE FUNCTION `p.d.r`(`x` INT64, `y` ANY TYPE) RETURNS INT64 AS (\nx + y\n)",
+		},
+		"procedure": {
+			RoutineType: "PROCEDURE",
+			Body:        "SET y = x;",
+			Arguments: []interface{}{
+				map[string]interface{}{"name": "x", "mode": "IN", "data_type": `{"typeKind":"ARRAY","arrayElementType":{"typeKind":"STRING"}}`},
+				map[string]interface{}{"name": "y", "mode": "OUT", "data_type": `{"typeKind":"STRUCT","structType":{"fields":[{"name":"a","type":{"typeKind":"STRING"}}]}}`},
+			},
+			Expected: "CREATE OR REPLACE PROCEDURE `p.d.r`(IN `x` ARRAY<STRING>, OUT `y` STRUCT<`a` STRING>)\nBEGIN\nSET y = x;\nEND",
+		},
+		"table valued function": {
+			RoutineType: "TABLE_VALUED_FUNCTION",
+			Body:        "SELECT 1 AS x",
+			Expected:    "CREATE OR REPLACE TABLE FUNCTION `p.d.r`() AS\nSELECT 1 AS x",
+		},
+	}
+
+	for tn, tc := range cases {
+		ddl, err := bigQueryRoutineDryRunDdl("p", "d", "r", tc.RoutineType, tc.Body, tc.Arguments, tc.ReturnType)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tn, err)
+			continue
+		}
+		if ddl != tc.Expected {
+			t.Errorf("%s: expected %q, got %q", tn, tc.Expected, ddl)
+		}
+	}
+}
+
+func TestBigQueryScheduledQueryParameters(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2023, 5, 6, 7, 8, 9, 0, time.UTC)
+	params := bigQueryScheduledQueryParameters("SELECT @run_time, @run_date", now)
+	if len(params) != 2 {
+		t.Fatalf("expected 2 parameters, got %d", len(params))
+	}
+	if params[0].Name != "run_time" || params[0].ParameterValue.Value != "2023-05-06 07:08:09" {
+		t.Errorf("unexpected run_time parameter %+v", params[0].ParameterValue)
+	}
+	if params[1].Name != "run_date" || params[1].ParameterValue.Value != "2023-05-06" {
+		t.Errorf("unexpected run_date parameter %+v", params[1].ParameterValue)
+	}
+
+	if params := bigQueryScheduledQueryParameters("SELECT 1", now); len(params) != 0 {
+		t.Errorf("expected no parameters, got %d", len(params))
+	}
+}
+
+func TestIsBigQueryDryRunNotFound(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		Err      error
+		Resource string
+		NotFound bool
+	}{
+		"missing table": {
+			Err:      &googleapi.Error{Code: 404, Message: "Not found: Table my-project:my_dataset.my_table was not found in location US"},
+			NotFound: true,
+		},
+		"missing dataset": {
+			Err:      &googleapi.Error{Code: 404, Message: "Not found: Dataset my-project:my_dataset"},
+			NotFound: true,
+		},
+		"missing given dataset": {
+			Err:      &googleapi.Error{Code: 404, Message: "Not found: Dataset my-project:my_dataset"},
+			Resource: "Dataset my-project:my_dataset",
+			NotFound: true,
+		},
+		"missing table in given dataset": {
+			Err:      &googleapi.Error{Code: 404, Message: "Not found: Table my-project:my_dataset.my_table was not found in location US"},
+			Resource: "Dataset my-project:my_dataset",
+		},
+		"missing other dataset": {
+			Err:      &googleapi.Error{Code: 404, Message: "Not found: Dataset my-project:other_dataset"},
+			Resource: "Dataset my-project:my_dataset",
+		},
+		"syntax error": {
+			Err: &googleapi.Error{Code: 400, Message: "Syntax error: Unexpected end of script at [1:7]"},
+		},
+		"missing column": {
+			Err: &googleapi.Error{Code: 400, Message: "Unrecognized name: missing_column at [1:8]"},
+		},
+		"other error": {
+			Err: fmt.Errorf("connection reset"),
+		},
+	}
+
+	for tn, tc := range cases {
+		if got := isBigQueryDryRunNotFound(tc.Err, tc.Resource); got != tc.NotFound {
+			t.Errorf("%s: got %v, expected %v", tn, got, tc.NotFound)
+		}
+	}
+}
+
+func TestWithBigQueryDryRun(t *testing.T) {
+	t.Parallel()
+
+	resources, err := withBigQueryDryRun(map[string]*schema.Resource{
+		"google_bigquery_data_transfer_config": ResourceBigqueryDataTransferConfig(),
+		"google_bigquery_job":                  ResourceBigQueryJob(),
+		"google_bigquery_routine":              ResourceBigQueryRoutine(),
+	}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for name := range bigQueryDryRunCustomizeDiffs {
+		r := resources[name]
+		if s, ok := r.Schema["dry_run_bytes_processed"]; !ok || !s.Computed {
+			t.Errorf("%s: expected a computed dry_run_bytes_processed", name)
+		}
+		if r.CustomizeDiff == nil {
+			t.Errorf("%s: expected a CustomizeDiff", name)
+		}
+		if err := r.InternalValidate(nil, true); err != nil {
+			t.Errorf("%s: %s", name, err)
+		}
+	}
+
+	if _, err := withBigQueryDryRun(map[string]*schema.Resource{}, nil); err == nil {
+		t.Errorf("expected an error for missing resources")
+	}
+}
@@ -0,0 +1,100 @@
+package google
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-provider-google/google/tpgresource"
+	transport_tpg "github.com/hashicorp/terraform-provider-google/google/transport"
+
+	"google.golang.org/api/bigquery/v2"
+	"google.golang.org/api/googleapi"
+)
+
+type BigQueryJobOperationWaiter struct {
+	Service   *bigquery.Service
+	ProjectId string
+	Location  string
+	JobId     string
+	Job       *bigquery.Job
+}
+
+func (w *BigQueryJobOperationWaiter) State() string {
+	if w == nil || w.Job == nil || w.Job.Status == nil {
+		return "<nil>"
+	}
+	return w.Job.Status.State
+}
+
+func (w *BigQueryJobOperationWaiter) Error() error {
+	if w == nil || w.Job == nil || w.Job.Status == nil || w.Job.Status.ErrorResult == nil {
+		return nil
+	}
+	return fmt.Errorf("BigQuery job %s failed: %s", w.JobId, w.Job.Status.ErrorResult.Message)
+}
+
+func (w *BigQueryJobOperationWaiter) IsRetryable(error) bool {
+	return false
+}
+
+func (w *BigQueryJobOperationWaiter) SetOp(job interface{}) error {
+	j, ok := job.(*bigquery.Job)
+	if !ok {
+		return fmt.Errorf("Unable to use %T as a BigQuery job", job)
+	}
+	w.Job = j
+	return nil
+}
+
+func (w *BigQueryJobOperationWaiter) QueryOp() (interface{}, error) {
+	if w == nil {
+		return nil, fmt.Errorf("Cannot query operation, it's unset or nil.")
+	}
+	return w.Service.Jobs.Get(w.ProjectId, w.JobId).Location(w.Location).Do()
+}
+
+func (w *BigQueryJobOperationWaiter) OpName() string {
+	if w == nil {
+		return "<nil>"
+	}
+	return w.JobId
+}
+
+func (w *BigQueryJobOperationWaiter) PendingStates() []string {
+	return []string{"PENDING", "RUNNING"}
+}
+
+func (w *BigQueryJobOperationWaiter) TargetStates() []string {
+	return []string{"DONE"}
+}
+
+// bigQueryRunQuery runs a standard SQL query job and waits for it to finish.
+func bigQueryRunQuery(config *transport_tpg.Config, project, location, query, activity, userAgent string, timeout time.Duration) error {
+	service := config.NewBigQueryClient(userAgent)
+	job := &bigquery.Job{
+		JobReference: &bigquery.JobReference{
+			ProjectId: project,
+			Location:  location,
+		},
+		Configuration: &bigquery.JobConfiguration{
+			Query: &bigquery.JobConfigurationQuery{
+				Query:        query,
+				UseLegacySql: googleapi.Bool(false),
+			},
+		},
+	}
+
+	res, err := service.Jobs.Insert(project, job).Do()
+	if err != nil {
+		return fmt.Errorf("Error starting %s: %s", activity, err)
+	}
+
+	w := &BigQueryJobOperationWaiter{
+		Service:   service,
+		ProjectId: project,
+		Location:  res.JobReference.Location,
+		JobId:     res.JobReference.JobId,
+		Job:       res,
+	}
+	return tpgresource.OperationWait(w, activity, timeout, config.PollInterval)
+}
@@ -0,0 +1,657 @@
+package google
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/hashicorp/terraform-provider-google/google/tpgresource"
+	transport_tpg "github.com/hashicorp/terraform-provider-google/google/transport"
+	"github.com/hashicorp/terraform-provider-google/google/verify"
+)
+
+// A rollout moves traffic to a new Cloud Run revision in steps instead of
+// switching to the configured traffic split at once. The new revision is
+// first deployed without traffic, then receives each step's percentage for
+// wait_duration while its health is checked. Once the last step passes the
+// configured traffic is applied; if a step fails, the service is reverted to
+// its previous template and to the revisions that were serving before the
+// update. The new template then differs from the service again, so the next
+// apply rolls it out from the start rather than sending it traffic directly.
+
+func cloudRunRolloutSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Description: `Shifts traffic to a new revision progressively when the template changes, instead of
+applying the configured traffic at once. The rollout is reverted and the apply fails if the
+new revision isn't ready or its error rate exceeds the threshold at any step.`,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"steps": {
+					Type:     schema.TypeList,
+					Required: true,
+					MinItems: 1,
+					Description: `Percentages of traffic sent to the new revision at each step, in increasing order.
+The configured traffic is applied after the last step.`,
+					Elem: &schema.Schema{
+						Type:         schema.TypeInt,
+						ValidateFunc: validation.IntBetween(1, 99),
+					},
+				},
+				"wait_duration": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      "60s",
+					ValidateFunc: verify.ValidateDuration(),
+					Description:  `How long to wait at each step before checking the new revision, as a duration such as "300s".`,
+				},
+				"error_rate_threshold": {
+					Type:         schema.TypeFloat,
+					Optional:     true,
+					ValidateFunc: validation.FloatBetween(0, 1),
+					Description: `Maximum ratio of 5xx responses to all requests served by the new revision during a step,
+read from the run.googleapis.com/request_count Cloud Monitoring metric. If unset, only the
+Ready condition of the new revision is checked.`,
+				},
+			},
+		},
+	}
+}
+
+type cloudRunRollout struct {
+	Steps              []int
+	Wait               time.Duration
+	ErrorRateThreshold float64
+}
+
+func expandCloudRunRollout(v interface{}) (*cloudRunRollout, error) {
+	l := v.([]interface{})
+	if len(l) == 0 || l[0] == nil {
+		return nil, nil
+	}
+	original := l[0].(map[string]interface{})
+
+	rollout := &cloudRunRollout{
+		ErrorRateThreshold: original["error_rate_threshold"].(float64),
+	}
+	for _, step := range original["steps"].([]interface{}) {
+		rollout.Steps = append(rollout.Steps, step.(int))
+	}
+	wait, err := time.ParseDuration(original["wait_duration"].(string))
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing rollout.0.wait_duration: %s", err)
+	}
+	rollout.Wait = wait
+	return rollout, nil
+}
+
+func cloudRunRolloutCustomizeDiff(_ context.Context, diff *schema.ResourceDiff, _ interface{}) error {
+	steps := diff.Get("rollout.0.steps").([]interface{})
+	for i := 1; i < len(steps); i++ {
+		if steps[i].(int) <= steps[i-1].(int) {
+			return fmt.Errorf("rollout.0.steps must be in increasing order, got %d after %d", steps[i].(int), steps[i-1].(int))
+		}
+	}
+	return nil
+}
+
+// cloudRunTrafficTarget is a share of a service's traffic pinned to a revision.
+type cloudRunTrafficTarget struct {
+	Revision string
+	Percent  int
+	Tag      string
+}
+
+// cloudRunRolloutTraffic sends percent of the traffic to revision and scales
+// the previous targets down proportionally to share the rest. Rounding
+// leftovers go to the first previous target that had traffic, and tagged
+// targets without traffic are kept so their URLs stay reachable.
+func cloudRunRolloutTraffic(previous []cloudRunTrafficTarget, revision string, percent int) []cloudRunTrafficTarget {
+	total := 0
+	for _, t := range previous {
+		total += t.Percent
+	}
+
+	remaining := 100 - percent
+	assigned := 0
+	first := -1
+	targets := make([]cloudRunTrafficTarget, 0, len(previous)+1)
+	for _, t := range previous {
+		scaled := 0
+		if total > 0 {
+			scaled = t.Percent * remaining / total
+		}
+		if first < 0 && t.Percent > 0 {
+			first = len(targets)
+		}
+		assigned += scaled
+		targets = append(targets, cloudRunTrafficTarget{Revision: t.Revision, Percent: scaled, Tag: t.Tag})
+	}
+	if first >= 0 {
+		targets[first].Percent += remaining - assigned
+	}
+	return append(targets, cloudRunTrafficTarget{Revision: revision, Percent: percent})
+}
+
+// cloudRunRolloutService is implemented for google_cloud_run_service and
+// google_cloud_run_v2_service so that both share the rollout steps.
+type cloudRunRolloutService interface {
+	// SetTraffic sends the service's configuration with its traffic pinned
+	// to targets and waits for it to be serving.
+	SetTraffic(targets []cloudRunTrafficTarget) error
+	// Revert sends the template the service had before the rollout, with
+	// its traffic pinned to targets, and waits for it to be serving.
+	Revert(targets []cloudRunTrafficTarget) error
+	// LatestCreatedRevision returns the short name of the newest revision.
+	LatestCreatedRevision() (string, error)
+	// RevisionReady reports whether revision's Ready condition is true,
+	// along with the condition's message when it isn't.
+	RevisionReady(revision string) (bool, string, error)
+	// ErrorRate returns the ratio of 5xx responses served by revision over
+	// the trailing window.
+	ErrorRate(revision string, window time.Duration) (float64, error)
+}
+
+// runCloudRunRollout deploys the new revision without traffic, then walks
+// through the rollout steps. It returns once the last step has passed; the
+// caller applies the configured traffic afterwards.
+func runCloudRunRollout(svc cloudRunRolloutService, rollout *cloudRunRollout, previous []cloudRunTrafficTarget) error {
+	if err := svc.SetTraffic(previous); err != nil {
+		return fmt.Errorf("Error deploying new revision for rollout: %s", err)
+	}
+	revision, err := svc.LatestCreatedRevision()
+	if err != nil {
+		return err
+	}
+	for _, t := range previous {
+		if t.Revision == revision {
+			log.Printf("[DEBUG] Template change didn't create a new revision, skipping rollout")
+			return nil
+		}
+	}
+
+	for _, percent := range rollout.Steps {
+		log.Printf("[DEBUG] Rolling out %d%% of traffic to revision %s", percent, revision)
+		if err := svc.SetTraffic(cloudRunRolloutTraffic(previous, revision, percent)); err != nil {
+			return revertCloudRunRollout(svc, previous, revision, fmt.Errorf("Error shifting %d%% of traffic to revision %s: %s", percent, revision, err))
+		}
+
+		time.Sleep(rollout.Wait)
+
+		ready, message, err := svc.RevisionReady(revision)
+		if err != nil {
+			return revertCloudRunRollout(svc, previous, revision, err)
+		}
+		if !ready {
+			return revertCloudRunRollout(svc, previous, revision, fmt.Errorf("revision %s is not ready at %d%% of traffic: %s", revision, percent, message))
+		}
+
+		if rollout.ErrorRateThreshold > 0 {
+			rate, err := svc.ErrorRate(revision, rollout.Wait)
+			if err != nil {
+				return revertCloudRunRollout(svc, previous, revision, err)
+			}
+			if rate > rollout.ErrorRateThreshold {
+				return revertCloudRunRollout(svc, previous, revision, fmt.Errorf("revision %s has an error rate of %.4f at %d%% of traffic, above the threshold of %.4f", revision, rate, percent, rollout.ErrorRateThreshold))
+			}
+		}
+	}
+	return nil
+}
+
+func revertCloudRunRollout(svc cloudRunRolloutService, previous []cloudRunTrafficTarget, revision string, cause error) error {
+	log.Printf("[WARN] Reverting traffic from revision %s: %s", revision, cause)
+	if err := svc.Revert(previous); err != nil {
+		return fmt.Errorf("Rollout failed: %s. Reverting the service to its previous template and revisions also failed: %s", cause, err)
+	}
+	return fmt.Errorf("Rollout failed and the service was reverted to its previous template and revisions: %s", cause)
+}
+
+// cloudRunRolloutMonitoring reads the request_count metric of a Cloud Run
+// revision. It is embedded by the service specific rollout implementations.
+type cloudRunRolloutMonitoring struct {
+	config    *transport_tpg.Config
+	userAgent string
+	project   string
+	location  string
+	service   string
+}
+
+func (m *cloudRunRolloutMonitoring) ErrorRate(revision string, window time.Duration) (float64, error) {
+	// Cloud Monitoring can't align over periods shorter than a minute.
+	if window < time.Minute {
+		window = time.Minute
+	}
+	end := time.Now().UTC()
+	start := end.Add(-window)
+
+	params := url.Values{}
+	params.Set("filter", fmt.Sprintf(`metric.type="run.googleapis.com/request_count" AND resource.type="cloud_run_revision" AND resource.labels.location=%q AND resource.labels.service_name=%q AND resource.labels.revision_name=%q`, m.location, m.service, revision))
+	params.Set("interval.startTime", start.Format(time.RFC3339))
+	params.Set("interval.endTime", end.Format(time.RFC3339))
+	params.Set("aggregation.alignmentPeriod", fmt.Sprintf("%ds", int64(window.Seconds())))
+	params.Set("aggregation.perSeriesAligner", "ALIGN_DELTA")
+	params.Set("aggregation.crossSeriesReducer", "REDUCE_SUM")
+	params.Set("aggregation.groupByFields", "metric.labels.response_code_class")
+	rawURL := fmt.Sprintf("%sv3/projects/%s/timeSeries?%s", m.config.MonitoringBasePath, m.project, params.Encode())
+
+	res, err := transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
+		Config:    m.config,
+		Method:    "GET",
+		Project:   m.project,
+		RawURL:    rawURL,
+		UserAgent: m.userAgent,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("Error reading request_count of revision %s: %s", revision, err)
+	}
+
+	errors, total := cloudRunRequestCounts(res)
+	if total == 0 {
+		log.Printf("[DEBUG] Revision %s served no requests in the last %s", revision, window)
+		return 0, nil
+	}
+	return float64(errors) / float64(total), nil
+}
+
+// cloudRunRequestCounts sums a timeSeries.list response of request_count
+// grouped by response_code_class, returning the 5xx and the total counts.
+func cloudRunRequestCounts(res map[string]interface{}) (int64, int64) {
+	var errors, total int64
+	series, _ := res["timeSeries"].([]interface{})
+	for _, raw := range series {
+		ts, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		class := ""
+		if metric, ok := ts["metric"].(map[string]interface{}); ok {
+			if labels, ok := metric["labels"].(map[string]interface{}); ok {
+				class, _ = labels["response_code_class"].(string)
+			}
+		}
+		points, _ := ts["points"].([]interface{})
+		for _, rawPoint := range points {
+			point, ok := rawPoint.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			value, ok := point["value"].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			// int64 values are encoded as strings
+			count, err := strconv.ParseInt(fmt.Sprintf("%v", value["int64Value"]), 10, 64)
+			if err != nil {
+				continue
+			}
+			total += count
+			if class == "5xx" {
+				errors += count
+			}
+		}
+	}
+	return errors, total
+}
+
+// cloudRunConditionReady looks up the Ready condition in a list of conditions.
+// v1 conditions report a "True" status, v2 conditions a CONDITION_SUCCEEDED state.
+func cloudRunConditionReady(conditions interface{}) (bool, string) {
+	l, _ := conditions.([]interface{})
+	for _, raw := range l {
+		condition, ok := raw.(map[string]interface{})
+		if !ok || condition["type"] != "Ready" {
+			continue
+		}
+		message, _ := condition["message"].(string)
+		return condition["status"] == "True" || condition["state"] == "CONDITION_SUCCEEDED", message
+	}
+	return false, "no Ready condition reported"
+}
+
+type cloudRunServiceRollout struct {
+	cloudRunRolloutMonitoring
+	d              *schema.ResourceData
+	meta           interface{}
+	billingProject string
+	obj            map[string]interface{}
+	// previousTemplate is the spec.template of the service before the update.
+	previousTemplate interface{}
+}
+
+// runCloudRunServiceRollout runs the rollout of a google_cloud_run_service
+// update. obj is the body of the update with the configured traffic.
+func runCloudRunServiceRollout(d *schema.ResourceData, meta interface{}, obj map[string]interface{}) error {
+	rollout, err := expandCloudRunRollout(d.Get("rollout"))
+	if err != nil || rollout == nil || !d.HasChange("template") {
+		return err
+	}
+
+	oldStatus, _ := d.GetChange("status")
+	latestReady := ""
+	if l := oldStatus.([]interface{}); len(l) > 0 && l[0] != nil {
+		latestReady, _ = l[0].(map[string]interface{})["latest_ready_revision_name"].(string)
+	}
+	oldTraffic, _ := d.GetChange("traffic")
+	var previous []cloudRunTrafficTarget
+	for _, raw := range oldTraffic.([]interface{}) {
+		t := raw.(map[string]interface{})
+		revision := t["revision_name"].(string)
+		if t["latest_revision"].(bool) || revision == "" {
+			revision = latestReady
+		}
+		if revision == "" {
+			continue
+		}
+		previous = append(previous, cloudRunTrafficTarget{Revision: revision, Percent: t["percent"].(int), Tag: t["tag"].(string)})
+	}
+	if len(previous) == 0 {
+		log.Printf("[DEBUG] Service %q has no serving revision, skipping rollout", d.Id())
+		return nil
+	}
+
+	config := meta.(*transport_tpg.Config)
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+	project, err := tpgresource.GetProject(d, config)
+	if err != nil {
+		return err
+	}
+	billingProject := project
+	if bp, err := tpgresource.GetBillingProject(d, config); err == nil {
+		billingProject = bp
+	}
+
+	svc := &cloudRunServiceRollout{
+		cloudRunRolloutMonitoring: cloudRunRolloutMonitoring{
+			config:    config,
+			userAgent: userAgent,
+			project:   project,
+			location:  d.Get("location").(string),
+			service:   d.Get("name").(string),
+		},
+		d:              d,
+		meta:           meta,
+		billingProject: billingProject,
+		obj:            obj,
+	}
+	res, err := resourceCloudRunServicePollRead(d, meta)()
+	if err != nil {
+		return fmt.Errorf("Error reading Service %q before rollout: %s", d.Id(), err)
+	}
+	if spec, ok := res["spec"].(map[string]interface{}); ok {
+		svc.previousTemplate = spec["template"]
+	}
+	return runCloudRunRollout(svc, rollout, previous)
+}
+
+func (s *cloudRunServiceRollout) SetTraffic(targets []cloudRunTrafficTarget) error {
+	return s.replace(nil, targets)
+}
+
+func (s *cloudRunServiceRollout) Revert(targets []cloudRunTrafficTarget) error {
+	if s.previousTemplate == nil {
+		return fmt.Errorf("Service %q had no template before the rollout", s.d.Id())
+	}
+	return s.replace(s.previousTemplate, targets)
+}
+
+// replace sends the configuration of the update with its traffic pinned to
+// targets, and its template replaced by template unless it's nil.
+func (s *cloudRunServiceRollout) replace(template interface{}, targets []cloudRunTrafficTarget) error {
+	traffic := make([]interface{}, 0, len(targets))
+	for _, t := range targets {
+		target := map[string]interface{}{
+			"revisionName": t.Revision,
+			"percent":      t.Percent,
+		}
+		if t.Tag != "" {
+			target["tag"] = t.Tag
+		}
+		traffic = append(traffic, target)
+	}
+
+	spec := make(map[string]interface{})
+	if original, ok := s.obj["spec"].(map[string]interface{}); ok {
+		for k, v := range original {
+			spec[k] = v
+		}
+	}
+	spec["traffic"] = traffic
+	if template != nil {
+		spec["template"] = template
+	}
+	body := make(map[string]interface{})
+	for k, v := range s.obj {
+		body[k] = v
+	}
+	body["spec"] = spec
+
+	url, err := tpgresource.ReplaceVars(s.d, s.config, "{{CloudRunBasePath}}apis/serving.knative.dev/v1/namespaces/{{project}}/services/{{name}}")
+	if err != nil {
+		return err
+	}
+	res, err := transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
+		Config:               s.config,
+		Method:               "PUT",
+		Project:              s.billingProject,
+		RawURL:               url,
+		UserAgent:            s.userAgent,
+		Body:                 body,
+		Timeout:              s.d.Timeout(schema.TimeoutUpdate),
+		ErrorRetryPredicates: []transport_tpg.RetryErrorPredicateFunc{transport_tpg.IsCloudRunCreationConflict},
+	})
+	if err != nil {
+		return err
+	}
+	return PollingWaitTime(resourceCloudRunServicePollRead(s.d, s.meta), PollCheckKnativeStatusFunc(res), "Updating Service traffic", s.d.Timeout(schema.TimeoutUpdate), 1)
+}
+
+func (s *cloudRunServiceRollout) LatestCreatedRevision() (string, error) {
+	res, err := resourceCloudRunServicePollRead(s.d, s.meta)()
+	if err != nil {
+		return "", err
+	}
+	status, _ := res["status"].(map[string]interface{})
+	revision, _ := status["latestCreatedRevisionName"].(string)
+	if revision == "" {
+		return "", fmt.Errorf("Service %q reports no latestCreatedRevisionName", s.d.Id())
+	}
+	return revision, nil
+}
+
+func (s *cloudRunServiceRollout) RevisionReady(revision string) (bool, string, error) {
+	url, err := tpgresource.ReplaceVars(s.d, s.config, "{{CloudRunBasePath}}apis/serving.knative.dev/v1/namespaces/{{project}}/revisions/"+revision)
+	if err != nil {
+		return false, "", err
+	}
+	res, err := transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
+		Config:    s.config,
+		Method:    "GET",
+		Project:   s.billingProject,
+		RawURL:    url,
+		UserAgent: s.userAgent,
+	})
+	if err != nil {
+		return false, "", fmt.Errorf("Error reading revision %s: %s", revision, err)
+	}
+	status, _ := res["status"].(map[string]interface{})
+	ready, message := cloudRunConditionReady(status["conditions"])
+	return ready, message, nil
+}
+
+type cloudRunV2ServiceRollout struct {
+	cloudRunRolloutMonitoring
+	d              *schema.ResourceData
+	billingProject string
+	url            string
+	obj            map[string]interface{}
+	// previousTemplate is the template of the service before the update.
+	previousTemplate interface{}
+}
+
+// runCloudRunV2ServiceRollout runs the rollout of a google_cloud_run_v2_service
+// update. obj is the body of the update with the configured traffic.
+func runCloudRunV2ServiceRollout(d *schema.ResourceData, meta interface{}, obj map[string]interface{}) error {
+	rollout, err := expandCloudRunRollout(d.Get("rollout"))
+	if err != nil || rollout == nil || !d.HasChange("template") {
+		return err
+	}
+
+	oldLatestReady, _ := d.GetChange("latest_ready_revision")
+	latestReady := tpgresource.GetResourceNameFromSelfLink(oldLatestReady.(string))
+	oldStatuses, _ := d.GetChange("traffic_statuses")
+	var previous []cloudRunTrafficTarget
+	for _, raw := range oldStatuses.([]interface{}) {
+		t := raw.(map[string]interface{})
+		revision := tpgresource.GetResourceNameFromSelfLink(t["revision"].(string))
+		if revision == "" {
+			revision = latestReady
+		}
+		if revision == "" {
+			continue
+		}
+		previous = append(previous, cloudRunTrafficTarget{Revision: revision, Percent: t["percent"].(int), Tag: t["tag"].(string)})
+	}
+	if len(previous) == 0 {
+		log.Printf("[DEBUG] Service %q has no serving revision, skipping rollout", d.Id())
+		return nil
+	}
+
+	config := meta.(*transport_tpg.Config)
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+	project, err := tpgresource.GetProject(d, config)
+	if err != nil {
+		return err
+	}
+	billingProject := project
+	if bp, err := tpgresource.GetBillingProject(d, config); err == nil {
+		billingProject = bp
+	}
+	url, err := tpgresource.ReplaceVars(d, config, "{{CloudRunV2BasePath}}projects/{{project}}/locations/{{location}}/services/{{name}}")
+	if err != nil {
+		return err
+	}
+
+	svc := &cloudRunV2ServiceRollout{
+		cloudRunRolloutMonitoring: cloudRunRolloutMonitoring{
+			config:    config,
+			userAgent: userAgent,
+			project:   project,
+			location:  d.Get("location").(string),
+			service:   d.Get("name").(string),
+		},
+		d:              d,
+		billingProject: billingProject,
+		url:            url,
+		obj:            obj,
+	}
+	res, err := transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
+		Config:    config,
+		Method:    "GET",
+		Project:   billingProject,
+		RawURL:    url,
+		UserAgent: userAgent,
+	})
+	if err != nil {
+		return fmt.Errorf("Error reading Service %q before rollout: %s", d.Id(), err)
+	}
+	svc.previousTemplate = res["template"]
+	return runCloudRunRollout(svc, rollout, previous)
+}
+
+func (s *cloudRunV2ServiceRollout) SetTraffic(targets []cloudRunTrafficTarget) error {
+	return s.patch(nil, targets)
+}
+
+func (s *cloudRunV2ServiceRollout) Revert(targets []cloudRunTrafficTarget) error {
+	if s.previousTemplate == nil {
+		return fmt.Errorf("Service %q had no template before the rollout", s.d.Id())
+	}
+	return s.patch(s.previousTemplate, targets)
+}
+
+// patch sends the configuration of the update with its traffic pinned to
+// targets, and its template replaced by template unless it's nil.
+func (s *cloudRunV2ServiceRollout) patch(template interface{}, targets []cloudRunTrafficTarget) error {
+	traffic := make([]interface{}, 0, len(targets))
+	for _, t := range targets {
+		target := map[string]interface{}{
+			"type":     "TRAFFIC_TARGET_ALLOCATION_TYPE_REVISION",
+			"revision": t.Revision,
+			"percent":  t.Percent,
+		}
+		if t.Tag != "" {
+			target["tag"] = t.Tag
+		}
+		traffic = append(traffic, target)
+	}
+	body := make(map[string]interface{})
+	for k, v := range s.obj {
+		body[k] = v
+	}
+	body["traffic"] = traffic
+	if template != nil {
+		body["template"] = template
+	}
+
+	res, err := transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
+		Config:    s.config,
+		Method:    "PATCH",
+		Project:   s.billingProject,
+		RawURL:    s.url,
+		UserAgent: s.userAgent,
+		Body:      body,
+		Timeout:   s.d.Timeout(schema.TimeoutUpdate),
+	})
+	if err != nil {
+		return err
+	}
+	return CloudRunV2OperationWaitTime(s.config, res, s.project, "Updating Service traffic", s.userAgent, s.d.Timeout(schema.TimeoutUpdate))
+}
+
+func (s *cloudRunV2ServiceRollout) LatestCreatedRevision() (string, error) {
+	res, err := transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
+		Config:    s.config,
+		Method:    "GET",
+		Project:   s.billingProject,
+		RawURL:    s.url,
+		UserAgent: s.userAgent,
+	})
+	if err != nil {
+		return "", err
+	}
+	revision, _ := res["latestCreatedRevision"].(string)
+	if revision == "" {
+		return "", fmt.Errorf("Service %q reports no latestCreatedRevision", s.d.Id())
+	}
+	return tpgresource.GetResourceNameFromSelfLink(revision), nil
+}
+
+func (s *cloudRunV2ServiceRollout) RevisionReady(revision string) (bool, string, error) {
+	res, err := transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
+		Config:    s.config,
+		Method:    "GET",
+		Project:   s.billingProject,
+		RawURL:    strings.TrimSuffix(s.url, "/") + "/revisions/" + revision,
+		UserAgent: s.userAgent,
+	})
+	if err != nil {
+		return false, "", fmt.Errorf("Error reading revision %s: %s", revision, err)
+	}
+	ready, message := cloudRunConditionReady(res["conditions"])
+	return ready, message, nil
+}
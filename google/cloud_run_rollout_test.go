@@ -0,0 +1,248 @@
+package google
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCloudRunRolloutTraffic(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		Previous []cloudRunTrafficTarget
+		Percent  int
+		Expected []cloudRunTrafficTarget
+	}{
+		"single previous revision": {
+			Previous: []cloudRunTrafficTarget{{Revision: "svc-00001", Percent: 100}},
+			Percent:  10,
+			Expected: []cloudRunTrafficTarget{
+				{Revision: "svc-00001", Percent: 90},
+				{Revision: "svc-00002", Percent: 10},
+			},
+		},
+		"split previous revisions are scaled proportionally": {
+			Previous: []cloudRunTrafficTarget{
+				{Revision: "svc-00001", Percent: 50},
+				{Revision: "svc-00002", Percent: 50, Tag: "blue"},
+			},
+			Percent: 25,
+			Expected: []cloudRunTrafficTarget{
+				{Revision: "svc-00001", Percent: 38},
+				{Revision: "svc-00002", Percent: 37, Tag: "blue"},
+				{Revision: "svc-00003", Percent: 25},
+			},
+		},
+		"tagged revisions without traffic are kept": {
+			Previous: []cloudRunTrafficTarget{
+				{Revision: "svc-00001", Tag: "old"},
+				{Revision: "svc-00002", Percent: 100},
+			},
+			Percent: 50,
+			Expected: []cloudRunTrafficTarget{
+				{Revision: "svc-00001", Percent: 0, Tag: "old"},
+				{Revision: "svc-00002", Percent: 50},
+				{Revision: "svc-00003", Percent: 50},
+			},
+		},
+	}
+
+	for tn, tc := range cases {
+		revision := tc.Expected[len(tc.Expected)-1].Revision
+		got := cloudRunRolloutTraffic(tc.Previous, revision, tc.Percent)
+		if !reflect.DeepEqual(got, tc.Expected) {
+			t.Errorf("%s: got %v, expected %v", tn, got, tc.Expected)
+		}
+		total := 0
+		for _, target := range got {
+			total += target.Percent
+		}
+		if total != 100 {
+			t.Errorf("%s: expected traffic to add up to 100, got %d", tn, total)
+		}
+	}
+}
+
+func TestCloudRunRequestCounts(t *testing.T) {
+	t.Parallel()
+
+	res := map[string]interface{}{
+		"timeSeries": []interface{}{
+			map[string]interface{}{
+				"metric": map[string]interface{}{
+					"labels": map[string]interface{}{"response_code_class": "2xx"},
+				},
+				"points": []interface{}{
+					map[string]interface{}{"value": map[string]interface{}{"int64Value": "90"}},
+				},
+			},
+			map[string]interface{}{
+				"metric": map[string]interface{}{
+					"labels": map[string]interface{}{"response_code_class": "5xx"},
+				},
+				"points": []interface{}{
+					map[string]interface{}{"value": map[string]interface{}{"int64Value": "6"}},
+					map[string]interface{}{"value": map[string]interface{}{"int64Value": "4"}},
+				},
+			},
+		},
+	}
+
+	errors, total := cloudRunRequestCounts(res)
+	if errors != 10 || total != 100 {
+		t.Errorf("got %d errors out of %d requests, expected 10 out of 100", errors, total)
+	}
+
+	errors, total = cloudRunRequestCounts(map[string]interface{}{})
+	if errors != 0 || total != 0 {
+		t.Errorf("got %d errors out of %d requests for an empty response, expected none", errors, total)
+	}
+}
+
+func TestCloudRunConditionReady(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		Conditions interface{}
+		Ready      bool
+	}{
+		"v1 ready": {
+			Conditions: []interface{}{map[string]interface{}{"type": "Ready", "status": "True"}},
+			Ready:      true,
+		},
+		"v1 not ready": {
+			Conditions: []interface{}{map[string]interface{}{"type": "Ready", "status": "False", "message": "Container failed to start"}},
+		},
+		"v2 ready": {
+			Conditions: []interface{}{
+				map[string]interface{}{"type": "ContainerHealthy", "state": "CONDITION_FAILED"},
+				map[string]interface{}{"type": "Ready", "state": "CONDITION_SUCCEEDED"},
+			},
+			Ready: true,
+		},
+		"missing": {
+			Conditions: nil,
+		},
+	}
+
+	for tn, tc := range cases {
+		if ready, _ := cloudRunConditionReady(tc.Conditions); ready != tc.Ready {
+			t.Errorf("%s: got ready %t, expected %t", tn, ready, tc.Ready)
+		}
+	}
+}
+
+type fakeCloudRunRolloutService struct {
+	revision  string
+	ready     map[string]bool
+	errorRate float64
+	traffic   [][]cloudRunTrafficTarget
+	reverted  bool
+}
+
+func (s *fakeCloudRunRolloutService) SetTraffic(targets []cloudRunTrafficTarget) error {
+	s.traffic = append(s.traffic, targets)
+	return nil
+}
+
+func (s *fakeCloudRunRolloutService) Revert(targets []cloudRunTrafficTarget) error {
+	s.reverted = true
+	return s.SetTraffic(targets)
+}
+
+func (s *fakeCloudRunRolloutService) LatestCreatedRevision() (string, error) {
+	return s.revision, nil
+}
+
+func (s *fakeCloudRunRolloutService) RevisionReady(revision string) (bool, string, error) {
+	return s.ready[revision], "not ready", nil
+}
+
+func (s *fakeCloudRunRolloutService) ErrorRate(revision string, window time.Duration) (float64, error) {
+	return s.errorRate, nil
+}
+
+func (s *fakeCloudRunRolloutService) newRevisionPercents() []int {
+	percents := []int{}
+	for _, targets := range s.traffic {
+		percent := 0
+		for _, target := range targets {
+			if target.Revision == s.revision {
+				percent = target.Percent
+			}
+		}
+		percents = append(percents, percent)
+	}
+	return percents
+}
+
+func TestRunCloudRunRollout(t *testing.T) {
+	t.Parallel()
+
+	previous := []cloudRunTrafficTarget{{Revision: "svc-00001", Percent: 100}}
+	rollout := &cloudRunRollout{Steps: []int{10, 50}, ErrorRateThreshold: 0.05}
+
+	cases := map[string]struct {
+		Ready     bool
+		ErrorRate float64
+		Percents  []int
+		Reverted  bool
+		Error     string
+	}{
+		"healthy revision goes through every step": {
+			Ready:     true,
+			ErrorRate: 0.01,
+			Percents:  []int{0, 10, 50},
+		},
+		"unready revision is reverted": {
+			Ready:    false,
+			Percents: []int{0, 10, 0},
+			Reverted: true,
+			Error:    "is not ready at 10% of traffic",
+		},
+		"error rate above the threshold is reverted": {
+			Ready:     true,
+			ErrorRate: 0.2,
+			Percents:  []int{0, 10, 0},
+			Reverted:  true,
+			Error:     "above the threshold",
+		},
+	}
+
+	for tn, tc := range cases {
+		svc := &fakeCloudRunRolloutService{
+			revision:  "svc-00002",
+			ready:     map[string]bool{"svc-00002": tc.Ready},
+			errorRate: tc.ErrorRate,
+		}
+		err := runCloudRunRollout(svc, rollout, previous)
+		if tc.Error == "" && err != nil {
+			t.Errorf("%s: unexpected error: %s", tn, err)
+		}
+		if tc.Error != "" && (err == nil || !strings.Contains(err.Error(), tc.Error)) {
+			t.Errorf("%s: expected error containing %q, got %v", tn, tc.Error, err)
+		}
+		if got := svc.newRevisionPercents(); !reflect.DeepEqual(got, tc.Percents) {
+			t.Errorf("%s: got traffic steps %v, expected %v", tn, got, tc.Percents)
+		}
+		if svc.reverted != tc.Reverted {
+			t.Errorf("%s: expected the template to be reverted to be %t, was %t", tn, tc.Reverted, svc.reverted)
+		}
+	}
+}
+
+func TestRunCloudRunRollout_noNewRevision(t *testing.T) {
+	t.Parallel()
+
+	svc := &fakeCloudRunRolloutService{revision: "svc-00001"}
+	previous := []cloudRunTrafficTarget{{Revision: "svc-00001", Percent: 100}}
+	if err := runCloudRunRollout(svc, &cloudRunRollout{Steps: []int{50}}, previous); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(svc.traffic) != 1 {
+		t.Errorf("expected only the initial deployment, got %s", fmt.Sprint(svc.traffic))
+	}
+}
@@ -0,0 +1,85 @@
+package google
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/hashicorp/terraform-provider-google/google/tpgresource"
+	transport_tpg "github.com/hashicorp/terraform-provider-google/google/transport"
+)
+
+// cloudTasksQueueStateCustomizeDiff rejects state changes the API can't make:
+// DISABLED is only reported by Cloud Tasks while the App Engine application
+// of the queue is disabled, and a disabled queue can't be paused or resumed.
+func cloudTasksQueueStateCustomizeDiff(_ context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+	if !diff.HasChange("state") || !diff.NewValueKnown("state") {
+		return nil
+	}
+	old, new := diff.GetChange("state")
+	if new.(string) == "DISABLED" {
+		return fmt.Errorf("state can't be set to DISABLED: a queue is disabled when its App Engine application is disabled")
+	}
+	if old.(string) == "DISABLED" {
+		return fmt.Errorf("the queue is DISABLED because its App Engine application is disabled, re-enable the application before setting state to %s", new)
+	}
+	return nil
+}
+
+// cloudTasksQueuePostCreateSetState pauses a new queue if needed. Queues are
+// created running, and DISABLED is rejected by the plan.
+func cloudTasksQueuePostCreateSetState(d *schema.ResourceData, config *transport_tpg.Config, billingProject, userAgent string) error {
+	if d.Get("state").(string) != "PAUSED" {
+		return nil
+	}
+	return cloudTasksQueueAction(d, config, "pause", billingProject, userAgent, d.Timeout(schema.TimeoutCreate))
+}
+
+// cloudTasksQueuePostUpdate pauses or resumes the queue when state changes,
+// and purges its tasks when purge_keepers changes.
+func cloudTasksQueuePostUpdate(d *schema.ResourceData, config *transport_tpg.Config, billingProject, userAgent string) error {
+	if d.HasChange("state") {
+		action := "resume"
+		if d.Get("state").(string) == "PAUSED" {
+			action = "pause"
+		}
+		if err := cloudTasksQueueAction(d, config, action, billingProject, userAgent, d.Timeout(schema.TimeoutUpdate)); err != nil {
+			return err
+		}
+	}
+
+	if d.HasChange("purge_keepers") {
+		if err := cloudTasksQueueAction(d, config, "purge", billingProject, userAgent, d.Timeout(schema.TimeoutUpdate)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cloudTasksQueueAction calls one of the :pause, :resume or :purge methods of
+// the queue.
+func cloudTasksQueueAction(d *schema.ResourceData, config *transport_tpg.Config, action, billingProject, userAgent string, timeout time.Duration) error {
+	url, err := tpgresource.ReplaceVars(d, config, fmt.Sprintf("{{CloudTasksBasePath}}projects/{{project}}/locations/{{location}}/queues/{{name}}:%s", action))
+	if err != nil {
+		return err
+	}
+
+	_, err = transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
+		Config:    config,
+		Method:    "POST",
+		Project:   billingProject,
+		RawURL:    url,
+		UserAgent: userAgent,
+		Body:      map[string]interface{}{},
+		Timeout:   timeout,
+	})
+	if err != nil {
+		return fmt.Errorf("Error calling %s on Queue %q: %s", action, d.Id(), err)
+	}
+
+	log.Printf("[DEBUG] Finished calling %s on Queue %q", action, d.Id())
+	return nil
+}
@@ -0,0 +1,61 @@
+package google
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestCloudTasksQueueStateCustomizeDiff(t *testing.T) {
+	t.Parallel()
+
+	r := ResourceCloudTasksQueue()
+	cases := map[string]struct {
+		Old   string
+		New   string
+		Error bool
+	}{
+		"pause": {
+			Old: "RUNNING",
+			New: "PAUSED",
+		},
+		"disabled is kept": {
+			Old: "DISABLED",
+			New: "DISABLED",
+		},
+		"disable": {
+			Old:   "RUNNING",
+			New:   "DISABLED",
+			Error: true,
+		},
+		"resume a disabled queue": {
+			Old:   "DISABLED",
+			New:   "RUNNING",
+			Error: true,
+		},
+	}
+
+	for tn, tc := range cases {
+		state := &terraform.InstanceState{
+			ID: "projects/p/locations/us-central1/queues/q",
+			Attributes: map[string]string{
+				"id":       "projects/p/locations/us-central1/queues/q",
+				"name":     "q",
+				"location": "us-central1",
+				"project":  "p",
+				"state":    tc.Old,
+			},
+		}
+		config := terraform.NewResourceConfigRaw(map[string]interface{}{
+			"name":     "q",
+			"location": "us-central1",
+			"project":  "p",
+			"state":    tc.New,
+		})
+		_, err := r.SimpleDiff(context.Background(), state, config, nil)
+		if tc.Error != (err != nil) {
+			t.Errorf("%s: got error %v, expected an error: %v", tn, err, tc.Error)
+		}
+	}
+}
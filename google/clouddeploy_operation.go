@@ -0,0 +1,69 @@
+package google
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-provider-google/google/tpgresource"
+	transport_tpg "github.com/hashicorp/terraform-provider-google/google/transport"
+)
+
+// clouddeployDefaultBasePath is the endpoint of Cloud Deploy when the
+// clouddeploy_custom_endpoint of the provider is unset. Cloud Deploy resources
+// are otherwise managed through the DCL, which defaults it itself.
+const clouddeployDefaultBasePath = "https://clouddeploy.googleapis.com/v1/"
+
+func clouddeployBasePath(config *transport_tpg.Config) string {
+	if config.ClouddeployBasePath != "" {
+		return config.ClouddeployBasePath
+	}
+	return clouddeployDefaultBasePath
+}
+
+type ClouddeployOperationWaiter struct {
+	Config    *transport_tpg.Config
+	UserAgent string
+	Project   string
+	tpgresource.CommonOperationWaiter
+}
+
+func (w *ClouddeployOperationWaiter) QueryOp() (interface{}, error) {
+	if w == nil {
+		return nil, fmt.Errorf("Cannot query operation, it's unset or nil.")
+	}
+	// Returns the proper get.
+	url := fmt.Sprintf("%s%s", clouddeployBasePath(w.Config), w.CommonOperationWaiter.Op.Name)
+
+	return transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
+		Config:    w.Config,
+		Method:    "GET",
+		Project:   w.Project,
+		RawURL:    url,
+		UserAgent: w.UserAgent,
+	})
+}
+
+func createClouddeployWaiter(config *transport_tpg.Config, op map[string]interface{}, project, activity, userAgent string) (*ClouddeployOperationWaiter, error) {
+	w := &ClouddeployOperationWaiter{
+		Config:    config,
+		UserAgent: userAgent,
+		Project:   project,
+	}
+	if err := w.CommonOperationWaiter.SetOp(op); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func ClouddeployOperationWaitTime(config *transport_tpg.Config, op map[string]interface{}, project, activity, userAgent string, timeout time.Duration) error {
+	if val, ok := op["name"]; !ok || val == "" {
+		// This was a synchronous call - there is no operation to wait for.
+		return nil
+	}
+	w, err := createClouddeployWaiter(config, op, project, activity, userAgent)
+	if err != nil {
+		// If w is nil, the op was synchronous.
+		return err
+	}
+	return tpgresource.OperationWait(w, activity, timeout, config.PollInterval)
+}
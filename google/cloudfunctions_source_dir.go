@@ -0,0 +1,393 @@
+package google
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// Cloud Functions source directories are packaged the same way by both the
+// 1st gen and 2nd gen resources: the directory is walked in lexical order,
+// files matched by .gcloudignore are skipped and the remaining files are
+// written to a zip archive with fixed timestamps and normalized permissions so
+// that the same content always produces the same archive.
+
+const cloudFunctionsGcloudIgnoreFile = ".gcloudignore"
+
+// gcloud uses these patterns when a source directory has no .gcloudignore.
+var cloudFunctionsDefaultIgnorePatterns = []string{
+	".gcloudignore",
+	".git",
+	".gitignore",
+}
+
+// The DOS epoch, the earliest timestamp representable in a zip archive.
+var cloudFunctionsSourceModTime = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
+type cloudFunctionsSourceFile struct {
+	// Slash separated path relative to the source directory.
+	Name       string
+	Path       string
+	Executable bool
+}
+
+type cloudFunctionsIgnoreRule struct {
+	pattern *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+type cloudFunctionsIgnoreRules []cloudFunctionsIgnoreRule
+
+// ignored reports whether the slash separated relative path is excluded. As in
+// .gitignore the last matching rule wins.
+func (rules cloudFunctionsIgnoreRules) ignored(name string, isDir bool) bool {
+	ignored := false
+	for _, rule := range rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if rule.pattern.MatchString(name) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// readCloudFunctionsIgnoreRules loads the .gcloudignore file of dir, falling
+// back to gcloud's defaults when the file doesn't exist.
+func readCloudFunctionsIgnoreRules(dir string) (cloudFunctionsIgnoreRules, error) {
+	f, err := os.Open(filepath.Join(dir, cloudFunctionsGcloudIgnoreFile))
+	if os.IsNotExist(err) {
+		return parseCloudFunctionsIgnorePatterns(dir, cloudFunctionsDefaultIgnorePatterns)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	lines, err := readCloudFunctionsIgnoreLines(f)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading %s: %s", cloudFunctionsGcloudIgnoreFile, err)
+	}
+	return parseCloudFunctionsIgnorePatterns(dir, lines)
+}
+
+func readCloudFunctionsIgnoreLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+// parseCloudFunctionsIgnorePatterns parses .gcloudignore lines. Besides the
+// .gitignore syntax, gcloud supports a "#!include:<file>" directive that
+// splices in the patterns of another file in the source directory.
+func parseCloudFunctionsIgnorePatterns(dir string, lines []string) (cloudFunctionsIgnoreRules, error) {
+	var rules cloudFunctionsIgnoreRules
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t\r")
+		if strings.HasPrefix(line, "#!include:") {
+			include := strings.TrimSpace(strings.TrimPrefix(line, "#!include:"))
+			f, err := os.Open(filepath.Join(dir, filepath.FromSlash(include)))
+			if err != nil {
+				return nil, fmt.Errorf("Error reading %s included from %s: %s", include, cloudFunctionsGcloudIgnoreFile, err)
+			}
+			included, err := readCloudFunctionsIgnoreLines(f)
+			f.Close()
+			if err != nil {
+				return nil, fmt.Errorf("Error reading %s included from %s: %s", include, cloudFunctionsGcloudIgnoreFile, err)
+			}
+			includedRules, err := parseCloudFunctionsIgnorePatterns(dir, included)
+			if err != nil {
+				return nil, err
+			}
+			rules = append(rules, includedRules...)
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := cloudFunctionsIgnoreRule{}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\`) {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+
+		// Patterns without a slash match at any depth, the others are relative
+		// to the source directory.
+		anchored := strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+
+		expr, err := cloudFunctionsIgnorePatternRegexp(line)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid %s pattern %q: %s", cloudFunctionsGcloudIgnoreFile, line, err)
+		}
+		if anchored {
+			expr = "^" + expr + "$"
+		} else {
+			expr = "(^|/)" + expr + "$"
+		}
+		rule.pattern, err = regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid %s pattern %q: %s", cloudFunctionsGcloudIgnoreFile, line, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// cloudFunctionsIgnorePatternRegexp translates a .gitignore style glob into a
+// regular expression.
+func cloudFunctionsIgnorePatternRegexp(pattern string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '*':
+			if strings.HasPrefix(pattern[i:], "**/") {
+				b.WriteString("(.*/)?")
+				i += 2
+			} else if strings.HasPrefix(pattern[i:], "**") {
+				b.WriteString(".*")
+				i++
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated character class")
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				b.WriteString(regexp.QuoteMeta(string(pattern[i])))
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String(), nil
+}
+
+// listCloudFunctionsSourceFiles returns the files of dir that aren't ignored,
+// sorted by their relative path. Files inside an ignored directory can't be
+// re-included, matching .gitignore semantics.
+func listCloudFunctionsSourceFiles(dir string) ([]cloudFunctionsSourceFile, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading source_dir %q: %s", dir, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("source_dir %q is not a directory", dir)
+	}
+
+	rules, err := readCloudFunctionsIgnoreRules(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []cloudFunctionsSourceFile
+	err = filepath.WalkDir(dir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == dir {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+
+		info, err := os.Stat(p)
+		if err != nil {
+			return err
+		}
+		if rules.ignored(name, info.IsDir()) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// Symlinked directories aren't followed to avoid cycles.
+		if info.IsDir() || !info.Mode().IsRegular() {
+			return nil
+		}
+		files = append(files, cloudFunctionsSourceFile{
+			Name:       name,
+			Path:       p,
+			Executable: info.Mode().Perm()&0111 != 0,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error reading source_dir %q: %s", dir, err)
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+	return files, nil
+}
+
+// cloudFunctionsSourceDirHash returns a hash of the packaged content of dir.
+// Only the relative paths, the executable bit and the file contents are
+// hashed, so that the hash is stable across machines and checkouts.
+func cloudFunctionsSourceDirHash(dir string) (string, error) {
+	files, err := listCloudFunctionsSourceFiles(dir)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	for _, file := range files {
+		f, err := os.Open(file.Path)
+		if err != nil {
+			return "", err
+		}
+		fh := sha256.New()
+		_, err = io.Copy(fh, f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s\x00%t\x00%x\n", file.Name, file.Executable, fh.Sum(nil))
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// zipCloudFunctionsSourceDir packages dir into a deterministic zip archive and
+// returns it together with the content hash of dir.
+func zipCloudFunctionsSourceDir(dir string) ([]byte, string, error) {
+	files, err := listCloudFunctionsSourceFiles(dir)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	h := sha256.New()
+	for _, file := range files {
+		content, err := os.ReadFile(file.Path)
+		if err != nil {
+			return nil, "", fmt.Errorf("Error reading %s: %s", file.Path, err)
+		}
+		fmt.Fprintf(h, "%s\x00%t\x00%x\n", file.Name, file.Executable, sha256.Sum256(content))
+
+		header := &zip.FileHeader{
+			Name:     file.Name,
+			Method:   zip.Deflate,
+			Modified: cloudFunctionsSourceModTime,
+		}
+		mode := os.FileMode(0644)
+		if file.Executable {
+			mode = 0755
+		}
+		header.SetMode(mode)
+
+		fw, err := w.CreateHeader(header)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := fw.Write(content); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// uploadCloudFunctionsSource uploads archive to a signed URL returned by
+// generateUploadUrl. The URL carries its own credentials, so the request is
+// sent without the provider's authorization.
+func uploadCloudFunctionsSource(ctx context.Context, uploadUrl string, archive []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, uploadUrl, bytes.NewReader(archive))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/zip")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := cleanhttp.DefaultClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("Error uploading function source: %s", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+		return fmt.Errorf("Error uploading function source: %s: %s", res.Status, strings.TrimSpace(string(body)))
+	}
+	log.Printf("[DEBUG] Uploaded %d bytes of function source to %s", len(archive), strings.SplitN(uploadUrl, "?", 2)[0])
+	return nil
+}
+
+// cloudFunctionsSourceDirCustomizeDiff recomputes source_dir_hash at plan time
+// so that a function is only redeployed when the content of source_dir
+// changes, rather than when the directory is moved or touched.
+func cloudFunctionsSourceDirCustomizeDiff(_ context.Context, diff *schema.ResourceDiff, _ interface{}) error {
+	if !diff.NewValueKnown("source_dir") {
+		return diff.SetNewComputed("source_dir_hash")
+	}
+
+	old := diff.Get("source_dir_hash").(string)
+	dir := diff.Get("source_dir").(string)
+	if dir == "" {
+		if old != "" {
+			return diff.SetNew("source_dir_hash", "")
+		}
+		return nil
+	}
+
+	hash, err := cloudFunctionsSourceDirHash(dir)
+	if err != nil {
+		return err
+	}
+	if hash != old {
+		return diff.SetNew("source_dir_hash", hash)
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package google
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func writeCloudFunctionsSourceFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func cloudFunctionsSourceFileNames(t *testing.T, dir string) []string {
+	files, err := listCloudFunctionsSourceFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, f := range files {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+func TestListCloudFunctionsSourceFiles(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		Files    map[string]string
+		Expected []string
+	}{
+		"no .gcloudignore uses the defaults": {
+			Files: map[string]string{
+				"index.js":    "",
+				".gitignore":  "node_modules",
+				".git/HEAD":   "",
+				"lib/util.js": "",
+			},
+			Expected: []string{"index.js", "lib/util.js"},
+		},
+		"patterns match at any depth": {
+			Files: map[string]string{
+				".gcloudignore":              "# comment\n\n*.log\nnode_modules\n",
+				"index.js":                   "",
+				"debug.log":                  "",
+				"lib/trace.log":              "",
+				"node_modules/a/index.js":    "",
+				"lib/node_modules/b/main.js": "",
+			},
+			Expected: []string{".gcloudignore", "index.js"},
+		},
+		"anchored and directory patterns": {
+			Files: map[string]string{
+				".gcloudignore":   ".gcloudignore\n/build\ntest/\ndocs/*.md\n",
+				"index.js":        "",
+				"build/out.js":    "",
+				"lib/build":       "",
+				"test/index.js":   "",
+				"lib/test":        "",
+				"docs/README.md":  "",
+				"docs/api/api.md": "",
+			},
+			Expected: []string{"docs/api/api.md", "index.js", "lib/build", "lib/test"},
+		},
+		"negation and double star": {
+			Files: map[string]string{
+				".gcloudignore":      ".gcloudignore\n**/*.json\n!package.json\nsrc/**/fixtures\n",
+				"package.json":       "",
+				"config.json":        "",
+				"lib/data.json":      "",
+				"src/a/b/fixtures/x": "",
+				"src/fixtures/y":     "",
+				"src/main.js":        "",
+			},
+			Expected: []string{"package.json", "src/main.js"},
+		},
+		"include directive": {
+			Files: map[string]string{
+				".gcloudignore": ".gcloudignore\n.gitignore\n#!include:.gitignore\n",
+				".gitignore":    "dist/\n",
+				"dist/out.js":   "",
+				"index.js":      "",
+			},
+			Expected: []string{"index.js"},
+		},
+	}
+
+	for tn, tc := range cases {
+		tc := tc
+		t.Run(tn, func(t *testing.T) {
+			t.Parallel()
+			dir := t.TempDir()
+			writeCloudFunctionsSourceFiles(t, dir, tc.Files)
+			if got := cloudFunctionsSourceFileNames(t, dir); !reflect.DeepEqual(got, tc.Expected) {
+				t.Errorf("got %v, expected %v", got, tc.Expected)
+			}
+		})
+	}
+}
+
+func TestZipCloudFunctionsSourceDir(t *testing.T) {
+	t.Parallel()
+
+	files := map[string]string{
+		"index.js":     "exports.helloGET = (req, res) => res.send('hello');",
+		"package.json": "{}",
+		"lib/util.js":  "module.exports = {};",
+	}
+	first := t.TempDir()
+	writeCloudFunctionsSourceFiles(t, first, files)
+	second := t.TempDir()
+	writeCloudFunctionsSourceFiles(t, second, files)
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(second, "index.js"), later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	firstZip, firstHash, err := zipCloudFunctionsSourceDir(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secondZip, secondHash, err := zipCloudFunctionsSourceDir(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(firstZip, secondZip) {
+		t.Errorf("expected archives of identical content to be identical")
+	}
+	if firstHash != secondHash {
+		t.Errorf("expected hashes of identical content to match, got %s and %s", firstHash, secondHash)
+	}
+
+	hash, err := cloudFunctionsSourceDirHash(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != firstHash {
+		t.Errorf("expected cloudFunctionsSourceDirHash to return %s, got %s", firstHash, hash)
+	}
+
+	r, err := zip.NewReader(bytes.NewReader(firstZip), int64(len(firstZip)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, f := range r.File {
+		names = append(names, f.Name)
+		if !f.Modified.Equal(cloudFunctionsSourceModTime) {
+			t.Errorf("expected %s to have a fixed modification time, got %s", f.Name, f.Modified)
+		}
+	}
+	if expected := []string{"index.js", "lib/util.js", "package.json"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("got archive entries %v, expected %v", names, expected)
+	}
+
+	writeCloudFunctionsSourceFiles(t, second, map[string]string{"index.js": "exports.helloGET = (req, res) => res.send('bye');"})
+	changed, err := cloudFunctionsSourceDirHash(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed == firstHash {
+		t.Errorf("expected the hash to change with the content")
+	}
+
+	if err := os.Chmod(filepath.Join(first, "index.js"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	executable, err := cloudFunctionsSourceDirHash(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if executable == firstHash {
+		t.Errorf("expected the hash to change with the executable bit")
+	}
+}
+
+func TestCloudFunctionsSourceDirHash_missingDir(t *testing.T) {
+	t.Parallel()
+
+	if _, err := cloudFunctionsSourceDirHash(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Errorf("expected an error for a missing source_dir")
+	}
+}
@@ -0,0 +1,237 @@
+package google
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/hashicorp/terraform-provider-google/google/tpgresource"
+	transport_tpg "github.com/hashicorp/terraform-provider-google/google/transport"
+	"github.com/hashicorp/terraform-provider-google/google/verify"
+)
+
+const (
+	instanceWaitForDefaultTimeout = 10 * time.Minute
+
+	// Serial port output is read incrementally, and only this much of the
+	// previously read output is kept around so that a match spanning two
+	// reads is still found.
+	instanceWaitForSerialPortOverlap = 64 * 1024
+)
+
+func computeInstanceWaitForSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: `Blocks creation of the instance until it reports that it is ready, either through a guest attribute or a line in its serial port output. Adding or changing it on an existing instance waits for the instance again.`,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"guest_attribute": {
+					Type:         schema.TypeList,
+					Optional:     true,
+					MaxItems:     1,
+					ExactlyOneOf: []string{"wait_for.0.guest_attribute", "wait_for.0.serial_port_output"},
+					Description:  `Wait until the guest attribute instance/guest-attributes/<namespace>/<key> is set. Guest attributes must be enabled on the instance with the enable-guest-attributes metadata key.`,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"namespace": {
+								Type:        schema.TypeString,
+								Required:    true,
+								Description: `The namespace of the guest attribute.`,
+							},
+							"key": {
+								Type:        schema.TypeString,
+								Required:    true,
+								Description: `The key of the guest attribute.`,
+							},
+							"value_regex": {
+								Type:         schema.TypeString,
+								Optional:     true,
+								ValidateFunc: validation.StringIsValidRegExp,
+								Description:  `If set, keep waiting until the guest attribute's value matches this regular expression. Otherwise any value is accepted.`,
+							},
+						},
+					},
+				},
+				"serial_port_output": {
+					Type:         schema.TypeList,
+					Optional:     true,
+					MaxItems:     1,
+					ExactlyOneOf: []string{"wait_for.0.guest_attribute", "wait_for.0.serial_port_output"},
+					Description:  `Wait until the instance's serial port output contains a match for a regular expression.`,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"regex": {
+								Type:         schema.TypeString,
+								Required:     true,
+								ValidateFunc: validation.StringIsValidRegExp,
+								Description:  `The regular expression to look for in the serial port output.`,
+							},
+							"port": {
+								Type:         schema.TypeInt,
+								Optional:     true,
+								Default:      1,
+								ValidateFunc: validation.IntBetween(1, 4),
+								Description:  `The serial port to read. Defaults to 1.`,
+							},
+						},
+					},
+				},
+				"timeout": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      "10m",
+					ValidateFunc: verify.ValidateNonNegativeDuration(),
+					Description:  `How long to wait for the instance to become ready before failing, as a duration such as "15m". Defaults to "10m".`,
+				},
+				"ready": {
+					Type:        schema.TypeBool,
+					Computed:    true,
+					Description: `Whether the instance reported that it was ready.`,
+				},
+				"matched_value": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: `The guest attribute value, or the serial port output, that satisfied the wait.`,
+				},
+				"ready_time": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: `The time the instance was found to be ready, in RFC3339 format.`,
+				},
+			},
+		},
+	}
+}
+
+// waitForInstanceReadiness polls the instance's guest attributes or serial
+// port output as configured in wait_for, and records the outcome in state.
+// It is called on create, and on update when wait_for changes. It is a no-op
+// when wait_for is not set.
+func waitForInstanceReadiness(d *schema.ResourceData, config *transport_tpg.Config, project, zone, name string) error {
+	if _, ok := d.GetOk("wait_for"); !ok {
+		return nil
+	}
+
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	timeout := instanceWaitForDefaultTimeout
+	if v := d.Get("wait_for.0.timeout").(string); v != "" {
+		timeout, err = time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("Error parsing wait_for.0.timeout: %s", err)
+		}
+	}
+
+	var check func() (string, bool, error)
+	var activity string
+	if _, ok := d.GetOk("wait_for.0.guest_attribute"); ok {
+		namespace := d.Get("wait_for.0.guest_attribute.0.namespace").(string)
+		key := d.Get("wait_for.0.guest_attribute.0.key").(string)
+		re, err := regexp.Compile(d.Get("wait_for.0.guest_attribute.0.value_regex").(string))
+		if err != nil {
+			return err
+		}
+		activity = fmt.Sprintf("guest attribute %s/%s", namespace, key)
+		check = func() (string, bool, error) {
+			attrs, err := config.NewComputeClient(userAgent).Instances.GetGuestAttributes(project, zone, name).QueryPath(namespace + "/").Do()
+			if err != nil {
+				// Guest attributes return 404 until the guest has written one.
+				if transport_tpg.IsGoogleApiErrorWithCode(err, 404) {
+					return "", false, nil
+				}
+				return "", false, err
+			}
+			if attrs.QueryValue == nil {
+				return "", false, nil
+			}
+			for _, item := range attrs.QueryValue.Items {
+				if item.Namespace == namespace && item.Key == key && re.MatchString(item.Value) {
+					return item.Value, true, nil
+				}
+			}
+			return "", false, nil
+		}
+	} else {
+		port := int64(d.Get("wait_for.0.serial_port_output.0.port").(int))
+		if port == 0 {
+			port = 1
+		}
+		re, err := regexp.Compile(d.Get("wait_for.0.serial_port_output.0.regex").(string))
+		if err != nil {
+			return err
+		}
+		activity = fmt.Sprintf("serial port %d output matching %q", port, re.String())
+
+		var start int64
+		var seen string
+		check = func() (string, bool, error) {
+			output, err := config.NewComputeClient(userAgent).Instances.GetSerialPortOutput(project, zone, name).Port(port).Start(start).Do()
+			if err != nil {
+				return "", false, err
+			}
+			start = output.Next
+
+			var match string
+			var ok bool
+			seen, match, ok = serialPortOutputMatch(seen, output.Contents, re)
+			return match, ok, nil
+		}
+	}
+
+	log.Printf("[DEBUG] Waiting up to %s for instance %s to report readiness via %s", timeout, name, activity)
+	var matched string
+	err = resource.Retry(timeout, func() *resource.RetryError {
+		value, ok, err := check()
+		if err != nil {
+			return resource.NonRetryableError(err)
+		}
+		if !ok {
+			return resource.RetryableError(fmt.Errorf("instance %s has not reported readiness via %s yet", name, activity))
+		}
+		matched = value
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Error waiting for instance %s to become ready: %s", name, err)
+	}
+
+	waitFor := d.Get("wait_for").([]interface{})[0].(map[string]interface{})
+	waitFor["ready"] = true
+	waitFor["matched_value"] = matched
+	waitFor["ready_time"] = time.Now().UTC().Format(time.RFC3339)
+	if err := d.Set("wait_for", []interface{}{waitFor}); err != nil {
+		return fmt.Errorf("Error setting wait_for: %s", err)
+	}
+
+	return nil
+}
+
+// serialPortOutputMatch appends contents to the output seen so far and looks
+// for re in the result. It returns the (bounded) output to carry over to the
+// next read, and the matching text if there was a match.
+func serialPortOutputMatch(seen, contents string, re *regexp.Regexp) (string, string, bool) {
+	buf := seen + contents
+	if loc := re.FindStringIndex(buf); loc != nil {
+		return buf, buf[loc[0]:loc[1]], true
+	}
+
+	if len(buf) > instanceWaitForSerialPortOverlap {
+		buf = buf[len(buf)-instanceWaitForSerialPortOverlap:]
+		// Don't start the carried over output partway through a line.
+		if i := strings.IndexByte(buf, '\n'); i >= 0 {
+			buf = buf[i+1:]
+		}
+	}
+	return buf, "", false
+}
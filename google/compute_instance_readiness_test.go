@@ -0,0 +1,41 @@
+package google
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestSerialPortOutputMatch(t *testing.T) {
+	t.Parallel()
+
+	re := regexp.MustCompile(`startup-script exit status (\d+)`)
+
+	// A match split across two reads is still found.
+	seen, match, ok := serialPortOutputMatch("", "booting\nstartup-script exit", re)
+	if ok {
+		t.Fatalf("unexpected match %q", match)
+	}
+	seen, match, ok = serialPortOutputMatch(seen, " status 0\n", re)
+	if !ok || match != "startup-script exit status 0" {
+		t.Fatalf("expected a match across reads, got %q (%t)", match, ok)
+	}
+
+	// Carried over output stays bounded and starts at a line boundary.
+	long := strings.Repeat("kernel: some noisy boot line\n", 5000)
+	seen, _, ok = serialPortOutputMatch("", long, re)
+	if ok {
+		t.Fatal("unexpected match in noise")
+	}
+	if len(seen) > instanceWaitForSerialPortOverlap {
+		t.Errorf("expected at most %d bytes carried over, got %d", instanceWaitForSerialPortOverlap, len(seen))
+	}
+	if !strings.HasPrefix(seen, "kernel:") {
+		t.Errorf("expected carried over output to start at a line boundary, got %q", seen[:20])
+	}
+
+	// Patterns that can match the empty string still count as a match.
+	if _, _, ok := serialPortOutputMatch("", "", regexp.MustCompile(`.*`)); !ok {
+		t.Error("expected an empty match to be reported")
+	}
+}
@@ -0,0 +1,180 @@
+package google
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-provider-google/google/tpgresource"
+	transport_tpg "github.com/hashicorp/terraform-provider-google/google/transport"
+)
+
+func DataSourceArtifactRegistryDockerImage() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceArtifactRegistryDockerImageRead,
+
+		Schema: map[string]*schema.Schema{
+			"location": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: `The location of the repository.`,
+			},
+			"repository_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: `The ID of the repository.`,
+			},
+			"image_name": {
+				Type:     schema.TypeString,
+				Required: true,
+				Description: `The name of the image in the repository, optionally followed by a tag, as in
+app:prod, or a digest, as in app@sha256:... Defaults to the latest tag.`,
+			},
+			"project": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The resource name of the image.`,
+			},
+			"self_link": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The URI of the image pinned to its digest, to pull or deploy it.`,
+			},
+			"digest": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The digest of the image.`,
+			},
+			"tags": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: `The tags of the image.`,
+			},
+			"image_size_bytes": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The size of the image in bytes.`,
+			},
+			"media_type": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The media type of the image manifest.`,
+			},
+			"upload_time": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The time the image was uploaded.`,
+			},
+			"build_time": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The time the image was built.`,
+			},
+			"update_time": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The time the image was last updated.`,
+			},
+		},
+	}
+}
+
+// parseArtifactRegistryDockerImageName splits an image name into the image and
+// either its digest or its tag, which defaults to latest as with docker pull.
+// As with docker pull, a digest takes precedence over a tag.
+func parseArtifactRegistryDockerImageName(name string) (image, tag, digest string) {
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, digest = name[:i], name[i+1:]
+	}
+	image, tag = name, "latest"
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		image, tag = name[:i], name[i+1:]
+	}
+	if digest != "" {
+		tag = ""
+	}
+	return image, tag, digest
+}
+
+func dataSourceArtifactRegistryDockerImageRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	repositoryUrl, project, err := artifactRegistryRepositoryUrl(d, config)
+	if err != nil {
+		return err
+	}
+
+	image, tag, digest := parseArtifactRegistryDockerImageName(d.Get("image_name").(string))
+	if digest == "" {
+		tagUrl := fmt.Sprintf("%s/tags/%s", artifactRegistryPackageUrl(repositoryUrl, image), tag)
+		res, err := transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
+			Config:    config,
+			Method:    "GET",
+			Project:   project,
+			RawURL:    tagUrl,
+			UserAgent: userAgent,
+		})
+		if err != nil {
+			return fmt.Errorf("Error reading tag %q of image %q: %s", tag, image, err)
+		}
+		version, _ := res["version"].(string)
+		digest = artifactRegistryResourceId(version)
+	}
+
+	imageUrl := fmt.Sprintf("%s/dockerImages/%s@%s", repositoryUrl, url.PathEscape(image), digest)
+	res, err := transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
+		Config:    config,
+		Method:    "GET",
+		Project:   project,
+		RawURL:    imageUrl,
+		UserAgent: userAgent,
+	})
+	if err != nil {
+		return fmt.Errorf("Error reading image %s@%s: %s", image, digest, err)
+	}
+
+	if err := d.Set("project", project); err != nil {
+		return fmt.Errorf("Error setting project: %s", err)
+	}
+	if err := d.Set("name", res["name"]); err != nil {
+		return fmt.Errorf("Error setting name: %s", err)
+	}
+	if err := d.Set("self_link", res["uri"]); err != nil {
+		return fmt.Errorf("Error setting self_link: %s", err)
+	}
+	if err := d.Set("digest", digest); err != nil {
+		return fmt.Errorf("Error setting digest: %s", err)
+	}
+	if err := d.Set("tags", res["tags"]); err != nil {
+		return fmt.Errorf("Error setting tags: %s", err)
+	}
+	if err := d.Set("image_size_bytes", res["imageSizeBytes"]); err != nil {
+		return fmt.Errorf("Error setting image_size_bytes: %s", err)
+	}
+	if err := d.Set("media_type", res["mediaType"]); err != nil {
+		return fmt.Errorf("Error setting media_type: %s", err)
+	}
+	if err := d.Set("upload_time", res["uploadTime"]); err != nil {
+		return fmt.Errorf("Error setting upload_time: %s", err)
+	}
+	if err := d.Set("build_time", res["buildTime"]); err != nil {
+		return fmt.Errorf("Error setting build_time: %s", err)
+	}
+	if err := d.Set("update_time", res["updateTime"]); err != nil {
+		return fmt.Errorf("Error setting update_time: %s", err)
+	}
+
+	d.SetId(fmt.Sprintf("%v", res["name"]))
+	return nil
+}
@@ -0,0 +1,121 @@
+package google
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+
+	"github.com/hashicorp/terraform-provider-google/google/acctest"
+)
+
+func TestParseArtifactRegistryDockerImageName(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string][3]string{
+		"app":                    {"app", "latest", ""},
+		"app:prod":               {"app", "prod", ""},
+		"team/app:v1.2":          {"team/app", "v1.2", ""},
+		"team/app":               {"team/app", "latest", ""},
+		"app@sha256:0123abcd":    {"app", "", "sha256:0123abcd"},
+		"app:prod@sha256:0123ab": {"app", "", "sha256:0123ab"},
+	}
+
+	for name, expected := range cases {
+		image, tag, digest := parseArtifactRegistryDockerImageName(name)
+		if got := [3]string{image, tag, digest}; got != expected {
+			t.Errorf("%s: got %v, expected %v", name, got, expected)
+		}
+	}
+}
+
+func TestArtifactRegistryResourceId(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"projects/p/locations/us/repositories/r/packages/team%2Fapp":                   "team/app",
+		"projects/p/locations/us/repositories/r/packages/app/versions/sha256:0123abcd": "sha256:0123abcd",
+		"projects/p/locations/us/repositories/r/packages/%40scope%2Flib/tags/latest":   "latest",
+		"projects/p/locations/us/repositories/r/packages/%40scope%2Flib":               "@scope/lib",
+	}
+
+	for name, expected := range cases {
+		if got := artifactRegistryResourceId(name); got != expected {
+			t.Errorf("%s: got %q, expected %q", name, got, expected)
+		}
+	}
+
+	if got, expected := artifactRegistryPackageUrl("https://example.com/r", "team/app"), "https://example.com/r/packages/team%2Fapp"; got != expected {
+		t.Errorf("got package URL %q, expected %q", got, expected)
+	}
+}
+
+func TestAccDataSourceArtifactRegistryDockerImage_basic(t *testing.T) {
+	t.Parallel()
+
+	context := map[string]interface{}{
+		"random_suffix": RandString(t, 10),
+	}
+
+	VcrTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.AccTestPreCheck(t) },
+		ProtoV5ProviderFactories: ProtoV5ProviderFactories(t),
+		CheckDestroy:             testAccCheckArtifactRegistryRepositoryDestroyProducer(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceArtifactRegistryDockerImage_basic(context),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.google_artifact_registry_docker_image.tag", "self_link", "google_cloudbuild_build.push", "built_images.0.image"),
+					resource.TestCheckResourceAttrPair("data.google_artifact_registry_docker_image.tag", "digest", "google_cloudbuild_build.push", "built_images.0.digest"),
+					resource.TestCheckResourceAttr("data.google_artifact_registry_docker_image.tag", "tags.0", "prod"),
+					resource.TestCheckResourceAttrPair("data.google_artifact_registry_docker_image.digest", "name", "data.google_artifact_registry_docker_image.tag", "name"),
+				),
+			},
+		},
+	})
+}
+
+func testAccArtifactRegistryDockerImage_push(context map[string]interface{}) string {
+	return Nprintf(`
+resource "google_artifact_registry_repository" "repo" {
+  location      = "us-central1"
+  repository_id = "tf-test-repo-%{random_suffix}"
+  format        = "DOCKER"
+}
+
+locals {
+  image = "us-central1-docker.pkg.dev/${google_artifact_registry_repository.repo.project}/${google_artifact_registry_repository.repo.repository_id}/team/busybox:prod"
+}
+
+resource "google_cloudbuild_build" "push" {
+  build {
+    step {
+      name = "gcr.io/cloud-builders/docker"
+      args = ["pull", "busybox"]
+    }
+    step {
+      name = "gcr.io/cloud-builders/docker"
+      args = ["tag", "busybox", local.image]
+    }
+    images = [local.image]
+  }
+}
+`, context)
+}
+
+func testAccDataSourceArtifactRegistryDockerImage_basic(context map[string]interface{}) string {
+	return testAccArtifactRegistryDockerImage_push(context) + `
+data "google_artifact_registry_docker_image" "tag" {
+  location      = google_artifact_registry_repository.repo.location
+  repository_id = google_artifact_registry_repository.repo.repository_id
+  image_name    = "team/busybox:prod"
+
+  depends_on = [google_cloudbuild_build.push]
+}
+
+data "google_artifact_registry_docker_image" "digest" {
+  location      = google_artifact_registry_repository.repo.location
+  repository_id = google_artifact_registry_repository.repo.repository_id
+  image_name    = "team/busybox@${google_cloudbuild_build.push.built_images[0].digest}"
+}
+`
+}
@@ -0,0 +1,105 @@
+package google
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-provider-google/google/tpgresource"
+	transport_tpg "github.com/hashicorp/terraform-provider-google/google/transport"
+)
+
+func DataSourceArtifactRegistryPackages() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceArtifactRegistryPackagesRead,
+
+		Schema: map[string]*schema.Schema{
+			"location": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: `The location of the repository.`,
+			},
+			"repository_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: `The ID of the repository.`,
+			},
+			"filter": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: `An expression filtering the packages, on their name or annotations, e.g.
+name="projects/my-project/locations/us/repositories/repo/packages/app*".`,
+			},
+			"project": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"packages": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: `The packages of the repository.`,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: `The ID of the package, e.g. the name of a Docker image or a Maven artifact.`,
+						},
+						"display_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"create_time": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"update_time": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceArtifactRegistryPackagesRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	repositoryUrl, project, err := artifactRegistryRepositoryUrl(d, config)
+	if err != nil {
+		return err
+	}
+
+	res, err := listArtifactRegistryResources(config, userAgent, project, repositoryUrl+"/packages", "packages", map[string]string{
+		"filter": d.Get("filter").(string),
+	})
+	if err != nil {
+		return fmt.Errorf("Error listing packages: %s", err)
+	}
+
+	packages := make([]interface{}, 0, len(res))
+	for _, raw := range res {
+		p := raw.(map[string]interface{})
+		packages = append(packages, map[string]interface{}{
+			"name":         artifactRegistryResourceId(fmt.Sprintf("%v", p["name"])),
+			"display_name": p["displayName"],
+			"create_time":  p["createTime"],
+			"update_time":  p["updateTime"],
+		})
+	}
+
+	if err := d.Set("packages", packages); err != nil {
+		return fmt.Errorf("Error setting packages: %s", err)
+	}
+	if err := d.Set("project", project); err != nil {
+		return fmt.Errorf("Error setting project: %s", err)
+	}
+	d.SetId(fmt.Sprintf("projects/%s/locations/%s/repositories/%s/packages", project, d.Get("location"), d.Get("repository_id")))
+	return nil
+}
@@ -0,0 +1,102 @@
+package google
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-provider-google/google/tpgresource"
+	transport_tpg "github.com/hashicorp/terraform-provider-google/google/transport"
+)
+
+func DataSourceArtifactRegistryTags() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceArtifactRegistryTagsRead,
+
+		Schema: map[string]*schema.Schema{
+			"location": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: `The location of the repository.`,
+			},
+			"repository_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: `The ID of the repository.`,
+			},
+			"package_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: `The ID of the package, e.g. the name of a Docker image or a Maven artifact.`,
+			},
+			"filter": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: `An expression filtering the tags, on their name or version, e.g.
+version="projects/my-project/locations/us/repositories/repo/packages/app/versions/sha256:...".`,
+			},
+			"project": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"tags": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: `The tags of the package.`,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: `The tag.`,
+						},
+						"version": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: `The ID of the version the tag points to, e.g. the digest of a Docker image.`,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceArtifactRegistryTagsRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	repositoryUrl, project, err := artifactRegistryRepositoryUrl(d, config)
+	if err != nil {
+		return err
+	}
+	packageUrl := artifactRegistryPackageUrl(repositoryUrl, d.Get("package_name").(string))
+
+	res, err := listArtifactRegistryResources(config, userAgent, project, packageUrl+"/tags", "tags", map[string]string{
+		"filter": d.Get("filter").(string),
+	})
+	if err != nil {
+		return fmt.Errorf("Error listing tags of package %q: %s", d.Get("package_name"), err)
+	}
+
+	tags := make([]interface{}, 0, len(res))
+	for _, raw := range res {
+		t := raw.(map[string]interface{})
+		tags = append(tags, map[string]interface{}{
+			"name":    artifactRegistryResourceId(fmt.Sprintf("%v", t["name"])),
+			"version": artifactRegistryResourceId(fmt.Sprintf("%v", t["version"])),
+		})
+	}
+
+	if err := d.Set("tags", tags); err != nil {
+		return fmt.Errorf("Error setting tags: %s", err)
+	}
+	if err := d.Set("project", project); err != nil {
+		return fmt.Errorf("Error setting project: %s", err)
+	}
+	d.SetId(fmt.Sprintf("projects/%s/locations/%s/repositories/%s/packages/%s/tags", project, d.Get("location"), d.Get("repository_id"), d.Get("package_name")))
+	return nil
+}
@@ -0,0 +1,142 @@
+package google
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-provider-google/google/tpgresource"
+	transport_tpg "github.com/hashicorp/terraform-provider-google/google/transport"
+)
+
+func DataSourceArtifactRegistryVersions() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceArtifactRegistryVersionsRead,
+
+		Schema: map[string]*schema.Schema{
+			"location": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: `The location of the repository.`,
+			},
+			"repository_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: `The ID of the repository.`,
+			},
+			"package_name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: `The ID of the package, e.g. the name of a Docker image or a Maven artifact.`,
+			},
+			"filter": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: `An expression filtering the versions, on their name or annotations, e.g.
+name="projects/my-project/locations/us/repositories/repo/packages/lib/versions/1.*".`,
+			},
+			"project": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"versions": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: `The versions of the package, newest first.`,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: `The ID of the version, e.g. 1.0.0, or the digest of a Docker image.`,
+						},
+						"description": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"related_tags": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: `The tags pointing to the version.`,
+						},
+						"create_time": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"update_time": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceArtifactRegistryVersionsRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	repositoryUrl, project, err := artifactRegistryRepositoryUrl(d, config)
+	if err != nil {
+		return err
+	}
+	packageUrl := artifactRegistryPackageUrl(repositoryUrl, d.Get("package_name").(string))
+
+	res, err := listArtifactRegistryResources(config, userAgent, project, packageUrl+"/versions", "versions", map[string]string{
+		"filter": d.Get("filter").(string),
+		"view":   "FULL",
+	})
+	if err != nil {
+		return fmt.Errorf("Error listing versions of package %q: %s", d.Get("package_name"), err)
+	}
+
+	if err := d.Set("versions", flattenArtifactRegistryVersions(res)); err != nil {
+		return fmt.Errorf("Error setting versions: %s", err)
+	}
+	if err := d.Set("project", project); err != nil {
+		return fmt.Errorf("Error setting project: %s", err)
+	}
+	d.SetId(fmt.Sprintf("projects/%s/locations/%s/repositories/%s/packages/%s/versions", project, d.Get("location"), d.Get("repository_id"), d.Get("package_name")))
+	return nil
+}
+
+// flattenArtifactRegistryVersions returns the versions newest first, so that
+// the first one is the latest version matching the filter.
+func flattenArtifactRegistryVersions(res []interface{}) []interface{} {
+	versions := make([]interface{}, 0, len(res))
+	for _, raw := range res {
+		v := raw.(map[string]interface{})
+		tags := []interface{}{}
+		if related, ok := v["relatedTags"].([]interface{}); ok {
+			for _, t := range related {
+				tag, _ := t.(map[string]interface{})
+				tags = append(tags, artifactRegistryResourceId(fmt.Sprintf("%v", tag["name"])))
+			}
+		}
+		versions = append(versions, map[string]interface{}{
+			"name":         artifactRegistryResourceId(fmt.Sprintf("%v", v["name"])),
+			"description":  v["description"],
+			"related_tags": tags,
+			"create_time":  v["createTime"],
+			"update_time":  v["updateTime"],
+		})
+	}
+
+	createTime := func(v interface{}) time.Time {
+		s, _ := v.(map[string]interface{})["create_time"].(string)
+		t, _ := time.Parse(time.RFC3339Nano, s)
+		return t
+	}
+	sort.SliceStable(versions, func(i, j int) bool {
+		return createTime(versions[i]).After(createTime(versions[j]))
+	})
+	return versions
+}
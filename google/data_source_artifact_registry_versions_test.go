@@ -0,0 +1,98 @@
+package google
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+
+	"github.com/hashicorp/terraform-provider-google/google/acctest"
+)
+
+func TestFlattenArtifactRegistryVersions(t *testing.T) {
+	t.Parallel()
+
+	res := []interface{}{
+		map[string]interface{}{
+			"name":       "projects/p/locations/us/repositories/r/packages/lib/versions/1.0.0",
+			"createTime": "2023-05-01T10:00:00.5Z",
+		},
+		map[string]interface{}{
+			"name":       "projects/p/locations/us/repositories/r/packages/lib/versions/1.1.0",
+			"createTime": "2023-05-02T09:00:00Z",
+			"relatedTags": []interface{}{
+				map[string]interface{}{"name": "projects/p/locations/us/repositories/r/packages/lib/tags/latest"},
+			},
+		},
+		map[string]interface{}{
+			"name":       "projects/p/locations/us/repositories/r/packages/lib/versions/1.0.1",
+			"createTime": "2023-05-01T10:00:00.25Z",
+		},
+	}
+
+	versions := flattenArtifactRegistryVersions(res)
+	names := []string{}
+	for _, v := range versions {
+		names = append(names, v.(map[string]interface{})["name"].(string))
+	}
+	if expected := []string{"1.1.0", "1.0.0", "1.0.1"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("got versions %v, expected %v", names, expected)
+	}
+	if tags := versions[0].(map[string]interface{})["related_tags"]; !reflect.DeepEqual(tags, []interface{}{"latest"}) {
+		t.Errorf("got related tags %v, expected [latest]", tags)
+	}
+}
+
+func TestAccDataSourceArtifactRegistryVersions_basic(t *testing.T) {
+	t.Parallel()
+
+	context := map[string]interface{}{
+		"random_suffix": RandString(t, 10),
+	}
+
+	VcrTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.AccTestPreCheck(t) },
+		ProtoV5ProviderFactories: ProtoV5ProviderFactories(t),
+		CheckDestroy:             testAccCheckArtifactRegistryRepositoryDestroyProducer(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceArtifactRegistryVersions_basic(context),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.google_artifact_registry_packages.packages", "packages.#", "1"),
+					resource.TestCheckResourceAttr("data.google_artifact_registry_packages.packages", "packages.0.name", "team/busybox"),
+					resource.TestCheckResourceAttr("data.google_artifact_registry_versions.versions", "versions.#", "1"),
+					resource.TestCheckResourceAttrPair("data.google_artifact_registry_versions.versions", "versions.0.name", "google_cloudbuild_build.push", "built_images.0.digest"),
+					resource.TestCheckResourceAttr("data.google_artifact_registry_versions.versions", "versions.0.related_tags.0", "prod"),
+					resource.TestCheckResourceAttr("data.google_artifact_registry_tags.tags", "tags.0.name", "prod"),
+					resource.TestCheckResourceAttrPair("data.google_artifact_registry_tags.tags", "tags.0.version", "google_cloudbuild_build.push", "built_images.0.digest"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDataSourceArtifactRegistryVersions_basic(context map[string]interface{}) string {
+	return testAccArtifactRegistryDockerImage_push(context) + `
+data "google_artifact_registry_packages" "packages" {
+  location      = google_artifact_registry_repository.repo.location
+  repository_id = google_artifact_registry_repository.repo.repository_id
+
+  depends_on = [google_cloudbuild_build.push]
+}
+
+data "google_artifact_registry_versions" "versions" {
+  location      = google_artifact_registry_repository.repo.location
+  repository_id = google_artifact_registry_repository.repo.repository_id
+  package_name  = data.google_artifact_registry_packages.packages.packages[0].name
+}
+
+data "google_artifact_registry_tags" "tags" {
+  location      = google_artifact_registry_repository.repo.location
+  repository_id = google_artifact_registry_repository.repo.repository_id
+  package_name  = "team/busybox"
+  filter        = "name=\"${google_artifact_registry_repository.repo.id}/packages/team%2Fbusybox/tags/prod\""
+
+  depends_on = [google_cloudbuild_build.push]
+}
+`
+}
@@ -0,0 +1,388 @@
+package google
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-provider-google/google/tpgresource"
+	transport_tpg "github.com/hashicorp/terraform-provider-google/google/transport"
+
+	"google.golang.org/api/compute/v1"
+)
+
+const (
+	effectiveFirewallSourceHierarchical    = "HIERARCHICAL_FIREWALL_POLICY"
+	effectiveFirewallSourceNetworkPolicy   = "NETWORK_FIREWALL_POLICY"
+	effectiveFirewallSourceRegionalPolicy  = "REGIONAL_NETWORK_FIREWALL_POLICY"
+	effectiveFirewallSourceVpcFirewallRule = "VPC_FIREWALL_RULE"
+)
+
+// effectiveFirewallPolicy is the common shape of the per-network and
+// per-instance effective firewall policy messages, which are identical but
+// distinct types in the API client.
+type effectiveFirewallPolicy struct {
+	Name      string
+	ShortName string
+	Type      string
+	Rules     []*compute.FirewallPolicyRule
+}
+
+func DataSourceGoogleComputeEffectiveFirewalls() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceGoogleComputeEffectiveFirewallsRead,
+
+		Schema: map[string]*schema.Schema{
+			"network": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ExactlyOneOf:     []string{"network", "instance"},
+				DiffSuppressFunc: tpgresource.CompareSelfLinkOrResourceName,
+				Description:      `The name or self link of the network to get the effective firewalls of.`,
+			},
+			"instance": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"network", "instance"},
+				Description:  `The name or self link of the instance to get the effective firewalls of.`,
+			},
+			"network_interface": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: `The name of the instance's network interface. Defaults to nic0.`,
+			},
+			"zone": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"project": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"enforcement_order": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `Whether VPC firewall rules are evaluated before (AFTER_CLASSIC_FIREWALL) or after (BEFORE_CLASSIC_FIREWALL) network firewall policies.`,
+			},
+			"rules": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: `Every rule that applies, in the order it is evaluated.`,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"source": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"policy": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"self_link": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"priority": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"direction": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"action": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"disabled": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"source_ranges": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"destination_ranges": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"source_tags": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"target_tags": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"target_service_accounts": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"target_resources": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"layer4_configs": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"ip_protocol": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"ports": {
+										Type:     schema.TypeList,
+										Computed: true,
+										Elem:     &schema.Schema{Type: schema.TypeString},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceGoogleComputeEffectiveFirewallsRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	project, err := tpgresource.GetProject(d, config)
+	if err != nil {
+		return err
+	}
+
+	client := config.NewComputeClient(userAgent)
+
+	var id string
+	var network *tpgresource.GlobalFieldValue
+	var policies []effectiveFirewallPolicy
+	var firewalls []*compute.Firewall
+	if v, ok := d.GetOk("instance"); ok {
+		instance, err := tpgresource.ParseInstanceFieldValue(v.(string), d, config)
+		if err != nil {
+			return err
+		}
+		nicName := d.Get("network_interface").(string)
+		if nicName == "" {
+			nicName = "nic0"
+		}
+
+		res, err := client.Instances.GetEffectiveFirewalls(instance.Project, instance.Zone, instance.Name, nicName).Do()
+		if err != nil {
+			return fmt.Errorf("Error reading effective firewalls for instance %s: %s", instance.Name, err)
+		}
+		firewalls = res.Firewalls
+		for _, p := range res.FirewallPolicys {
+			policies = append(policies, effectiveFirewallPolicy{Name: p.Name, ShortName: p.ShortName, Type: p.Type, Rules: p.Rules})
+		}
+
+		inst, err := client.Instances.Get(instance.Project, instance.Zone, instance.Name).Do()
+		if err != nil {
+			return fmt.Errorf("Error reading instance %s: %s", instance.Name, err)
+		}
+		for _, nic := range inst.NetworkInterfaces {
+			if nic.Name == nicName {
+				network, err = tpgresource.ParseNetworkFieldValue(nic.Network, d, config)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		if network == nil {
+			return fmt.Errorf("Instance %s has no network interface named %s", instance.Name, nicName)
+		}
+
+		project = instance.Project
+		if err := d.Set("zone", instance.Zone); err != nil {
+			return fmt.Errorf("Error setting zone: %s", err)
+		}
+		id = fmt.Sprintf("%s/networkInterfaces/%s", instance.RelativeLink(), nicName)
+	} else {
+		network, err = tpgresource.ParseNetworkFieldValue(d.Get("network").(string), d, config)
+		if err != nil {
+			return err
+		}
+
+		res, err := client.Networks.GetEffectiveFirewalls(network.Project, network.Name).Do()
+		if err != nil {
+			return fmt.Errorf("Error reading effective firewalls for network %s: %s", network.Name, err)
+		}
+		firewalls = res.Firewalls
+		for _, p := range res.FirewallPolicys {
+			policies = append(policies, effectiveFirewallPolicy{Name: p.Name, ShortName: p.ShortName, Type: p.Type, Rules: p.Rules})
+		}
+
+		project = network.Project
+		id = network.RelativeLink()
+	}
+
+	net, err := client.Networks.Get(network.Project, network.Name).Do()
+	if err != nil {
+		return fmt.Errorf("Error reading network %s: %s", network.Name, err)
+	}
+	enforcementOrder := net.NetworkFirewallPolicyEnforcementOrder
+	if enforcementOrder == "" {
+		enforcementOrder = "AFTER_CLASSIC_FIREWALL"
+	}
+
+	if err := d.Set("rules", orderEffectiveFirewallRules(policies, firewalls, enforcementOrder)); err != nil {
+		return fmt.Errorf("Error setting rules: %s", err)
+	}
+	if err := d.Set("enforcement_order", enforcementOrder); err != nil {
+		return fmt.Errorf("Error setting enforcement_order: %s", err)
+	}
+	if err := d.Set("project", project); err != nil {
+		return fmt.Errorf("Error setting project: %s", err)
+	}
+
+	d.SetId(id)
+	return nil
+}
+
+// orderEffectiveFirewallRules flattens the firewall policies and VPC firewall
+// rules that apply to a network into a single list, in the order Cloud
+// Firewall evaluates them: hierarchical policies from the organization down,
+// then VPC firewall rules and the network's firewall policies in the order
+// set by the network's enforcement order. Within each tier, rules are sorted
+// by priority.
+func orderEffectiveFirewallRules(policies []effectiveFirewallPolicy, firewalls []*compute.Firewall, enforcementOrder string) []map[string]interface{} {
+	var hierarchical, network, vpc []map[string]interface{}
+
+	for _, p := range policies {
+		source := effectiveFirewallSourceHierarchical
+		switch p.Type {
+		case "NETWORK":
+			source = effectiveFirewallSourceNetworkPolicy
+		case "NETWORK_REGIONAL":
+			source = effectiveFirewallSourceRegionalPolicy
+		}
+
+		policyName := p.Name
+		if p.ShortName != "" {
+			policyName = p.ShortName
+		}
+
+		rules := make([]map[string]interface{}, 0, len(p.Rules))
+		for _, r := range p.Rules {
+			rules = append(rules, flattenEffectiveFirewallPolicyRule(source, policyName, r))
+		}
+		sortEffectiveFirewallRules(rules)
+
+		if source == effectiveFirewallSourceHierarchical {
+			hierarchical = append(hierarchical, rules...)
+		} else {
+			network = append(network, rules...)
+		}
+	}
+
+	for _, fw := range firewalls {
+		vpc = append(vpc, flattenEffectiveVpcFirewallRule(fw))
+	}
+	sortEffectiveFirewallRules(vpc)
+
+	ordered := append([]map[string]interface{}{}, hierarchical...)
+	if enforcementOrder == "BEFORE_CLASSIC_FIREWALL" {
+		ordered = append(ordered, network...)
+		ordered = append(ordered, vpc...)
+	} else {
+		ordered = append(ordered, vpc...)
+		ordered = append(ordered, network...)
+	}
+	return ordered
+}
+
+// sortEffectiveFirewallRules orders rules by ascending priority. For rules
+// with equal priority, deny rules take precedence over allow rules.
+func sortEffectiveFirewallRules(rules []map[string]interface{}) {
+	sort.SliceStable(rules, func(i, j int) bool {
+		pi, pj := rules[i]["priority"].(int), rules[j]["priority"].(int)
+		if pi != pj {
+			return pi < pj
+		}
+		return rules[i]["action"] == "deny" && rules[j]["action"] != "deny"
+	})
+}
+
+func flattenEffectiveFirewallPolicyRule(source, policy string, r *compute.FirewallPolicyRule) map[string]interface{} {
+	rule := map[string]interface{}{
+		"source":                  source,
+		"policy":                  policy,
+		"name":                    r.RuleName,
+		"priority":                int(r.Priority),
+		"direction":               r.Direction,
+		"action":                  strings.ToLower(r.Action),
+		"disabled":                r.Disabled,
+		"target_service_accounts": r.TargetServiceAccounts,
+		"target_resources":        r.TargetResources,
+	}
+	if r.Match != nil {
+		rule["source_ranges"] = r.Match.SrcIpRanges
+		rule["destination_ranges"] = r.Match.DestIpRanges
+
+		layer4 := make([]map[string]interface{}, 0, len(r.Match.Layer4Configs))
+		for _, c := range r.Match.Layer4Configs {
+			layer4 = append(layer4, map[string]interface{}{
+				"ip_protocol": c.IpProtocol,
+				"ports":       c.Ports,
+			})
+		}
+		rule["layer4_configs"] = layer4
+	}
+	return rule
+}
+
+func flattenEffectiveVpcFirewallRule(fw *compute.Firewall) map[string]interface{} {
+	action := "allow"
+	layer4 := make([]map[string]interface{}, 0)
+	for _, a := range fw.Allowed {
+		layer4 = append(layer4, map[string]interface{}{
+			"ip_protocol": a.IPProtocol,
+			"ports":       a.Ports,
+		})
+	}
+	if len(fw.Denied) > 0 {
+		action = "deny"
+		for _, a := range fw.Denied {
+			layer4 = append(layer4, map[string]interface{}{
+				"ip_protocol": a.IPProtocol,
+				"ports":       a.Ports,
+			})
+		}
+	}
+
+	return map[string]interface{}{
+		"source":                  effectiveFirewallSourceVpcFirewallRule,
+		"name":                    fw.Name,
+		"self_link":               fw.SelfLink,
+		"priority":                int(fw.Priority),
+		"direction":               fw.Direction,
+		"action":                  action,
+		"disabled":                fw.Disabled,
+		"source_ranges":           fw.SourceRanges,
+		"destination_ranges":      fw.DestinationRanges,
+		"source_tags":             fw.SourceTags,
+		"target_tags":             fw.TargetTags,
+		"target_service_accounts": fw.TargetServiceAccounts,
+		"layer4_configs":          layer4,
+	}
+}
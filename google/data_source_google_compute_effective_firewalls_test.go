@@ -0,0 +1,128 @@
+package google
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-provider-google/google/acctest"
+
+	"google.golang.org/api/compute/v1"
+)
+
+func TestOrderEffectiveFirewallRules(t *testing.T) {
+	t.Parallel()
+
+	policies := []effectiveFirewallPolicy{
+		{
+			Name:      "123456",
+			ShortName: "org-baseline",
+			Type:      "HIERARCHY",
+			Rules: []*compute.FirewallPolicyRule{
+				{RuleName: "goto-next", Priority: 2147483647, Action: "goto_next", Direction: "INGRESS"},
+				{RuleName: "deny-telnet", Priority: 100, Action: "deny", Direction: "INGRESS"},
+			},
+		},
+		{
+			Name: "net-policy",
+			Type: "NETWORK",
+			Rules: []*compute.FirewallPolicyRule{
+				{RuleName: "allow-health-checks", Priority: 1000, Action: "allow", Direction: "INGRESS"},
+			},
+		},
+	}
+	firewalls := []*compute.Firewall{
+		{Name: "allow-ssh", Priority: 1000, Direction: "INGRESS", Allowed: []*compute.FirewallAllowed{{IPProtocol: "tcp", Ports: []string{"22"}}}, SourceRanges: []string{"0.0.0.0/0"}},
+		{Name: "deny-ssh", Priority: 1000, Direction: "INGRESS", Denied: []*compute.FirewallDenied{{IPProtocol: "tcp", Ports: []string{"22"}}}},
+		{Name: "allow-internal", Priority: 65534, Direction: "INGRESS", Allowed: []*compute.FirewallAllowed{{IPProtocol: "all"}}},
+	}
+
+	cases := map[string]struct {
+		EnforcementOrder string
+		Expected         []string
+	}{
+		"after classic firewall": {
+			EnforcementOrder: "AFTER_CLASSIC_FIREWALL",
+			Expected:         []string{"deny-telnet", "goto-next", "deny-ssh", "allow-ssh", "allow-internal", "allow-health-checks"},
+		},
+		"before classic firewall": {
+			EnforcementOrder: "BEFORE_CLASSIC_FIREWALL",
+			Expected:         []string{"deny-telnet", "goto-next", "allow-health-checks", "deny-ssh", "allow-ssh", "allow-internal"},
+		},
+	}
+
+	for tn, tc := range cases {
+		rules := orderEffectiveFirewallRules(policies, firewalls, tc.EnforcementOrder)
+		if len(rules) != len(tc.Expected) {
+			t.Errorf("%s: expected %d rules, got %d", tn, len(tc.Expected), len(rules))
+			continue
+		}
+		for i, name := range tc.Expected {
+			if rules[i]["name"] != name {
+				t.Errorf("%s: expected rule %d to be %s, got %s", tn, i, name, rules[i]["name"])
+			}
+		}
+	}
+
+	rules := orderEffectiveFirewallRules(policies, firewalls, "AFTER_CLASSIC_FIREWALL")
+	if rules[0]["source"] != effectiveFirewallSourceHierarchical || rules[0]["policy"] != "org-baseline" {
+		t.Errorf("expected the first rule to come from the org-baseline hierarchical policy, got %v/%v", rules[0]["source"], rules[0]["policy"])
+	}
+	if rules[3]["source"] != effectiveFirewallSourceVpcFirewallRule || rules[3]["action"] != "allow" {
+		t.Errorf("expected allow-ssh to be an allowing VPC firewall rule, got %v/%v", rules[3]["source"], rules[3]["action"])
+	}
+}
+
+func TestAccDataSourceComputeEffectiveFirewalls_network(t *testing.T) {
+	t.Parallel()
+
+	context := map[string]interface{}{
+		"random_suffix": RandString(t, 10),
+	}
+
+	VcrTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.AccTestPreCheck(t) },
+		ProtoV5ProviderFactories: ProtoV5ProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceComputeEffectiveFirewalls_network(context),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.google_compute_effective_firewalls.fw", "enforcement_order", "AFTER_CLASSIC_FIREWALL"),
+					resource.TestCheckTypeSetElemNestedAttrs("data.google_compute_effective_firewalls.fw", "rules.*", map[string]string{
+						"source":                   "VPC_FIREWALL_RULE",
+						"name":                     "tf-test-allow-ssh-" + context["random_suffix"].(string),
+						"action":                   "allow",
+						"source_ranges.0":          "0.0.0.0/0",
+						"layer4_configs.0.ports.0": "22",
+					}),
+				),
+			},
+		},
+	})
+}
+
+func testAccDataSourceComputeEffectiveFirewalls_network(context map[string]interface{}) string {
+	return Nprintf(`
+resource "google_compute_network" "vpc" {
+  name                    = "tf-test-eff-fw-%{random_suffix}"
+  auto_create_subnetworks = false
+}
+
+resource "google_compute_firewall" "allow_ssh" {
+  name    = "tf-test-allow-ssh-%{random_suffix}"
+  network = google_compute_network.vpc.name
+
+  allow {
+    protocol = "tcp"
+    ports    = ["22"]
+  }
+
+  source_ranges = ["0.0.0.0/0"]
+}
+
+data "google_compute_effective_firewalls" "fw" {
+  network = google_compute_network.vpc.self_link
+
+  depends_on = [google_compute_firewall.allow_ssh]
+}
+`, context)
+}
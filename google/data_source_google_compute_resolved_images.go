@@ -0,0 +1,88 @@
+package google
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-provider-google/google/tpgresource"
+	transport_tpg "github.com/hashicorp/terraform-provider-google/google/transport"
+)
+
+func DataSourceGoogleComputeResolvedImages() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceGoogleComputeResolvedImagesRead,
+
+		Schema: map[string]*schema.Schema{
+			"images": {
+				Type:        schema.TypeMap,
+				Required:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: `A map of arbitrary keys to image references, such as debian-cloud/debian-11.`,
+			},
+			"project": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"self_links": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"names": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"families": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceGoogleComputeResolvedImagesRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	project, err := tpgresource.GetProject(d, config)
+	if err != nil {
+		return err
+	}
+
+	selfLinks := make(map[string]string)
+	names := make(map[string]string)
+	families := make(map[string]string)
+	for k, ref := range d.Get("images").(map[string]interface{}) {
+		log.Printf("[DEBUG] Resolving image reference %q for key %q", ref.(string), k)
+		image, err := resolveImageToImage(config, project, ref.(string), userAgent)
+		if err != nil {
+			return fmt.Errorf("Error resolving image %q for key %q: %s", ref.(string), k, err)
+		}
+		selfLinks[k] = image.SelfLink
+		names[k] = image.Name
+		families[k] = image.Family
+	}
+
+	if err := d.Set("self_links", selfLinks); err != nil {
+		return fmt.Errorf("Error setting self_links: %s", err)
+	}
+	if err := d.Set("names", names); err != nil {
+		return fmt.Errorf("Error setting names: %s", err)
+	}
+	if err := d.Set("families", families); err != nil {
+		return fmt.Errorf("Error setting families: %s", err)
+	}
+	if err := d.Set("project", project); err != nil {
+		return fmt.Errorf("Error setting project: %s", err)
+	}
+
+	d.SetId(fmt.Sprintf("projects/%s/global/images", project))
+	return nil
+}
@@ -0,0 +1,39 @@
+package google
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-provider-google/google/acctest"
+)
+
+func TestAccDataSourceComputeResolvedImages(t *testing.T) {
+	t.Parallel()
+
+	VcrTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.AccTestPreCheck(t) },
+		ProtoV5ProviderFactories: ProtoV5ProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceComputeResolvedImagesConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr("data.google_compute_resolved_images.fleet", "self_links.debian",
+						regexp.MustCompile("projects/debian-cloud/global/images/debian-11-")),
+					resource.TestCheckResourceAttr("data.google_compute_resolved_images.fleet", "families.debian", "debian-11"),
+					resource.TestMatchResourceAttr("data.google_compute_resolved_images.fleet", "self_links.ubuntu",
+						regexp.MustCompile("projects/ubuntu-os-cloud/global/images/ubuntu-2204-")),
+				),
+			},
+		},
+	})
+}
+
+const testAccDataSourceComputeResolvedImagesConfig = `
+data "google_compute_resolved_images" "fleet" {
+  images = {
+    debian = "debian-cloud/debian-11"
+    ubuntu = "projects/ubuntu-os-cloud/global/images/family/ubuntu-2204-lts"
+  }
+}
+`
@@ -0,0 +1,529 @@
+package google
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-provider-google/google/tpgresource"
+)
+
+// DataSourceGoogleComputeUrlMapRouting evaluates the routing of a URL map
+// locally, without calling any API, for a list of sample requests. It takes
+// the same routing blocks as google_compute_url_map:
+//
+//	data "google_compute_url_map_routing" "routing" {
+//	  default_service = "default"
+//	  host_rule {
+//	    hosts        = ["example.com"]
+//	    path_matcher = "main"
+//	  }
+//	  path_matcher {
+//	    name            = "main"
+//	    default_service = "web"
+//	  }
+//	  request {
+//	    host = "example.com"
+//	    path = "/"
+//	  }
+//	}
+func DataSourceGoogleComputeUrlMapRouting() *schema.Resource {
+	urlMap := ResourceComputeUrlMap().Schema
+
+	return &schema.Resource{
+		Read: dataSourceGoogleComputeUrlMapRoutingRead,
+		Schema: map[string]*schema.Schema{
+			"default_service":      urlMap["default_service"],
+			"default_url_redirect": urlMap["default_url_redirect"],
+			"default_route_action": urlMap["default_route_action"],
+			"host_rule":            urlMap["host_rule"],
+			"path_matcher":         urlMap["path_matcher"],
+
+			"request": {
+				Type:        schema.TypeList,
+				Required:    true,
+				Description: `The sample requests to route.`,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"host": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: `The host of the request, optionally with a port.`,
+						},
+						"path": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: `The path of the request, optionally with a query string.`,
+						},
+						"scheme": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "https",
+							Description: `The scheme of the request, used in redirect URLs.`,
+						},
+						"headers": {
+							Type:        schema.TypeMap,
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: `The headers of the request.`,
+						},
+					},
+				},
+			},
+
+			"results": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: `The routing of each request, in the order of the request blocks.`,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"host": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"path": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"path_matcher": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"matched_rule": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"service": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"weighted_backend_services": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"backend_service": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"weight": {
+										Type:     schema.TypeInt,
+										Computed: true,
+									},
+								},
+							},
+						},
+						"rewritten_host": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"rewritten_path": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"redirect_url": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"redirect_response_code": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// urlMapRoutingRequest is a sample request. Header names are lowercase.
+type urlMapRoutingRequest struct {
+	Scheme  string
+	Host    string
+	Path    string
+	Query   url.Values
+	Headers map[string]string
+}
+
+func newUrlMapRoutingRequest(scheme, host, path string, headers map[string]string) urlMapRoutingRequest {
+	req := urlMapRoutingRequest{Scheme: scheme, Host: host, Path: path, Headers: map[string]string{}}
+	if i := strings.Index(path, "?"); i >= 0 {
+		req.Path = path[:i]
+		req.Query, _ = url.ParseQuery(path[i+1:])
+	}
+	for k, v := range headers {
+		req.Headers[strings.ToLower(k)] = v
+	}
+	return req
+}
+
+// urlMapRoutingResult is where a request is routed.
+type urlMapRoutingResult struct {
+	PathMatcher          string
+	MatchedRule          string
+	Service              string
+	WeightedBackends     []map[string]interface{}
+	RewrittenHost        string
+	RewrittenPath        string
+	RedirectUrl          string
+	RedirectResponseCode int
+}
+
+// urlMapRoutingAction is the service, route action and URL redirect of a
+// rule or default, in the shape of the google_compute_url_map schema.
+type urlMapRoutingAction struct {
+	Service     string
+	RouteAction []interface{}
+	UrlRedirect []interface{}
+}
+
+var urlMapRedirectResponseCodes = map[string]int{
+	"":                          301,
+	"MOVED_PERMANENTLY_DEFAULT": 301,
+	"FOUND":                     302,
+	"SEE_OTHER":                 303,
+	"TEMPORARY_REDIRECT":        307,
+	"PERMANENT_REDIRECT":        308,
+}
+
+// urlMapHostMatches reports how well pattern matches host: exact matches beat
+// wildcard ones, and longer wildcard suffixes beat shorter ones. 0 is no match.
+func urlMapHostMatches(pattern, host string) int {
+	pattern, host = strings.ToLower(pattern), strings.ToLower(host)
+	if pattern == host {
+		return len(pattern) + 2
+	}
+	if strings.HasPrefix(pattern, "*") && strings.HasSuffix(host, pattern[1:]) && len(host) > len(pattern)-1 {
+		return len(pattern)
+	}
+	return 0
+}
+
+// urlMapPathRuleMatch returns the portion of path matched by a path rule's
+// path, which may end with /*, or false if it doesn't match.
+func urlMapPathRuleMatch(rulePath, path string) (string, bool) {
+	if strings.HasSuffix(rulePath, "/*") {
+		prefix := strings.TrimSuffix(rulePath, "*")
+		return prefix, strings.HasPrefix(path, prefix)
+	}
+	return rulePath, rulePath == path
+}
+
+func urlMapFullMatch(pattern, value string) (bool, error) {
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return false, fmt.Errorf("invalid regular expression %q: %s", pattern, err)
+	}
+	return re.MatchString(value), nil
+}
+
+// urlMapMatchRuleMatches evaluates one entry of a route rule's match_rules,
+// returning the matched portion of the path for prefix rewrites and redirects.
+// Metadata filters only apply to proxyless gRPC clients, and are ignored.
+func urlMapMatchRuleMatches(matchRule map[string]interface{}, req urlMapRoutingRequest) (string, bool, error) {
+	path := req.Path
+	ignoreCase, _ := matchRule["ignore_case"].(bool)
+	fold := func(s string) string {
+		if ignoreCase {
+			return strings.ToLower(s)
+		}
+		return s
+	}
+
+	matched := "/"
+	if v, _ := matchRule["prefix_match"].(string); v != "" {
+		if !strings.HasPrefix(fold(path), fold(v)) {
+			return "", false, nil
+		}
+		matched = path[:len(v)]
+	} else if v, _ := matchRule["full_path_match"].(string); v != "" {
+		if fold(path) != fold(v) {
+			return "", false, nil
+		}
+		matched = path
+	} else if v, _ := matchRule["regex_match"].(string); v != "" {
+		ok, err := urlMapFullMatch(v, path)
+		if err != nil || !ok {
+			return "", false, err
+		}
+		matched = path
+	}
+
+	for _, raw := range urlMapRoutingList(matchRule["header_matches"]) {
+		ok, err := urlMapHeaderMatches(raw, req.Headers)
+		if err != nil || !ok {
+			return "", false, err
+		}
+	}
+	for _, raw := range urlMapRoutingList(matchRule["query_parameter_matches"]) {
+		ok, err := urlMapQueryParameterMatches(raw, req.Query)
+		if err != nil || !ok {
+			return "", false, err
+		}
+	}
+	return matched, true, nil
+}
+
+func urlMapHeaderMatches(headerMatch map[string]interface{}, headers map[string]string) (bool, error) {
+	value, present := headers[strings.ToLower(headerMatch["header_name"].(string))]
+
+	var ok bool
+	if v, _ := headerMatch["exact_match"].(string); v != "" {
+		ok = present && value == v
+	} else if v, _ := headerMatch["prefix_match"].(string); v != "" {
+		ok = present && strings.HasPrefix(value, v)
+	} else if v, _ := headerMatch["suffix_match"].(string); v != "" {
+		ok = present && strings.HasSuffix(value, v)
+	} else if v, _ := headerMatch["regex_match"].(string); v != "" {
+		if present {
+			var err error
+			if ok, err = urlMapFullMatch(v, value); err != nil {
+				return false, err
+			}
+		}
+	} else if ranges := urlMapRoutingList(headerMatch["range_match"]); len(ranges) > 0 {
+		n, err := strconv.ParseInt(value, 10, 64)
+		ok = present && err == nil && n >= int64(ranges[0]["range_start"].(int)) && n < int64(ranges[0]["range_end"].(int))
+	} else {
+		presentMatch, _ := headerMatch["present_match"].(bool)
+		ok = present == presentMatch
+	}
+
+	if invert, _ := headerMatch["invert_match"].(bool); invert {
+		ok = !ok
+	}
+	return ok, nil
+}
+
+func urlMapQueryParameterMatches(queryMatch map[string]interface{}, query url.Values) (bool, error) {
+	values, present := query[queryMatch["name"].(string)]
+	value := ""
+	if present && len(values) > 0 {
+		value = values[0]
+	}
+
+	if v, _ := queryMatch["exact_match"].(string); v != "" {
+		return present && value == v, nil
+	}
+	if v, _ := queryMatch["regex_match"].(string); v != "" {
+		if !present {
+			return false, nil
+		}
+		return urlMapFullMatch(v, value)
+	}
+	presentMatch, _ := queryMatch["present_match"].(bool)
+	return present == presentMatch, nil
+}
+
+// urlMapRoutingList returns a list or set of nested blocks as maps.
+func urlMapRoutingList(v interface{}) []map[string]interface{} {
+	var raws []interface{}
+	switch t := v.(type) {
+	case []interface{}:
+		raws = t
+	case *schema.Set:
+		raws = t.List()
+	}
+	blocks := make([]map[string]interface{}, 0, len(raws))
+	for _, raw := range raws {
+		if block, ok := raw.(map[string]interface{}); ok {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+func urlMapRoutingStrings(v interface{}) []string {
+	switch t := v.(type) {
+	case []interface{}:
+		return tpgresource.ConvertStringArr(t)
+	case *schema.Set:
+		return tpgresource.ConvertStringSet(t)
+	}
+	return nil
+}
+
+// routeUrlMapRequest routes req through a URL map given as the attributes of
+// google_compute_url_map.
+func routeUrlMapRequest(urlMap map[string]interface{}, req urlMapRoutingRequest) (*urlMapRoutingResult, error) {
+	host := req.Host
+	if h, _, found := strings.Cut(host, ":"); found {
+		host = h
+	}
+
+	best, matcherName := 0, ""
+	for _, hostRule := range urlMapRoutingList(urlMap["host_rule"]) {
+		for _, pattern := range urlMapRoutingStrings(hostRule["hosts"]) {
+			if score := urlMapHostMatches(pattern, host); score > best {
+				best, matcherName = score, hostRule["path_matcher"].(string)
+			}
+		}
+	}
+
+	if matcherName == "" {
+		return resolveUrlMapRoutingAction(urlMapRoutingResult{MatchedRule: "url_map_default"}, urlMapRoutingActionOf(urlMap, "default_service", "default_route_action", "default_url_redirect"), req, "/")
+	}
+
+	var matcher map[string]interface{}
+	for _, pm := range urlMapRoutingList(urlMap["path_matcher"]) {
+		if pm["name"].(string) == matcherName {
+			matcher = pm
+		}
+	}
+	if matcher == nil {
+		return nil, fmt.Errorf("host rule for %q refers to path matcher %q, which doesn't exist", host, matcherName)
+	}
+	result := urlMapRoutingResult{PathMatcher: matcherName}
+
+	routeRules := urlMapRoutingList(matcher["route_rules"])
+	sort.SliceStable(routeRules, func(i, j int) bool {
+		return routeRules[i]["priority"].(int) < routeRules[j]["priority"].(int)
+	})
+	for _, routeRule := range routeRules {
+		matchRules := urlMapRoutingList(routeRule["match_rules"])
+		for _, matchRule := range matchRules {
+			matched, ok, err := urlMapMatchRuleMatches(matchRule, req)
+			if err != nil {
+				return nil, fmt.Errorf("route rule with priority %d of path matcher %q: %s", routeRule["priority"].(int), matcherName, err)
+			}
+			if ok {
+				result.MatchedRule = fmt.Sprintf("route_rule:%d", routeRule["priority"].(int))
+				return resolveUrlMapRoutingAction(result, urlMapRoutingActionOf(routeRule, "service", "route_action", "url_redirect"), req, matched)
+			}
+		}
+	}
+
+	var pathRuleAction urlMapRoutingAction
+	longest, matchedPaths := "", []string(nil)
+	for _, pathRule := range urlMapRoutingList(matcher["path_rule"]) {
+		paths := urlMapRoutingStrings(pathRule["paths"])
+		for _, p := range paths {
+			if prefix, ok := urlMapPathRuleMatch(p, req.Path); ok && (matchedPaths == nil || len(prefix) > len(longest)) {
+				longest, matchedPaths = prefix, paths
+				pathRuleAction = urlMapRoutingActionOf(pathRule, "service", "route_action", "url_redirect")
+			}
+		}
+	}
+	if matchedPaths != nil {
+		sort.Strings(matchedPaths)
+		result.MatchedRule = "path_rule:" + strings.Join(matchedPaths, ",")
+		return resolveUrlMapRoutingAction(result, pathRuleAction, req, longest)
+	}
+
+	result.MatchedRule = "path_matcher_default"
+	return resolveUrlMapRoutingAction(result, urlMapRoutingActionOf(matcher, "default_service", "default_route_action", "default_url_redirect"), req, "/")
+}
+
+func urlMapRoutingActionOf(block map[string]interface{}, serviceKey, routeActionKey, urlRedirectKey string) urlMapRoutingAction {
+	service, _ := block[serviceKey].(string)
+	routeAction, _ := block[routeActionKey].([]interface{})
+	urlRedirect, _ := block[urlRedirectKey].([]interface{})
+	return urlMapRoutingAction{Service: service, RouteAction: routeAction, UrlRedirect: urlRedirect}
+}
+
+// resolveUrlMapRoutingAction fills result with where action sends req. matched
+// is the portion of the path matched by the rule, replaced by prefix rewrites
+// and redirects.
+func resolveUrlMapRoutingAction(result urlMapRoutingResult, action urlMapRoutingAction, req urlMapRoutingRequest, matched string) (*urlMapRoutingResult, error) {
+	result.RewrittenHost, result.RewrittenPath = req.Host, req.Path
+
+	if redirects := urlMapRoutingList(action.UrlRedirect); len(redirects) > 0 {
+		redirect := redirects[0]
+		scheme, host, path := req.Scheme, req.Host, req.Path
+		if v, _ := redirect["https_redirect"].(bool); v {
+			scheme = "https"
+		}
+		if v, _ := redirect["host_redirect"].(string); v != "" {
+			host = v
+		}
+		if v, _ := redirect["path_redirect"].(string); v != "" {
+			path = v
+		} else if v, _ := redirect["prefix_redirect"].(string); v != "" {
+			path = v + strings.TrimPrefix(path, matched)
+		}
+		redirectUrl := fmt.Sprintf("%s://%s%s", scheme, host, path)
+		if strip, _ := redirect["strip_query"].(bool); !strip && len(req.Query) > 0 {
+			redirectUrl += "?" + req.Query.Encode()
+		}
+		code, _ := redirect["redirect_response_code"].(string)
+		result.RedirectUrl = redirectUrl
+		result.RedirectResponseCode = urlMapRedirectResponseCodes[code]
+		return &result, nil
+	}
+
+	result.Service = action.Service
+	if routeActions := urlMapRoutingList(action.RouteAction); len(routeActions) > 0 {
+		routeAction := routeActions[0]
+		heaviest := -1
+		for _, backend := range urlMapRoutingList(routeAction["weighted_backend_services"]) {
+			weight, _ := backend["weight"].(int)
+			result.WeightedBackends = append(result.WeightedBackends, map[string]interface{}{
+				"backend_service": backend["backend_service"],
+				"weight":          weight,
+			})
+			if weight > heaviest {
+				heaviest = weight
+				result.Service = backend["backend_service"].(string)
+			}
+		}
+		if rewrites := urlMapRoutingList(routeAction["url_rewrite"]); len(rewrites) > 0 {
+			if v, _ := rewrites[0]["host_rewrite"].(string); v != "" {
+				result.RewrittenHost = v
+			}
+			if v, _ := rewrites[0]["path_prefix_rewrite"].(string); v != "" {
+				result.RewrittenPath = v + strings.TrimPrefix(req.Path, matched)
+			}
+		}
+	}
+	return &result, nil
+}
+
+func dataSourceGoogleComputeUrlMapRoutingRead(d *schema.ResourceData, meta interface{}) error {
+	urlMap := map[string]interface{}{}
+	for _, k := range []string{"default_service", "default_url_redirect", "default_route_action", "host_rule", "path_matcher"} {
+		urlMap[k] = d.Get(k)
+	}
+
+	results := []interface{}{}
+	for i, raw := range d.Get("request").([]interface{}) {
+		request := raw.(map[string]interface{})
+		headers := map[string]string{}
+		for k, v := range request["headers"].(map[string]interface{}) {
+			headers[k] = v.(string)
+		}
+		req := newUrlMapRoutingRequest(request["scheme"].(string), request["host"].(string), request["path"].(string), headers)
+
+		result, err := routeUrlMapRequest(urlMap, req)
+		if err != nil {
+			return fmt.Errorf("Error routing request %d (%s%s): %s", i, request["host"], request["path"], err)
+		}
+
+		weighted := make([]interface{}, 0, len(result.WeightedBackends))
+		for _, backend := range result.WeightedBackends {
+			weighted = append(weighted, backend)
+		}
+		results = append(results, map[string]interface{}{
+			"host":                      request["host"],
+			"path":                      request["path"],
+			"path_matcher":              result.PathMatcher,
+			"matched_rule":              result.MatchedRule,
+			"service":                   result.Service,
+			"weighted_backend_services": weighted,
+			"rewritten_host":            result.RewrittenHost,
+			"rewritten_path":            result.RewrittenPath,
+			"redirect_url":              result.RedirectUrl,
+			"redirect_response_code":    result.RedirectResponseCode,
+		})
+	}
+
+	if err := d.Set("results", results); err != nil {
+		return fmt.Errorf("Error setting results: %s", err)
+	}
+
+	d.SetId(strconv.Itoa(tpgresource.Hashcode(fmt.Sprintf("%v", results))))
+	return nil
+}
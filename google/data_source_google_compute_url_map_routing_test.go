@@ -0,0 +1,228 @@
+package google
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/hashicorp/terraform-provider-google/google/acctest"
+)
+
+func TestDataSourceGoogleComputeUrlMapRoutingRead(t *testing.T) {
+	t.Parallel()
+
+	raw := map[string]interface{}{
+		"default_service": "default",
+		"host_rule": []interface{}{
+			map[string]interface{}{"hosts": []interface{}{"example.com"}, "path_matcher": "exact"},
+			map[string]interface{}{"hosts": []interface{}{"*.example.com"}, "path_matcher": "wildcard"},
+			map[string]interface{}{"hosts": []interface{}{"old.example.com"}, "path_matcher": "redirect"},
+		},
+		"path_matcher": []interface{}{
+			map[string]interface{}{
+				"name":            "exact",
+				"default_service": "web",
+				"path_rule": []interface{}{
+					map[string]interface{}{"paths": []interface{}{"/static/*"}, "service": "static"},
+					map[string]interface{}{"paths": []interface{}{"/static/images/*"}, "service": "images"},
+					map[string]interface{}{
+						"paths": []interface{}{"/api/*"},
+						"route_action": []interface{}{
+							map[string]interface{}{
+								"url_rewrite": []interface{}{
+									map[string]interface{}{"path_prefix_rewrite": "/v1/", "host_rewrite": "api.internal"},
+								},
+								"weighted_backend_services": []interface{}{
+									map[string]interface{}{"backend_service": "api-blue", "weight": 90},
+									map[string]interface{}{"backend_service": "api-green", "weight": 10},
+								},
+							},
+						},
+					},
+				},
+			},
+			map[string]interface{}{
+				"name":            "wildcard",
+				"default_service": "tenant",
+				"route_rules": []interface{}{
+					map[string]interface{}{
+						"priority": 20,
+						"service":  "beta",
+						"match_rules": []interface{}{
+							map[string]interface{}{
+								"prefix_match": "/",
+								"query_parameter_matches": []interface{}{
+									map[string]interface{}{"name": "beta", "present_match": true},
+								},
+							},
+						},
+					},
+					map[string]interface{}{
+						"priority": 10,
+						"service":  "canary",
+						"match_rules": []interface{}{
+							map[string]interface{}{
+								"prefix_match": "/",
+								"header_matches": []interface{}{
+									map[string]interface{}{"header_name": "X-Canary", "exact_match": "true"},
+								},
+							},
+						},
+					},
+				},
+			},
+			map[string]interface{}{
+				"name": "redirect",
+				"default_url_redirect": []interface{}{
+					map[string]interface{}{
+						"host_redirect":          "example.com",
+						"https_redirect":         true,
+						"strip_query":            false,
+						"redirect_response_code": "FOUND",
+					},
+				},
+			},
+		},
+		"request": []interface{}{
+			map[string]interface{}{"host": "unknown.org", "path": "/"},
+			map[string]interface{}{"host": "example.com:443", "path": "/"},
+			map[string]interface{}{"host": "example.com", "path": "/static/css/site.css"},
+			map[string]interface{}{"host": "example.com", "path": "/static/images/logo.png"},
+			map[string]interface{}{"host": "example.com", "path": "/api/users?page=2"},
+			map[string]interface{}{"host": "tenant.example.com", "path": "/", "headers": map[string]interface{}{"x-canary": "true"}},
+			map[string]interface{}{"host": "tenant.example.com", "path": "/?beta=1", "headers": map[string]interface{}{"x-canary": "true"}},
+			map[string]interface{}{"host": "tenant.example.com", "path": "/?beta=1"},
+			map[string]interface{}{"host": "tenant.example.com", "path": "/"},
+			map[string]interface{}{"host": "old.example.com", "path": "/a?b=c", "scheme": "http"},
+		},
+	}
+
+	d := schema.TestResourceDataRaw(t, DataSourceGoogleComputeUrlMapRouting().Schema, raw)
+	if err := dataSourceGoogleComputeUrlMapRoutingRead(d, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []map[string]interface{}{
+		{"matched_rule": "url_map_default", "service": "default"},
+		{"path_matcher": "exact", "matched_rule": "path_matcher_default", "service": "web"},
+		{"matched_rule": "path_rule:/static/*", "service": "static"},
+		{"matched_rule": "path_rule:/static/images/*", "service": "images"},
+		{"matched_rule": "path_rule:/api/*", "service": "api-blue", "rewritten_host": "api.internal", "rewritten_path": "/v1/users", "weighted_backend_services.#": 2},
+		{"path_matcher": "wildcard", "matched_rule": "route_rule:10", "service": "canary"},
+		{"matched_rule": "route_rule:10", "service": "canary"},
+		{"matched_rule": "route_rule:20", "service": "beta"},
+		{"matched_rule": "path_matcher_default", "service": "tenant"},
+		{"matched_rule": "path_matcher_default", "service": "", "redirect_url": "https://example.com/a?b=c", "redirect_response_code": 302},
+	}
+
+	results := d.Get("results").([]interface{})
+	if len(results) != len(expected) {
+		t.Fatalf("got %d results, expected %d", len(results), len(expected))
+	}
+	for i, want := range expected {
+		got := results[i].(map[string]interface{})
+		for k, v := range want {
+			var actual interface{}
+			if k == "weighted_backend_services.#" {
+				actual = len(got["weighted_backend_services"].([]interface{}))
+			} else {
+				actual = got[k]
+			}
+			if actual != v {
+				t.Errorf("request %d (%s%s): got %s %v, expected %v", i, got["host"], got["path"], k, actual, v)
+			}
+		}
+	}
+}
+
+func TestUrlMapHeaderMatches(t *testing.T) {
+	t.Parallel()
+
+	headers := map[string]string{"user-agent": "Mozilla/5.0", "x-version": "42"}
+	cases := map[string]struct {
+		Match map[string]interface{}
+		Ok    bool
+	}{
+		"prefix":          {Match: map[string]interface{}{"header_name": "User-Agent", "prefix_match": "Mozilla"}, Ok: true},
+		"suffix":          {Match: map[string]interface{}{"header_name": "user-agent", "suffix_match": "4.0"}},
+		"regex":           {Match: map[string]interface{}{"header_name": "user-agent", "regex_match": "Mozilla/[0-9.]+"}, Ok: true},
+		"partial regex":   {Match: map[string]interface{}{"header_name": "user-agent", "regex_match": "Mozilla"}},
+		"range":           {Match: map[string]interface{}{"header_name": "x-version", "range_match": []interface{}{map[string]interface{}{"range_start": 40, "range_end": 50}}}, Ok: true},
+		"range end":       {Match: map[string]interface{}{"header_name": "x-version", "range_match": []interface{}{map[string]interface{}{"range_start": 0, "range_end": 42}}}},
+		"present":         {Match: map[string]interface{}{"header_name": "x-version", "present_match": true}, Ok: true},
+		"missing":         {Match: map[string]interface{}{"header_name": "x-missing", "present_match": true}},
+		"inverted":        {Match: map[string]interface{}{"header_name": "x-version", "exact_match": "41", "invert_match": true}, Ok: true},
+		"inverted absent": {Match: map[string]interface{}{"header_name": "x-missing", "exact_match": "41", "invert_match": true}, Ok: true},
+	}
+
+	for tn, tc := range cases {
+		ok, err := urlMapHeaderMatches(tc.Match, headers)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tn, err)
+		}
+		if ok != tc.Ok {
+			t.Errorf("%s: got %t, expected %t", tn, ok, tc.Ok)
+		}
+	}
+}
+
+func TestAccDataSourceGoogleComputeUrlMapRouting_basic(t *testing.T) {
+	t.Parallel()
+
+	VcrTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.AccTestPreCheck(t) },
+		ProtoV5ProviderFactories: ProtoV5ProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceGoogleComputeUrlMapRouting_basic(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.google_compute_url_map_routing.routing", "results.0.service", "projects/my-project/global/backendServices/web"),
+					resource.TestCheckResourceAttr("data.google_compute_url_map_routing.routing", "results.1.service", "projects/my-project/global/backendBuckets/static"),
+					resource.TestCheckResourceAttr("data.google_compute_url_map_routing.routing", "results.1.rewritten_path", "/site/app.js"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDataSourceGoogleComputeUrlMapRouting_basic() string {
+	return `
+data "google_compute_url_map_routing" "routing" {
+  default_service = "projects/my-project/global/backendServices/web"
+
+  host_rule {
+    hosts        = ["example.com"]
+    path_matcher = "main"
+  }
+
+  path_matcher {
+    name            = "main"
+    default_service = "projects/my-project/global/backendServices/web"
+
+    route_rules {
+      priority = 1
+      service  = "projects/my-project/global/backendBuckets/static"
+      match_rules {
+        prefix_match = "/static/"
+      }
+      route_action {
+        url_rewrite {
+          path_prefix_rewrite = "/site/"
+        }
+      }
+    }
+  }
+
+  request {
+    host = "example.com"
+    path = "/"
+  }
+
+  request {
+    host = "example.com"
+    path = "/static/app.js"
+  }
+}
+`
+}
@@ -0,0 +1,77 @@
+package google
+
+import (
+	"encoding/base64"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-provider-google/google/tpgresource"
+	transport_tpg "github.com/hashicorp/terraform-provider-google/google/transport"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func DataSourceGoogleKmsSecretEnvelope() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceGoogleKmsSecretEnvelopeRead,
+		Schema: map[string]*schema.Schema{
+			"envelope": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"additional_authenticated_data": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"crypto_key": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"plaintext": {
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+			"plaintext_base64": {
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+		},
+	}
+}
+
+func dataSourceGoogleKmsSecretEnvelopeRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	envelope := d.Get("envelope").(string)
+	parsed, err := parseKmsEnvelope(envelope)
+	if err != nil {
+		return err
+	}
+
+	aad := []byte(d.Get("additional_authenticated_data").(string))
+	plaintext, err := openKmsEnvelope(envelope, aad, kmsEnvelopeUnwrapper(config, userAgent, aad))
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] Successfully decrypted envelope wrapped by %s", parsed.Key)
+
+	if err := d.Set("crypto_key", parsed.Key); err != nil {
+		return fmt.Errorf("Error setting crypto_key: %s", err)
+	}
+	if err := d.Set("plaintext", string(plaintext)); err != nil {
+		return fmt.Errorf("Error setting plaintext: %s", err)
+	}
+	if err := d.Set("plaintext_base64", base64.StdEncoding.EncodeToString(plaintext)); err != nil {
+		return fmt.Errorf("Error setting plaintext_base64: %s", err)
+	}
+	d.SetId(kmsEnvelopeId(parsed.Key, envelope))
+
+	return nil
+}
@@ -0,0 +1,474 @@
+package google
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/hashicorp/terraform-provider-google/google/tpgresource"
+	transport_tpg "github.com/hashicorp/terraform-provider-google/google/transport"
+)
+
+// DataSourceNetworkManagementConnectivityTestRun runs a Network Management
+// reachability analysis at read time, either by rerunning an existing
+// connectivity test or by creating a short-lived test for the given endpoints,
+// and exposes the verdict and traces so they can be asserted on.
+func DataSourceNetworkManagementConnectivityTestRun() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceNetworkManagementConnectivityTestRunRead,
+
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(10 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"connectivity_test": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ExactlyOneOf:  []string{"connectivity_test", "source"},
+				ConflictsWith: []string{"destination", "protocol", "related_projects"},
+				Description:   `The name or id of an existing connectivity test to rerun, such as one managed by google_network_management_connectivity_test.`,
+			},
+			"source": {
+				Type:         schema.TypeList,
+				Optional:     true,
+				MaxItems:     1,
+				RequiredWith: []string{"destination"},
+				Description:  `The source of the traffic to analyze.`,
+				Elem:         networkManagementConnectivityTestRunEndpointSchema(),
+			},
+			"destination": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: `The destination of the traffic to analyze.`,
+				Elem:        networkManagementConnectivityTestRunEndpointSchema(),
+			},
+			"protocol": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: `IP protocol of the test. When not provided, "TCP" is assumed.`,
+			},
+			"related_projects": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: `Other projects that may be relevant for reachability analysis.`,
+			},
+			"project": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"result": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The overall result of the analysis: REACHABLE, UNREACHABLE, AMBIGUOUS or UNDETERMINED.`,
+			},
+			"reachable": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: `Whether result is REACHABLE.`,
+			},
+			"verify_time": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"error": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The error the analysis hit, if the result is UNDETERMINED.`,
+			},
+			"blocking_firewalls": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: `URIs of firewall rules and policies that denied the traffic in any trace.`,
+			},
+			"blocking_routes": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: `URIs of routes (or the resources missing a route) responsible for a routing drop in any trace.`,
+			},
+			"drop_causes": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: `The causes of every dropped or aborted trace.`,
+			},
+			"traces": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"source_ip": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"destination_ip": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"protocol": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"source_port": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"destination_port": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"steps": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"state": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"description": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"causes_drop": {
+										Type:     schema.TypeBool,
+										Computed: true,
+									},
+									"project_id": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"resource_uri": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"firewall_action": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"cause": {
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func networkManagementConnectivityTestRunEndpointSchema() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"ip_address": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"port": {
+				Type:     schema.TypeInt,
+				Optional: true,
+			},
+			"instance": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"gke_master_cluster": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: `A cluster URI for a Google Kubernetes Engine cluster control plane.`,
+			},
+			"cloud_sql_instance": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: `A Cloud SQL instance URI.`,
+			},
+			"network": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"network_type": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"GCP_NETWORK", "NON_GCP_NETWORK", ""}, false),
+			},
+			"project_id": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+		},
+	}
+}
+
+func expandNetworkManagementConnectivityTestRunEndpoint(v interface{}) map[string]interface{} {
+	l := v.([]interface{})
+	if len(l) == 0 || l[0] == nil {
+		return nil
+	}
+	raw := l[0].(map[string]interface{})
+
+	fields := map[string]string{
+		"ip_address":         "ipAddress",
+		"instance":           "instance",
+		"gke_master_cluster": "gkeMasterCluster",
+		"cloud_sql_instance": "cloudSqlInstance",
+		"network":            "network",
+		"network_type":       "networkType",
+		"project_id":         "projectId",
+	}
+	endpoint := make(map[string]interface{})
+	for tf, api := range fields {
+		if s, ok := raw[tf].(string); ok && s != "" {
+			endpoint[api] = s
+		}
+	}
+	if port, ok := raw["port"].(int); ok && port != 0 {
+		endpoint["port"] = port
+	}
+	return endpoint
+}
+
+func dataSourceNetworkManagementConnectivityTestRunRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	project, err := tpgresource.GetProject(d, config)
+	if err != nil {
+		return err
+	}
+	if err := d.Set("project", project); err != nil {
+		return fmt.Errorf("Error setting project: %s", err)
+	}
+
+	var testName string
+	var op map[string]interface{}
+	if v, ok := d.GetOk("connectivity_test"); ok {
+		testName = v.(string)
+		if !strings.HasPrefix(testName, "projects/") {
+			testName = fmt.Sprintf("projects/%s/locations/global/connectivityTests/%s", project, testName)
+		}
+
+		log.Printf("[DEBUG] Rerunning ConnectivityTest %q", testName)
+		op, err = transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
+			Config:    config,
+			Method:    "POST",
+			Project:   project,
+			RawURL:    fmt.Sprintf("%s%s:rerun", config.NetworkManagementBasePath, testName),
+			UserAgent: userAgent,
+			Body:      map[string]interface{}{},
+			Timeout:   d.Timeout(schema.TimeoutRead),
+		})
+		if err != nil {
+			return fmt.Errorf("Error rerunning ConnectivityTest %q: %s", testName, err)
+		}
+	} else {
+		obj := map[string]interface{}{
+			"source":      expandNetworkManagementConnectivityTestRunEndpoint(d.Get("source")),
+			"destination": expandNetworkManagementConnectivityTestRunEndpoint(d.Get("destination")),
+			"description": "Created by Terraform for a google_network_management_connectivity_test_run data source.",
+		}
+		if v, ok := d.GetOk("protocol"); ok {
+			obj["protocol"] = v.(string)
+		}
+		if v, ok := d.GetOk("related_projects"); ok {
+			obj["relatedProjects"] = v.([]interface{})
+		}
+
+		testId := resource.PrefixedUniqueId("tf-run-")
+		testName = fmt.Sprintf("projects/%s/locations/global/connectivityTests/%s", project, testId)
+
+		log.Printf("[DEBUG] Creating temporary ConnectivityTest %q: %#v", testName, obj)
+		op, err = transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
+			Config:    config,
+			Method:    "POST",
+			Project:   project,
+			RawURL:    fmt.Sprintf("%sprojects/%s/locations/global/connectivityTests?testId=%s", config.NetworkManagementBasePath, project, testId),
+			UserAgent: userAgent,
+			Body:      obj,
+			Timeout:   d.Timeout(schema.TimeoutRead),
+		})
+		if err != nil {
+			return fmt.Errorf("Error creating temporary ConnectivityTest: %s", err)
+		}
+
+		defer func() {
+			log.Printf("[DEBUG] Deleting temporary ConnectivityTest %q", testName)
+			delOp, err := transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
+				Config:    config,
+				Method:    "DELETE",
+				Project:   project,
+				RawURL:    fmt.Sprintf("%s%s", config.NetworkManagementBasePath, testName),
+				UserAgent: userAgent,
+				Timeout:   d.Timeout(schema.TimeoutRead),
+			})
+			if err == nil {
+				err = NetworkManagementOperationWaitTime(config, delOp, project, "Deleting ConnectivityTest", userAgent, d.Timeout(schema.TimeoutRead))
+			}
+			if err != nil {
+				log.Printf("[WARN] Error deleting temporary ConnectivityTest %q: %s", testName, err)
+			}
+		}()
+	}
+
+	// The operation completes once reachability analysis has finished.
+	if err := NetworkManagementOperationWaitTime(config, op, project, "Running ConnectivityTest", userAgent, d.Timeout(schema.TimeoutRead)); err != nil {
+		return fmt.Errorf("Error waiting for ConnectivityTest %q to run: %s", testName, err)
+	}
+
+	res, err := transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
+		Config:    config,
+		Method:    "GET",
+		Project:   project,
+		RawURL:    fmt.Sprintf("%s%s", config.NetworkManagementBasePath, testName),
+		UserAgent: userAgent,
+	})
+	if err != nil {
+		return fmt.Errorf("Error reading ConnectivityTest %q: %s", testName, err)
+	}
+
+	details, _ := res["reachabilityDetails"].(map[string]interface{})
+	summary := flattenNetworkManagementReachabilityDetails(details)
+	for k, v := range summary {
+		if err := d.Set(k, v); err != nil {
+			return fmt.Errorf("Error setting %s: %s", k, err)
+		}
+	}
+
+	d.SetId(testName)
+	return nil
+}
+
+// flattenNetworkManagementReachabilityDetails turns a ReachabilityDetails
+// message into the data source's computed attributes, collecting the rules
+// and routes that caused traffic to be dropped along the way.
+func flattenNetworkManagementReachabilityDetails(details map[string]interface{}) map[string]interface{} {
+	result, _ := details["result"].(string)
+	verifyTime, _ := details["verifyTime"].(string)
+
+	var errMessage string
+	if e, ok := details["error"].(map[string]interface{}); ok {
+		errMessage, _ = e["message"].(string)
+	}
+
+	blockingFirewalls := []string{}
+	blockingRoutes := []string{}
+	dropCauses := []string{}
+	seen := make(map[string]bool)
+	appendUnique := func(l []string, s string) []string {
+		if s == "" || seen[s] {
+			return l
+		}
+		seen[s] = true
+		return append(l, s)
+	}
+
+	traces := make([]interface{}, 0)
+	rawTraces, _ := details["traces"].([]interface{})
+	for _, rt := range rawTraces {
+		trace, ok := rt.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		flattened := make(map[string]interface{})
+		if info, ok := trace["endpointInfo"].(map[string]interface{}); ok {
+			flattened["source_ip"] = info["sourceIp"]
+			flattened["destination_ip"] = info["destinationIp"]
+			flattened["protocol"] = info["protocol"]
+			flattened["source_port"] = flattenNetworkManagementConnectivityTestSourcePort(info["sourcePort"], nil, nil)
+			flattened["destination_port"] = flattenNetworkManagementConnectivityTestDestinationPort(info["destinationPort"], nil, nil)
+		}
+
+		var lastRoute string
+		steps := make([]interface{}, 0)
+		rawSteps, _ := trace["steps"].([]interface{})
+		for _, rs := range rawSteps {
+			step, ok := rs.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			s := map[string]interface{}{
+				"state":       step["state"],
+				"description": step["description"],
+				"causes_drop": step["causesDrop"],
+				"project_id":  step["projectId"],
+			}
+
+			if fw, ok := step["firewall"].(map[string]interface{}); ok {
+				uri, _ := fw["uri"].(string)
+				if uri == "" {
+					// Implied rules and policy rules don't have a URI of their own.
+					uri, _ = fw["policy"].(string)
+				}
+				if uri == "" {
+					uri, _ = fw["displayName"].(string)
+				}
+				action, _ := fw["action"].(string)
+				s["resource_uri"] = uri
+				s["firewall_action"] = action
+				if action == "DENY" {
+					blockingFirewalls = appendUnique(blockingFirewalls, uri)
+				}
+			}
+			if route, ok := step["route"].(map[string]interface{}); ok {
+				lastRoute, _ = route["uri"].(string)
+				if lastRoute == "" {
+					lastRoute, _ = route["displayName"].(string)
+				}
+				s["resource_uri"] = lastRoute
+			}
+			if instance, ok := step["instance"].(map[string]interface{}); ok {
+				s["resource_uri"] = instance["uri"]
+			}
+			for _, terminal := range []string{"drop", "abort"} {
+				t, ok := step[terminal].(map[string]interface{})
+				if !ok {
+					continue
+				}
+				cause, _ := t["cause"].(string)
+				uri, _ := t["resourceUri"].(string)
+				s["cause"] = cause
+				if uri != "" {
+					s["resource_uri"] = uri
+				}
+				dropCauses = append(dropCauses, cause)
+				if strings.Contains(cause, "ROUTE") {
+					if uri == "" {
+						uri = lastRoute
+					}
+					blockingRoutes = appendUnique(blockingRoutes, uri)
+				}
+			}
+
+			steps = append(steps, s)
+		}
+		flattened["steps"] = steps
+		traces = append(traces, flattened)
+	}
+
+	return map[string]interface{}{
+		"result":             result,
+		"reachable":          result == "REACHABLE",
+		"verify_time":        verifyTime,
+		"error":              errMessage,
+		"blocking_firewalls": blockingFirewalls,
+		"blocking_routes":    blockingRoutes,
+		"drop_causes":        dropCauses,
+		"traces":             traces,
+	}
+}
@@ -0,0 +1,162 @@
+package google
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-provider-google/google/acctest"
+)
+
+func TestFlattenNetworkManagementReachabilityDetails(t *testing.T) {
+	t.Parallel()
+
+	raw := `{
+  "result": "UNREACHABLE",
+  "verifyTime": "2023-06-01T12:00:00Z",
+  "traces": [
+    {
+      "endpointInfo": {"sourceIp": "10.0.0.2", "destinationIp": "10.0.1.2", "protocol": "TCP", "destinationPort": 22},
+      "steps": [
+        {"state": "START_FROM_INSTANCE", "instance": {"uri": "projects/p/zones/z/instances/a"}},
+        {"state": "APPLY_EGRESS_FIREWALL_RULE", "firewall": {"displayName": "default-allow-egress", "action": "ALLOW"}},
+        {"state": "APPLY_ROUTE", "route": {"uri": "projects/p/global/routes/r"}},
+        {"state": "APPLY_INGRESS_FIREWALL_RULE", "firewall": {"uri": "projects/p/global/firewalls/deny-ssh", "action": "DENY"}},
+        {"state": "DROP", "causesDrop": true, "drop": {"cause": "FIREWALL_RULE", "resourceUri": "projects/p/global/firewalls/deny-ssh"}}
+      ]
+    },
+    {
+      "endpointInfo": {"sourceIp": "10.0.0.2", "destinationIp": "10.9.0.2", "protocol": "TCP"},
+      "steps": [
+        {"state": "APPLY_ROUTE", "route": {"uri": "projects/p/global/routes/blackhole"}},
+        {"state": "DROP", "causesDrop": true, "drop": {"cause": "ROUTE_BLACKHOLE"}}
+      ]
+    }
+  ]
+}`
+	var details map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &details); err != nil {
+		t.Fatal(err)
+	}
+
+	got := flattenNetworkManagementReachabilityDetails(details)
+
+	if got["result"] != "UNREACHABLE" || got["reachable"] != false {
+		t.Errorf("unexpected result %v (reachable %v)", got["result"], got["reachable"])
+	}
+	if expected := []string{"projects/p/global/firewalls/deny-ssh"}; !reflect.DeepEqual(got["blocking_firewalls"], expected) {
+		t.Errorf("expected blocking_firewalls %v, got %v", expected, got["blocking_firewalls"])
+	}
+	if expected := []string{"projects/p/global/routes/blackhole"}; !reflect.DeepEqual(got["blocking_routes"], expected) {
+		t.Errorf("expected blocking_routes %v, got %v", expected, got["blocking_routes"])
+	}
+	if expected := []string{"FIREWALL_RULE", "ROUTE_BLACKHOLE"}; !reflect.DeepEqual(got["drop_causes"], expected) {
+		t.Errorf("expected drop_causes %v, got %v", expected, got["drop_causes"])
+	}
+
+	traces := got["traces"].([]interface{})
+	if len(traces) != 2 {
+		t.Fatalf("expected 2 traces, got %d", len(traces))
+	}
+	first := traces[0].(map[string]interface{})
+	if first["destination_port"] != 22 {
+		t.Errorf("expected destination_port 22, got %#v", first["destination_port"])
+	}
+	if steps := first["steps"].([]interface{}); len(steps) != 5 {
+		t.Errorf("expected 5 steps, got %d", len(steps))
+	}
+}
+
+func TestAccDataSourceNetworkManagementConnectivityTestRun_instanceToIp(t *testing.T) {
+	t.Parallel()
+
+	context := map[string]interface{}{
+		"random_suffix": RandString(t, 10),
+	}
+
+	VcrTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.AccTestPreCheck(t) },
+		ProtoV5ProviderFactories: ProtoV5ProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceNetworkManagementConnectivityTestRun_instanceToIp(context),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.google_network_management_connectivity_test_run.ssh", "result", "UNREACHABLE"),
+					resource.TestCheckResourceAttr("data.google_network_management_connectivity_test_run.ssh", "reachable", "false"),
+					resource.TestCheckResourceAttrPair(
+						"data.google_network_management_connectivity_test_run.ssh", "blocking_firewalls.0",
+						"google_compute_firewall.deny_ssh", "self_link"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDataSourceNetworkManagementConnectivityTestRun_instanceToIp(context map[string]interface{}) string {
+	return Nprintf(`
+resource "google_compute_network" "vpc" {
+  name                    = "tf-test-conn-run-%{random_suffix}"
+  auto_create_subnetworks = false
+}
+
+resource "google_compute_subnetwork" "subnet" {
+  name          = "tf-test-conn-run-%{random_suffix}"
+  ip_cidr_range = "10.0.0.0/24"
+  region        = "us-central1"
+  network       = google_compute_network.vpc.id
+}
+
+resource "google_compute_firewall" "deny_ssh" {
+  name      = "tf-test-deny-ssh-%{random_suffix}"
+  network   = google_compute_network.vpc.id
+  direction = "EGRESS"
+  priority  = 100
+
+  deny {
+    protocol = "tcp"
+    ports    = ["22"]
+  }
+
+  destination_ranges = ["10.0.0.0/24"]
+}
+
+data "google_compute_image" "debian" {
+  family  = "debian-11"
+  project = "debian-cloud"
+}
+
+resource "google_compute_instance" "source" {
+  name         = "tf-test-conn-run-%{random_suffix}"
+  machine_type = "e2-medium"
+  zone         = "us-central1-a"
+
+  boot_disk {
+    initialize_params {
+      image = data.google_compute_image.debian.self_link
+    }
+  }
+
+  network_interface {
+    subnetwork = google_compute_subnetwork.subnet.id
+    network_ip = "10.0.0.2"
+  }
+}
+
+data "google_network_management_connectivity_test_run" "ssh" {
+  source {
+    instance = google_compute_instance.source.id
+  }
+
+  destination {
+    ip_address = "10.0.0.10"
+    port       = 22
+    network    = google_compute_network.vpc.id
+  }
+
+  protocol = "TCP"
+
+  depends_on = [google_compute_firewall.deny_ssh]
+}
+`, context)
+}
@@ -0,0 +1,97 @@
+package google
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-provider-google/google/tpgresource"
+	transport_tpg "github.com/hashicorp/terraform-provider-google/google/transport"
+)
+
+func DataSourceGooglePubsubSchemaRevisions() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceGooglePubsubSchemaRevisionsRead,
+
+		Schema: map[string]*schema.Schema{
+			"schema": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: `The name of the schema, or its full resource name.`,
+			},
+			"project": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"revisions": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: `The revisions of the schema, newest first.`,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"revision_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"revision_create_time": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"definition": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceGooglePubsubSchemaRevisionsRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	project, err := tpgresource.GetProject(d, config)
+	if err != nil {
+		return err
+	}
+
+	name := d.Get("schema").(string)
+	if !pubsubSchemaNameRegex.MatchString(name) {
+		name = fmt.Sprintf("projects/%s/schemas/%s", project, name)
+	}
+
+	revisions, err := listPubsubSchemaRevisions(config, name, project, userAgent)
+	if err != nil {
+		return err
+	}
+
+	result := make([]map[string]interface{}, 0, len(revisions))
+	for _, raw := range revisions {
+		revision := raw.(map[string]interface{})
+		result = append(result, map[string]interface{}{
+			"revision_id":          revision["revisionId"],
+			"revision_create_time": revision["revisionCreateTime"],
+			"type":                 revision["type"],
+			"definition":           revision["definition"],
+		})
+	}
+
+	if err := d.Set("project", project); err != nil {
+		return fmt.Errorf("Error setting project: %s", err)
+	}
+	if err := d.Set("revisions", result); err != nil {
+		return fmt.Errorf("Error setting revisions: %s", err)
+	}
+
+	d.SetId(name)
+	return nil
+}
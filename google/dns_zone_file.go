@@ -0,0 +1,471 @@
+package google
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/api/dns/v1"
+)
+
+// dnsZoneFileLine is a logical line of a BIND zone file, with parentheses
+// joined and comments removed.
+type dnsZoneFileLine struct {
+	tokens []string
+	// Lines starting with whitespace reuse the previous owner name.
+	blankOwner bool
+	number     int
+}
+
+// tokenizeDnsZoneFile splits a zone file into logical lines. Quoted strings
+// are kept as single tokens, including their quotes.
+func tokenizeDnsZoneFile(content string) ([]dnsZoneFileLine, error) {
+	var lines []dnsZoneFileLine
+	var current *dnsZoneFileLine
+	var token strings.Builder
+	inToken, inQuotes, inComment := false, false, false
+	depth, lineNumber := 0, 1
+
+	flushToken := func() {
+		if inToken {
+			current.tokens = append(current.tokens, token.String())
+			token.Reset()
+			inToken = false
+		}
+	}
+	startLine := func(blankOwner bool) {
+		current = &dnsZoneFileLine{blankOwner: blankOwner, number: lineNumber}
+	}
+	endLine := func() {
+		flushToken()
+		if current != nil && len(current.tokens) > 0 {
+			lines = append(lines, *current)
+		}
+		current = nil
+	}
+
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		if current == nil {
+			startLine(c == ' ' || c == '\t')
+		}
+
+		if inComment {
+			if c != '\n' {
+				continue
+			}
+			inComment = false
+		}
+
+		if inQuotes {
+			token.WriteByte(c)
+			if c == '\\' && i+1 < len(content) {
+				i++
+				token.WriteByte(content[i])
+			} else if c == '"' {
+				inQuotes = false
+			} else if c == '\n' {
+				return nil, fmt.Errorf("line %d: unterminated quoted string", current.number)
+			}
+			continue
+		}
+
+		switch c {
+		case '\n':
+			if depth == 0 {
+				endLine()
+			} else {
+				flushToken()
+			}
+			lineNumber++
+		case ' ', '\t', '\r':
+			flushToken()
+		case ';':
+			flushToken()
+			inComment = true
+		case '(':
+			flushToken()
+			depth++
+		case ')':
+			flushToken()
+			if depth == 0 {
+				return nil, fmt.Errorf("line %d: unbalanced parenthesis", lineNumber)
+			}
+			depth--
+		case '"':
+			flushToken()
+			inToken = true
+			inQuotes = true
+			token.WriteByte(c)
+		case '\\':
+			inToken = true
+			token.WriteByte(c)
+			if i+1 < len(content) {
+				i++
+				token.WriteByte(content[i])
+			}
+		default:
+			inToken = true
+			token.WriteByte(c)
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("line %d: unterminated quoted string", current.number)
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced parenthesis at end of zone file")
+	}
+	if current != nil {
+		endLine()
+	}
+	return lines, nil
+}
+
+// parseDnsTtl parses a TTL in seconds or with BIND's unit suffixes, e.g. 1h30m.
+func parseDnsTtl(s string) (int64, bool) {
+	if s == "" {
+		return 0, false
+	}
+	if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return v, v >= 0
+	}
+
+	var total, value int64
+	digits := false
+	for _, c := range strings.ToLower(s) {
+		if c >= '0' && c <= '9' {
+			value = value*10 + int64(c-'0')
+			digits = true
+			continue
+		}
+		if !digits {
+			return 0, false
+		}
+		switch c {
+		case 's':
+		case 'm':
+			value *= 60
+		case 'h':
+			value *= 60 * 60
+		case 'd':
+			value *= 24 * 60 * 60
+		case 'w':
+			value *= 7 * 24 * 60 * 60
+		default:
+			return 0, false
+		}
+		total += value
+		value, digits = 0, false
+	}
+	if digits {
+		return 0, false
+	}
+	return total, true
+}
+
+// qualifyDnsName makes name absolute relative to origin. "@" is the origin.
+func qualifyDnsName(name, origin string) string {
+	if name == "@" {
+		return origin
+	}
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	if origin == "." {
+		return name + "."
+	}
+	return name + "." + origin
+}
+
+// dnsRdataNameFields lists the fields of each record type's rdata holding a
+// domain name, which are qualified like owner names.
+var dnsRdataNameFields = map[string][]int{
+	"CNAME": {0},
+	"DNAME": {0},
+	"NS":    {0},
+	"PTR":   {0},
+	"MX":    {1},
+	"SRV":   {3},
+	"SOA":   {0, 1},
+}
+
+// normalizeDnsRdata formats rdata tokens the way Cloud DNS returns them.
+func normalizeDnsRdata(rType string, fields []string, origin string) string {
+	switch rType {
+	case "TXT", "SPF":
+		quoted := make([]string, 0, len(fields))
+		for _, f := range fields {
+			if !strings.HasPrefix(f, `"`) {
+				f = strconv.Quote(f)
+			}
+			quoted = append(quoted, f)
+		}
+		return strings.Join(quoted, " ")
+	}
+
+	for _, i := range dnsRdataNameFields[rType] {
+		if i < len(fields) {
+			fields[i] = qualifyDnsName(fields[i], origin)
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// canonicalDnsRdata returns rrdata in a form that only serves to compare it,
+// so that the different spellings of a record compare equal: Cloud DNS may
+// return long TXT strings split into 255 byte chunks, and IPv6 addresses and
+// domain names spelled differently than they were sent.
+func canonicalDnsRdata(rType, rrdata string) string {
+	switch rType {
+	case "TXT", "SPF":
+		return strings.Join(dnsCharacterStrings(rrdata), "")
+	case "A", "AAAA":
+		if ip := net.ParseIP(strings.TrimSpace(rrdata)); ip != nil {
+			return ip.String()
+		}
+	}
+
+	fields := strings.Fields(rrdata)
+	for _, i := range dnsRdataNameFields[rType] {
+		if i < len(fields) {
+			fields[i] = strings.ToLower(fields[i])
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// dnsCharacterStrings returns the unescaped character-strings of TXT rdata,
+// which may each be quoted or not.
+func dnsCharacterStrings(rrdata string) []string {
+	var strs []string
+	var current strings.Builder
+	inString, quoted := false, false
+	flush := func() {
+		if inString {
+			strs = append(strs, current.String())
+			current.Reset()
+		}
+		inString, quoted = false, false
+	}
+
+	for i := 0; i < len(rrdata); i++ {
+		c := rrdata[i]
+		switch {
+		case c == '\\' && i+1 < len(rrdata):
+			inString = true
+			if i+3 < len(rrdata) {
+				if v, err := strconv.ParseUint(rrdata[i+1:i+4], 10, 8); err == nil {
+					current.WriteByte(byte(v))
+					i += 3
+					continue
+				}
+			}
+			i++
+			current.WriteByte(rrdata[i])
+		case c == '"':
+			if quoted {
+				// An empty quoted string is still a character-string.
+				inString = true
+				flush()
+			} else {
+				flush()
+				inString, quoted = true, true
+			}
+		case (c == ' ' || c == '\t') && !quoted:
+			flush()
+		default:
+			inString = true
+			current.WriteByte(c)
+		}
+	}
+	flush()
+	return strs
+}
+
+var dnsClasses = map[string]bool{"IN": true, "CH": true, "HS": true, "CS": true}
+
+// parseDnsZoneFile parses a BIND zone file into record sets. origin is used
+// until a $ORIGIN directive changes it, and defaultTtl for records without a
+// TTL when there is no $TTL directive. $INCLUDE and $GENERATE aren't supported.
+func parseDnsZoneFile(content, origin string, defaultTtl int64) ([]*dns.ResourceRecordSet, error) {
+	lines, err := tokenizeDnsZoneFile(content)
+	if err != nil {
+		return nil, err
+	}
+
+	origin = strings.ToLower(qualifyDnsName(origin, "."))
+	ttl := defaultTtl
+	owner := ""
+	builder := newDnsRecordSetBuilder()
+
+	for _, line := range lines {
+		tokens := line.tokens
+		if strings.HasPrefix(tokens[0], "$") && !line.blankOwner {
+			switch strings.ToUpper(tokens[0]) {
+			case "$ORIGIN":
+				if len(tokens) != 2 {
+					return nil, fmt.Errorf("line %d: $ORIGIN takes a single domain name", line.number)
+				}
+				origin = strings.ToLower(qualifyDnsName(tokens[1], origin))
+			case "$TTL":
+				v, ok := parseDnsTtl(safeDnsToken(tokens, 1))
+				if len(tokens) != 2 || !ok {
+					return nil, fmt.Errorf("line %d: $TTL takes a single TTL", line.number)
+				}
+				ttl = v
+			default:
+				return nil, fmt.Errorf("line %d: unsupported directive %s", line.number, tokens[0])
+			}
+			continue
+		}
+
+		if !line.blankOwner {
+			owner = strings.ToLower(qualifyDnsName(tokens[0], origin))
+			tokens = tokens[1:]
+		} else if owner == "" {
+			return nil, fmt.Errorf("line %d: record without an owner name", line.number)
+		}
+
+		recordTtl := ttl
+		for i := 0; i < 2 && len(tokens) > 0; i++ {
+			if v, ok := parseDnsTtl(tokens[0]); ok {
+				recordTtl = v
+				tokens = tokens[1:]
+			} else if class := strings.ToUpper(tokens[0]); dnsClasses[class] {
+				if class != "IN" {
+					return nil, fmt.Errorf("line %d: unsupported class %s", line.number, class)
+				}
+				tokens = tokens[1:]
+			}
+		}
+		if len(tokens) < 2 {
+			return nil, fmt.Errorf("line %d: expected a record type and data", line.number)
+		}
+
+		rType := strings.ToUpper(tokens[0])
+		builder.add(owner, rType, recordTtl, normalizeDnsRdata(rType, tokens[1:], origin))
+	}
+	return builder.recordSets(), nil
+}
+
+func safeDnsToken(tokens []string, i int) string {
+	if i < len(tokens) {
+		return tokens[i]
+	}
+	return ""
+}
+
+// dnsRecordSetBuilder groups records into record sets keyed by name and type,
+// keeping the first TTL seen for a set and dropping duplicate rdata.
+type dnsRecordSetBuilder struct {
+	sets map[string]*dns.ResourceRecordSet
+	seen map[string]bool
+}
+
+func newDnsRecordSetBuilder() *dnsRecordSetBuilder {
+	return &dnsRecordSetBuilder{
+		sets: make(map[string]*dns.ResourceRecordSet),
+		seen: make(map[string]bool),
+	}
+}
+
+func (b *dnsRecordSetBuilder) add(name, rType string, ttl int64, rrdatas ...string) {
+	key := dnsRecordSetKey(name, rType)
+	set, ok := b.sets[key]
+	if !ok {
+		set = &dns.ResourceRecordSet{Name: name, Type: rType, Ttl: ttl}
+		b.sets[key] = set
+	}
+	for _, rrdata := range rrdatas {
+		if b.seen[key+" "+rrdata] {
+			continue
+		}
+		b.seen[key+" "+rrdata] = true
+		set.Rrdatas = append(set.Rrdatas, rrdata)
+	}
+}
+
+func (b *dnsRecordSetBuilder) recordSets() []*dns.ResourceRecordSet {
+	sets := make([]*dns.ResourceRecordSet, 0, len(b.sets))
+	for _, set := range b.sets {
+		sort.Strings(set.Rrdatas)
+		sets = append(sets, set)
+	}
+	sortDnsRecordSets(sets)
+	return sets
+}
+
+func dnsRecordSetKey(name, rType string) string {
+	return strings.ToLower(name) + " " + strings.ToUpper(rType)
+}
+
+func sortDnsRecordSets(sets []*dns.ResourceRecordSet) {
+	sort.Slice(sets, func(i, j int) bool {
+		return dnsRecordSetKey(sets[i].Name, sets[i].Type) < dnsRecordSetKey(sets[j].Name, sets[j].Type)
+	})
+}
+
+// isDnsApexSoaOrNs reports whether set is the SOA or NS record set at the apex
+// of the zone, which Cloud DNS creates with the zone and never deletes.
+func isDnsApexSoaOrNs(set *dns.ResourceRecordSet, dnsName string) bool {
+	return strings.EqualFold(set.Name, dnsName) && (set.Type == "SOA" || set.Type == "NS")
+}
+
+// dnsRecordSetsEqual reports whether two record sets of the same name and type
+// hold the same records, comparing their rdata in canonical form.
+func dnsRecordSetsEqual(a, b *dns.ResourceRecordSet) bool {
+	if a.Ttl != b.Ttl || a.RoutingPolicy != nil || b.RoutingPolicy != nil || len(a.Rrdatas) != len(b.Rrdatas) {
+		return false
+	}
+	ar := make([]string, 0, len(a.Rrdatas))
+	br := make([]string, 0, len(b.Rrdatas))
+	for i := range a.Rrdatas {
+		ar = append(ar, canonicalDnsRdata(a.Type, a.Rrdatas[i]))
+		br = append(br, canonicalDnsRdata(b.Type, b.Rrdatas[i]))
+	}
+	sort.Strings(ar)
+	sort.Strings(br)
+	for i := range ar {
+		if ar[i] != br[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// dnsZoneRecordsChange computes a single change that makes the live record
+// sets of a zone match desired. Changed sets are deleted and re-added. Live
+// sets missing from desired are deleted, except the apex SOA and NS sets and
+// sets with a routing policy, which are left alone.
+func dnsZoneRecordsChange(live, desired []*dns.ResourceRecordSet, dnsName string) *dns.Change {
+	liveByKey := make(map[string]*dns.ResourceRecordSet, len(live))
+	for _, set := range live {
+		liveByKey[dnsRecordSetKey(set.Name, set.Type)] = set
+	}
+
+	chg := &dns.Change{}
+	desiredKeys := make(map[string]bool, len(desired))
+	for _, set := range desired {
+		key := dnsRecordSetKey(set.Name, set.Type)
+		desiredKeys[key] = true
+		existing, ok := liveByKey[key]
+		if ok && dnsRecordSetsEqual(existing, set) {
+			continue
+		}
+		if ok {
+			chg.Deletions = append(chg.Deletions, existing)
+		}
+		chg.Additions = append(chg.Additions, set)
+	}
+	for _, set := range live {
+		if desiredKeys[dnsRecordSetKey(set.Name, set.Type)] || isDnsApexSoaOrNs(set, dnsName) || set.RoutingPolicy != nil {
+			continue
+		}
+		chg.Deletions = append(chg.Deletions, set)
+	}
+	sortDnsRecordSets(chg.Additions)
+	sortDnsRecordSets(chg.Deletions)
+	return chg
+}
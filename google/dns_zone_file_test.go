@@ -0,0 +1,202 @@
+package google
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/dns/v1"
+)
+
+func TestParseDnsTtl(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		Ttl int64
+		Ok  bool
+	}{
+		"300":   {Ttl: 300, Ok: true},
+		"1h":    {Ttl: 3600, Ok: true},
+		"1h30m": {Ttl: 5400, Ok: true},
+		"1W2D":  {Ttl: 777600, Ok: true},
+		"1h30":  {},
+		"IN":    {},
+		"":      {},
+	}
+	for input, tc := range cases {
+		ttl, ok := parseDnsTtl(input)
+		if ok != tc.Ok || ttl != tc.Ttl {
+			t.Errorf("parseDnsTtl(%q) = %d, %t, expected %d, %t", input, ttl, ok, tc.Ttl, tc.Ok)
+		}
+	}
+}
+
+func TestParseDnsZoneFile(t *testing.T) {
+	t.Parallel()
+
+	zoneFile := `
+$TTL 1h
+; the zone's own records
+@   IN  SOA ns1 hostmaster (
+        2023010101 ; serial
+        7200 3600 1209600 300 )
+    IN  NS  ns1
+        NS  ns2.example.net.
+@       MX  10 mail
+        MX  20 mail.example.net.
+www 300 IN A 192.0.2.1
+www     A   192.0.2.2
+www     A   192.0.2.1
+txt     TXT "v=spf1 include:_spf.example.com ~all"
+split   TXT "first" "second"
+bare    TXT unquoted
+_sip._tcp SRV 10 60 5060 sip
+$ORIGIN sub.example.com.
+api     CNAME www.example.com.
+alias   CNAME api
+`
+
+	sets, err := parseDnsZoneFile(zoneFile, "example.com.", 300)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []*dns.ResourceRecordSet{
+		{Name: "_sip._tcp.example.com.", Type: "SRV", Ttl: 3600, Rrdatas: []string{"10 60 5060 sip.example.com."}},
+		{Name: "alias.sub.example.com.", Type: "CNAME", Ttl: 3600, Rrdatas: []string{"api.sub.example.com."}},
+		{Name: "api.sub.example.com.", Type: "CNAME", Ttl: 3600, Rrdatas: []string{"www.example.com."}},
+		{Name: "bare.example.com.", Type: "TXT", Ttl: 3600, Rrdatas: []string{`"unquoted"`}},
+		{Name: "example.com.", Type: "MX", Ttl: 3600, Rrdatas: []string{"10 mail.example.com.", "20 mail.example.net."}},
+		{Name: "example.com.", Type: "NS", Ttl: 3600, Rrdatas: []string{"ns1.example.com.", "ns2.example.net."}},
+		{Name: "example.com.", Type: "SOA", Ttl: 3600, Rrdatas: []string{"ns1.example.com. hostmaster.example.com. 2023010101 7200 3600 1209600 300"}},
+		{Name: "split.example.com.", Type: "TXT", Ttl: 3600, Rrdatas: []string{`"first" "second"`}},
+		{Name: "txt.example.com.", Type: "TXT", Ttl: 3600, Rrdatas: []string{`"v=spf1 include:_spf.example.com ~all"`}},
+		{Name: "www.example.com.", Type: "A", Ttl: 300, Rrdatas: []string{"192.0.2.1", "192.0.2.2"}},
+	}
+	if !reflect.DeepEqual(sets, expected) {
+		for _, set := range sets {
+			t.Logf("got %#v", set)
+		}
+		t.Errorf("parsed record sets don't match")
+	}
+}
+
+func TestParseDnsZoneFile_errors(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"unbalanced parenthesis": "@ SOA ns1 hostmaster ( 1 2 3 4 5\n",
+		"unterminated quote":     "txt TXT \"abc\n",
+		"include":                "$INCLUDE other.zone\n",
+		"missing owner":          "   A 192.0.2.1\n",
+		"missing data":           "www A\n",
+		"unsupported class":      "www CH A 192.0.2.1\n",
+	}
+	for tn, zoneFile := range cases {
+		if _, err := parseDnsZoneFile(zoneFile, "example.com.", 300); err == nil {
+			t.Errorf("%s: expected an error", tn)
+		}
+	}
+}
+
+func TestDnsZoneRecordsChange(t *testing.T) {
+	t.Parallel()
+
+	live := []*dns.ResourceRecordSet{
+		{Name: "example.com.", Type: "SOA", Ttl: 21600, Rrdatas: []string{"ns-cloud-a1.googledomains.com. cloud-dns-hostmaster.google.com. 1 21600 3600 259200 300"}},
+		{Name: "example.com.", Type: "NS", Ttl: 21600, Rrdatas: []string{"ns-cloud-a1.googledomains.com."}},
+		{Name: "same.example.com.", Type: "A", Ttl: 300, Rrdatas: []string{"192.0.2.2", "192.0.2.1"}},
+		{Name: "changed.example.com.", Type: "A", Ttl: 300, Rrdatas: []string{"192.0.2.1"}},
+		{Name: "removed.example.com.", Type: "A", Ttl: 300, Rrdatas: []string{"192.0.2.1"}},
+		{Name: "geo.example.com.", Type: "A", Ttl: 300, RoutingPolicy: &dns.RRSetRoutingPolicy{}},
+	}
+	desired := []*dns.ResourceRecordSet{
+		{Name: "same.example.com.", Type: "A", Ttl: 300, Rrdatas: []string{"192.0.2.1", "192.0.2.2"}},
+		{Name: "changed.example.com.", Type: "A", Ttl: 600, Rrdatas: []string{"192.0.2.1"}},
+		{Name: "added.example.com.", Type: "CNAME", Ttl: 300, Rrdatas: []string{"same.example.com."}},
+	}
+
+	chg := dnsZoneRecordsChange(live, desired, "example.com.")
+
+	var additions, deletions []string
+	for _, set := range chg.Additions {
+		additions = append(additions, dnsRecordSetKey(set.Name, set.Type))
+	}
+	for _, set := range chg.Deletions {
+		deletions = append(deletions, dnsRecordSetKey(set.Name, set.Type))
+	}
+	if expected := []string{"added.example.com. CNAME", "changed.example.com. A"}; !reflect.DeepEqual(additions, expected) {
+		t.Errorf("got additions %v, expected %v", additions, expected)
+	}
+	if expected := []string{"changed.example.com. A", "removed.example.com. A"}; !reflect.DeepEqual(deletions, expected) {
+		t.Errorf("got deletions %v, expected %v", deletions, expected)
+	}
+
+	if chg := dnsZoneRecordsChange(live[:3], desired[:1], "example.com."); len(chg.Additions) != 0 || len(chg.Deletions) != 0 {
+		t.Errorf("expected no change for matching records, got %d additions and %d deletions", len(chg.Additions), len(chg.Deletions))
+	}
+}
+
+func TestDnsRecordSetsEqual(t *testing.T) {
+	t.Parallel()
+
+	long := strings.Repeat("a", 255) + strings.Repeat("b", 45)
+	cases := map[string]struct {
+		Type     string
+		Sent     []string
+		Returned []string
+		Equal    bool
+	}{
+		"txt split into chunks": {
+			Type:     "TXT",
+			Sent:     []string{normalizeDnsRdata("TXT", []string{`"` + long + `"`}, "example.com.")},
+			Returned: []string{`"` + strings.Repeat("a", 255) + `" "` + strings.Repeat("b", 45) + `"`},
+			Equal:    true,
+		},
+		"txt unquoted": {
+			Type:     "TXT",
+			Sent:     []string{`"v=spf1" "-all"`},
+			Returned: []string{`v=spf1 -all`},
+			Equal:    true,
+		},
+		"txt escapes": {
+			Type:     "TXT",
+			Sent:     []string{`"say \"hi\""`},
+			Returned: []string{`"say \034hi\034"`},
+			Equal:    true,
+		},
+		"txt changed": {
+			Type:     "TXT",
+			Sent:     []string{`"v=spf1 -all"`},
+			Returned: []string{`"v=spf1 ~all"`},
+		},
+		"aaaa spelled differently": {
+			Type:     "AAAA",
+			Sent:     []string{"2001:DB8:0:0:0:0:0:1", "2001:db8::2"},
+			Returned: []string{"2001:db8::2", "2001:db8::1"},
+			Equal:    true,
+		},
+		"aaaa changed": {
+			Type:     "AAAA",
+			Sent:     []string{"2001:db8::1"},
+			Returned: []string{"2001:db8::3"},
+		},
+		"name in another case": {
+			Type:     "MX",
+			Sent:     []string{"10 Mail.Example.com."},
+			Returned: []string{"10 mail.example.com."},
+			Equal:    true,
+		},
+	}
+
+	for tn, tc := range cases {
+		sent := &dns.ResourceRecordSet{Name: "example.com.", Type: tc.Type, Ttl: 300, Rrdatas: tc.Sent}
+		returned := &dns.ResourceRecordSet{Name: "example.com.", Type: tc.Type, Ttl: 300, Rrdatas: tc.Returned}
+		if got := dnsRecordSetsEqual(sent, returned); got != tc.Equal {
+			t.Errorf("%s: got %v, expected %v", tn, got, tc.Equal)
+		}
+		if got := dnsManagedZoneRecordSetsEqual([]*dns.ResourceRecordSet{returned}, []*dns.ResourceRecordSet{sent}); got != tc.Equal {
+			t.Errorf("%s: got %v comparing state, expected %v", tn, got, tc.Equal)
+		}
+	}
+}
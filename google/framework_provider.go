@@ -215,6 +215,18 @@ func (p *frameworkProvider) Schema(_ context.Context, _ provider.SchemaRequest,
 			"request_reason": schema.StringAttribute{
 				Optional: true,
 			},
+			"secret_state_mode": schema.StringAttribute{
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("plaintext", "hash"),
+				},
+			},
+			"bigquery_dry_run": schema.BoolAttribute{
+				Optional: true,
+			},
+			"bigquery_dry_run_max_bytes_billed": schema.Int64Attribute{
+				Optional: true,
+			},
 
 			// Generated Products
 			"access_approval_custom_endpoint": &schema.StringAttribute{
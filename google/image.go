@@ -9,6 +9,7 @@ import (
 	transport_tpg "github.com/hashicorp/terraform-provider-google/google/transport"
 	"github.com/hashicorp/terraform-provider-google/google/verify"
 
+	"google.golang.org/api/compute/v1"
 	"google.golang.org/api/googleapi"
 )
 
@@ -245,3 +246,37 @@ func resolveImageRefToRelativeURI(providerProject, name string) (string, error)
 	return "", fmt.Errorf("Could not expand image or family %q into a relative URI", name)
 
 }
+
+// resolveImageToImage resolves name the same way resolveImage does, but
+// dereferences image families so the result is always a concrete image. This
+// is used where the exact image an alias pointed at needs to be recorded, so
+// that later publications to a family don't silently change it.
+func resolveImageToImage(c *transport_tpg.Config, project, name, userAgent string) (*compute.Image, error) {
+	ref, err := resolveImage(c, project, name, userAgent)
+	if err != nil {
+		return nil, err
+	}
+
+	relative, err := resolveImageRefToRelativeURI(project, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	if res := resolveImageProjectFamily.FindStringSubmatch(relative); res != nil {
+		image, err := c.NewComputeClient(userAgent).Images.GetFromFamily(res[1], res[2]).Do()
+		if err != nil {
+			return nil, fmt.Errorf("Error resolving latest image in family %s: %s", relative, err)
+		}
+		return image, nil
+	}
+
+	if res := resolveImageProjectImage.FindStringSubmatch(relative); res != nil {
+		image, err := c.NewComputeClient(userAgent).Images.Get(res[1], res[2]).Do()
+		if err != nil {
+			return nil, fmt.Errorf("Error reading image %s: %s", relative, err)
+		}
+		return image, nil
+	}
+
+	return nil, fmt.Errorf("Could not resolve %q to a concrete image", name)
+}
@@ -0,0 +1,305 @@
+package google
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"hash"
+	"strings"
+
+	"google.golang.org/api/cloudkms/v1"
+
+	transport_tpg "github.com/hashicorp/terraform-provider-google/google/transport"
+)
+
+const (
+	kmsEnvelopeVersion   = 1
+	kmsEnvelopeAlgorithm = "AES_256_GCM"
+
+	kmsSymmetricAlgorithm = "GOOGLE_SYMMETRIC_ENCRYPTION"
+)
+
+// kmsEnvelope is the envelope written by google_kms_secret_envelope and read
+// by data.google_kms_secret_envelope. The data is encrypted locally with a
+// random data encryption key, which is itself encrypted ("wrapped") by a
+// Cloud KMS key. The envelope is serialized as base64-encoded JSON.
+type kmsEnvelope struct {
+	Version int `json:"version"`
+	// Key is the KMS CryptoKey, or with an asymmetric key the
+	// CryptoKeyVersion, that wrapped the data encryption key.
+	Key          string `json:"key"`
+	KeyAlgorithm string `json:"keyAlgorithm"`
+	WrappedKey   string `json:"wrappedKey"`
+	Algorithm    string `json:"algorithm"`
+	Nonce        string `json:"nonce"`
+	Ciphertext   string `json:"ciphertext"`
+}
+
+// kmsEnvelopeWrapFunc encrypts a data encryption key, and returns the
+// envelope's key, keyAlgorithm and wrappedKey.
+type kmsEnvelopeWrapFunc func(dek []byte) (key, keyAlgorithm, wrappedKey string, err error)
+
+// kmsEnvelopeUnwrapFunc decrypts the data encryption key of an envelope.
+type kmsEnvelopeUnwrapFunc func(envelope *kmsEnvelope) ([]byte, error)
+
+func sealKmsEnvelope(plaintext, aad []byte, wrap kmsEnvelopeWrapFunc) (string, error) {
+	dek := make([]byte, 32)
+	if _, err := rand.Read(dek); err != nil {
+		return "", fmt.Errorf("Error generating data encryption key: %s", err)
+	}
+	defer zeroBytes(dek)
+
+	gcm, err := newKmsEnvelopeGCM(dek)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("Error generating nonce: %s", err)
+	}
+
+	key, keyAlgorithm, wrappedKey, err := wrap(dek)
+	if err != nil {
+		return "", err
+	}
+
+	envelope := kmsEnvelope{
+		Version:      kmsEnvelopeVersion,
+		Key:          key,
+		KeyAlgorithm: keyAlgorithm,
+		WrappedKey:   wrappedKey,
+		Algorithm:    kmsEnvelopeAlgorithm,
+		Nonce:        base64.StdEncoding.EncodeToString(nonce),
+		Ciphertext:   base64.StdEncoding.EncodeToString(gcm.Seal(nil, nonce, plaintext, aad)),
+	}
+	b, err := json.Marshal(envelope)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+func parseKmsEnvelope(encoded string) (*kmsEnvelope, error) {
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("Error decoding envelope: %s", err)
+	}
+	var envelope kmsEnvelope
+	if err := json.Unmarshal(b, &envelope); err != nil {
+		return nil, fmt.Errorf("Error parsing envelope: %s", err)
+	}
+	if envelope.Version != kmsEnvelopeVersion {
+		return nil, fmt.Errorf("Unsupported envelope version %d", envelope.Version)
+	}
+	if envelope.Algorithm != kmsEnvelopeAlgorithm {
+		return nil, fmt.Errorf("Unsupported envelope algorithm %q", envelope.Algorithm)
+	}
+	return &envelope, nil
+}
+
+func openKmsEnvelope(encoded string, aad []byte, unwrap kmsEnvelopeUnwrapFunc) ([]byte, error) {
+	envelope, err := parseKmsEnvelope(encoded)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := base64.StdEncoding.DecodeString(envelope.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("Error decoding envelope nonce: %s", err)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(envelope.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("Error decoding envelope ciphertext: %s", err)
+	}
+
+	dek, err := unwrap(envelope)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(dek)
+
+	gcm, err := newKmsEnvelopeGCM(dek)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("Invalid envelope nonce length %d", len(nonce))
+	}
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, aad)
+	if err != nil {
+		return nil, fmt.Errorf("Error decrypting envelope, the ciphertext or additional authenticated data don't match: %s", err)
+	}
+	return plaintext, nil
+}
+
+// kmsEnvelopeId identifies an envelope by its key and a digest of its
+// contents, as the envelope itself may be large.
+func kmsEnvelopeId(key, encoded string) string {
+	sum := sha256.Sum256([]byte(encoded))
+	return fmt.Sprintf("%s/%s", key, hex.EncodeToString(sum[:]))
+}
+
+func newKmsEnvelopeGCM(dek []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(dek)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func zeroBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// kmsSymmetricEnvelopeWrapper wraps data encryption keys with KMS encrypt.
+func kmsSymmetricEnvelopeWrapper(config *transport_tpg.Config, userAgent string, cryptoKeyId *KmsCryptoKeyId, aad []byte) kmsEnvelopeWrapFunc {
+	return func(dek []byte) (string, string, string, error) {
+		request := &cloudkms.EncryptRequest{
+			Plaintext: base64.StdEncoding.EncodeToString(dek),
+		}
+		if len(aad) > 0 {
+			request.AdditionalAuthenticatedData = base64.StdEncoding.EncodeToString(aad)
+		}
+
+		call := config.NewKmsClient(userAgent).Projects.Locations.KeyRings.CryptoKeys.Encrypt(cryptoKeyId.CryptoKeyId(), request)
+		if config.UserProjectOverride {
+			call.Header().Set("X-Goog-User-Project", cryptoKeyId.KeyRingId.Project)
+		}
+		res, err := call.Do()
+		if err != nil {
+			return "", "", "", fmt.Errorf("Error wrapping data encryption key with %s: %s", cryptoKeyId.CryptoKeyId(), err)
+		}
+		return cryptoKeyId.CryptoKeyId(), kmsSymmetricAlgorithm, res.Ciphertext, nil
+	}
+}
+
+// kmsAsymmetricEnvelopeWrapper wraps data encryption keys locally with the
+// public key of an asymmetric decryption key version.
+func kmsAsymmetricEnvelopeWrapper(config *transport_tpg.Config, userAgent string, cryptoKeyVersionId *kmsCryptoKeyVersionId) kmsEnvelopeWrapFunc {
+	return func(dek []byte) (string, string, string, error) {
+		name := cryptoKeyVersionId.cryptoKeyVersionId()
+		call := config.NewKmsClient(userAgent).Projects.Locations.KeyRings.CryptoKeys.CryptoKeyVersions.GetPublicKey(name)
+		if config.UserProjectOverride {
+			call.Header().Set("X-Goog-User-Project", cryptoKeyVersionId.CryptoKeyId.KeyRingId.Project)
+		}
+		publicKey, err := call.Do()
+		if err != nil {
+			return "", "", "", fmt.Errorf("Error fetching public key of %s: %s", name, err)
+		}
+
+		wrapped, err := wrapKmsEnvelopeKeyWithPublicKey(dek, publicKey.Pem, publicKey.Algorithm)
+		if err != nil {
+			return "", "", "", fmt.Errorf("Error wrapping data encryption key with %s: %s", name, err)
+		}
+		return name, publicKey.Algorithm, wrapped, nil
+	}
+}
+
+func wrapKmsEnvelopeKeyWithPublicKey(dek []byte, publicKeyPem, algorithm string) (string, error) {
+	h, err := kmsAsymmetricDecryptionHash(algorithm)
+	if err != nil {
+		return "", err
+	}
+
+	block, _ := pem.Decode([]byte(publicKeyPem))
+	if block == nil {
+		return "", fmt.Errorf("public key is not PEM encoded")
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return "", err
+	}
+	rsaKey, ok := parsed.(*rsa.PublicKey)
+	if !ok {
+		return "", fmt.Errorf("public key is not an RSA key")
+	}
+
+	wrapped, err := rsa.EncryptOAEP(h, rand.Reader, rsaKey, dek, nil)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(wrapped), nil
+}
+
+// kmsAsymmetricDecryptionHash returns the OAEP hash of an asymmetric
+// decryption algorithm, such as RSA_DECRYPT_OAEP_2048_SHA256.
+func kmsAsymmetricDecryptionHash(algorithm string) (hash.Hash, error) {
+	if !strings.HasPrefix(algorithm, "RSA_DECRYPT_OAEP_") {
+		return nil, fmt.Errorf("key algorithm %q can't be used for envelope encryption, an RSA_DECRYPT_OAEP key is required", algorithm)
+	}
+	switch {
+	case strings.HasSuffix(algorithm, "_SHA1"):
+		return sha1.New(), nil
+	case strings.HasSuffix(algorithm, "_SHA256"):
+		return sha256.New(), nil
+	case strings.HasSuffix(algorithm, "_SHA512"):
+		return sha512.New(), nil
+	}
+	return nil, fmt.Errorf("unsupported key algorithm %q", algorithm)
+}
+
+// kmsEnvelopeUnwrapper unwraps data encryption keys with KMS decrypt, or
+// asymmetric decrypt, depending on the key that wrapped them.
+func kmsEnvelopeUnwrapper(config *transport_tpg.Config, userAgent string, aad []byte) kmsEnvelopeUnwrapFunc {
+	return func(envelope *kmsEnvelope) ([]byte, error) {
+		var plaintext string
+		if envelope.KeyAlgorithm == kmsSymmetricAlgorithm {
+			cryptoKeyId, err := ParseKmsCryptoKeyId(envelope.Key, config)
+			if err != nil {
+				return nil, err
+			}
+			request := &cloudkms.DecryptRequest{
+				Ciphertext: envelope.WrappedKey,
+			}
+			if len(aad) > 0 {
+				request.AdditionalAuthenticatedData = base64.StdEncoding.EncodeToString(aad)
+			}
+			call := config.NewKmsClient(userAgent).Projects.Locations.KeyRings.CryptoKeys.Decrypt(cryptoKeyId.CryptoKeyId(), request)
+			if config.UserProjectOverride {
+				call.Header().Set("X-Goog-User-Project", cryptoKeyId.KeyRingId.Project)
+			}
+			res, err := call.Do()
+			if err != nil {
+				return nil, fmt.Errorf("Error unwrapping data encryption key with %s: %s", envelope.Key, err)
+			}
+			plaintext = res.Plaintext
+		} else {
+			if _, err := kmsAsymmetricDecryptionHash(envelope.KeyAlgorithm); err != nil {
+				return nil, err
+			}
+			cryptoKeyVersionId, err := parseKmsCryptoKeyVersionId(envelope.Key, config)
+			if err != nil {
+				return nil, err
+			}
+			request := &cloudkms.AsymmetricDecryptRequest{
+				Ciphertext: envelope.WrappedKey,
+			}
+			call := config.NewKmsClient(userAgent).Projects.Locations.KeyRings.CryptoKeys.CryptoKeyVersions.AsymmetricDecrypt(cryptoKeyVersionId.cryptoKeyVersionId(), request)
+			if config.UserProjectOverride {
+				call.Header().Set("X-Goog-User-Project", cryptoKeyVersionId.CryptoKeyId.KeyRingId.Project)
+			}
+			res, err := call.Do()
+			if err != nil {
+				return nil, fmt.Errorf("Error unwrapping data encryption key with %s: %s", envelope.Key, err)
+			}
+			plaintext = res.Plaintext
+		}
+
+		dek, err := base64.StdEncoding.DecodeString(plaintext)
+		if err != nil {
+			return nil, fmt.Errorf("Error decoding base64 response: %s", err)
+		}
+		return dek, nil
+	}
+}
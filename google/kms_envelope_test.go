@@ -0,0 +1,121 @@
+package google
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"strings"
+	"testing"
+)
+
+func testKmsEnvelopeIdentityWrap(dek []byte) (string, string, string, error) {
+	return "projects/p/locations/l/keyRings/r/cryptoKeys/k", kmsSymmetricAlgorithm, base64.StdEncoding.EncodeToString(dek), nil
+}
+
+func testKmsEnvelopeIdentityUnwrap(envelope *kmsEnvelope) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(envelope.WrappedKey)
+}
+
+func TestKmsEnvelopeRoundTrip(t *testing.T) {
+	// Larger than the 64KiB KMS can encrypt directly.
+	plaintext := bytes.Repeat([]byte("certificate "), 10000)
+	aad := []byte("aad")
+
+	envelope, err := sealKmsEnvelope(plaintext, aad, testKmsEnvelopeIdentityWrap)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opened, err := openKmsEnvelope(envelope, aad, testKmsEnvelopeIdentityUnwrap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(opened, plaintext) {
+		t.Errorf("expected decrypted envelope to match plaintext")
+	}
+
+	if _, err := openKmsEnvelope(envelope, []byte("other"), testKmsEnvelopeIdentityUnwrap); err == nil {
+		t.Errorf("expected decrypting with different additional authenticated data to fail")
+	}
+
+	parsed, err := parseKmsEnvelope(envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext, _ := base64.StdEncoding.DecodeString(parsed.Ciphertext)
+	ciphertext[0] ^= 0xff
+	parsed.Ciphertext = base64.StdEncoding.EncodeToString(ciphertext)
+	b, _ := json.Marshal(parsed)
+	if _, err := openKmsEnvelope(base64.StdEncoding.EncodeToString(b), aad, testKmsEnvelopeIdentityUnwrap); err == nil {
+		t.Errorf("expected decrypting a tampered envelope to fail")
+	}
+}
+
+func TestParseKmsEnvelope_invalid(t *testing.T) {
+	cases := map[string]struct {
+		Envelope string
+		Error    string
+	}{
+		"not base64": {
+			Envelope: "!!!",
+			Error:    "Error decoding envelope",
+		},
+		"not json": {
+			Envelope: base64.StdEncoding.EncodeToString([]byte("nope")),
+			Error:    "Error parsing envelope",
+		},
+		"unknown version": {
+			Envelope: base64.StdEncoding.EncodeToString([]byte(`{"version":2,"algorithm":"AES_256_GCM"}`)),
+			Error:    "Unsupported envelope version",
+		},
+		"unknown algorithm": {
+			Envelope: base64.StdEncoding.EncodeToString([]byte(`{"version":1,"algorithm":"ROT13"}`)),
+			Error:    "Unsupported envelope algorithm",
+		},
+	}
+
+	for tn, tc := range cases {
+		_, err := parseKmsEnvelope(tc.Envelope)
+		if err == nil || !strings.Contains(err.Error(), tc.Error) {
+			t.Errorf("%s: expected error containing %q, got %v", tn, tc.Error, err)
+		}
+	}
+}
+
+func TestWrapKmsEnvelopeKeyWithPublicKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKeyPem := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+
+	dek := []byte("0123456789abcdef0123456789abcdef")
+	wrapped, err := wrapKmsEnvelopeKeyWithPublicKey(dek, publicKeyPem, "RSA_DECRYPT_OAEP_2048_SHA256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(wrapped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unwrapped, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, key, ciphertext, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(unwrapped, dek) {
+		t.Errorf("expected unwrapped key to match")
+	}
+
+	if _, err := wrapKmsEnvelopeKeyWithPublicKey(dek, publicKeyPem, "RSA_SIGN_PSS_2048_SHA256"); err == nil {
+		t.Errorf("expected a signing key to be rejected")
+	}
+}
@@ -123,6 +123,22 @@ func Provider() *schema.Provider {
 				Optional: true,
 			},
 
+			"secret_state_mode": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: verify.ValidateEnum([]string{"plaintext", "hash"}),
+			},
+
+			"bigquery_dry_run": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+
+			"bigquery_dry_run_max_bytes_billed": {
+				Type:     schema.TypeInt,
+				Optional: true,
+			},
+
 			// Generated Products
 			"access_approval_custom_endpoint": {
 				Type:         schema.TypeString,
@@ -611,7 +627,11 @@ func DatasourceMapWithErrors() (map[string]*schema.Resource, error) {
 		"google_active_folder":                                DataSourceGoogleActiveFolder(),
 		"google_alloydb_locations":                            DataSourceAlloydbLocations(),
 		"google_alloydb_supported_database_flags":             DataSourceAlloydbSupportedDatabaseFlags(),
+		"google_artifact_registry_docker_image":               DataSourceArtifactRegistryDockerImage(),
+		"google_artifact_registry_packages":                   DataSourceArtifactRegistryPackages(),
 		"google_artifact_registry_repository":                 DataSourceArtifactRegistryRepository(),
+		"google_artifact_registry_tags":                       DataSourceArtifactRegistryTags(),
+		"google_artifact_registry_versions":                   DataSourceArtifactRegistryVersions(),
 		"google_app_engine_default_service_account":           DataSourceGoogleAppEngineDefaultServiceAccount(),
 		"google_beyondcorp_app_connection":                    DataSourceGoogleBeyondcorpAppConnection(),
 		"google_beyondcorp_app_connector":                     DataSourceGoogleBeyondcorpAppConnector(),
@@ -633,6 +653,7 @@ func DatasourceMapWithErrors() (map[string]*schema.Resource, error) {
 		"google_compute_backend_bucket":                       DataSourceGoogleComputeBackendBucket(),
 		"google_compute_default_service_account":              DataSourceGoogleComputeDefaultServiceAccount(),
 		"google_compute_disk":                                 DataSourceGoogleComputeDisk(),
+		"google_compute_effective_firewalls":                  DataSourceGoogleComputeEffectiveFirewalls(),
 		"google_compute_forwarding_rule":                      DataSourceGoogleComputeForwardingRule(),
 		"google_compute_global_address":                       DataSourceGoogleComputeGlobalAddress(),
 		"google_compute_global_forwarding_rule":               DataSourceGoogleComputeGlobalForwardingRule(),
@@ -653,6 +674,7 @@ func DatasourceMapWithErrors() (map[string]*schema.Resource, error) {
 		"google_compute_region_network_endpoint_group":        DataSourceGoogleComputeRegionNetworkEndpointGroup(),
 		"google_compute_region_instance_group":                DataSourceGoogleComputeRegionInstanceGroup(),
 		"google_compute_region_ssl_certificate":               DataSourceGoogleRegionComputeSslCertificate(),
+		"google_compute_resolved_images":                      DataSourceGoogleComputeResolvedImages(),
 		"google_compute_resource_policy":                      DataSourceGoogleComputeResourcePolicy(),
 		"google_compute_router":                               DataSourceGoogleComputeRouter(),
 		"google_compute_router_nat":                           DataSourceGoogleComputeRouterNat(),
@@ -661,6 +683,7 @@ func DatasourceMapWithErrors() (map[string]*schema.Resource, error) {
 		"google_compute_ssl_certificate":                      DataSourceGoogleComputeSslCertificate(),
 		"google_compute_ssl_policy":                           DataSourceGoogleComputeSslPolicy(),
 		"google_compute_subnetwork":                           DataSourceGoogleComputeSubnetwork(),
+		"google_compute_url_map_routing":                      DataSourceGoogleComputeUrlMapRouting(),
 		"google_compute_vpn_gateway":                          DataSourceGoogleComputeVpnGateway(),
 		"google_compute_zones":                                DataSourceGoogleComputeZones(),
 		"google_container_azure_versions":                     DataSourceGoogleContainerAzureVersions(),
@@ -683,6 +706,7 @@ func DatasourceMapWithErrors() (map[string]*schema.Resource, error) {
 		"google_kms_key_ring":                                 DataSourceGoogleKmsKeyRing(),
 		"google_kms_secret":                                   DataSourceGoogleKmsSecret(),
 		"google_kms_secret_ciphertext":                        DataSourceGoogleKmsSecretCiphertext(),
+		"google_kms_secret_envelope":                          DataSourceGoogleKmsSecretEnvelope(),
 		"google_folder":                                       DataSourceGoogleFolder(),
 		"google_folders":                                      DataSourceGoogleFolders(),
 		"google_folder_organization_policy":                   DataSourceGoogleFolderOrganizationPolicy(),
@@ -695,12 +719,14 @@ func DatasourceMapWithErrors() (map[string]*schema.Resource, error) {
 		"google_monitoring_app_engine_service":                DataSourceMonitoringServiceAppEngine(),
 		"google_monitoring_uptime_check_ips":                  DataSourceGoogleMonitoringUptimeCheckIps(),
 		"google_netblock_ip_ranges":                           DataSourceGoogleNetblockIpRanges(),
+		"google_network_management_connectivity_test_run":     DataSourceNetworkManagementConnectivityTestRun(),
 		"google_organization":                                 DataSourceGoogleOrganization(),
 		"google_privateca_certificate_authority":              DataSourcePrivatecaCertificateAuthority(),
 		"google_project":                                      DataSourceGoogleProject(),
 		"google_projects":                                     DataSourceGoogleProjects(),
 		"google_project_organization_policy":                  DataSourceGoogleProjectOrganizationPolicy(),
 		"google_project_service":                              DataSourceGoogleProjectService(),
+		"google_pubsub_schema_revisions":                      DataSourceGooglePubsubSchemaRevisions(),
 		"google_pubsub_subscription":                          DataSourceGooglePubsubSubscription(),
 		"google_pubsub_topic":                                 DataSourceGooglePubsubTopic(),
 		"google_secret_manager_secret":                        DataSourceSecretManagerSecret(),
@@ -832,7 +858,7 @@ func ResourceMap() map[string]*schema.Resource {
 }
 
 func ResourceMapWithErrors() (map[string]*schema.Resource, error) {
-	return mergeResourceMaps(
+	return withBigQueryDryRun(mergeResourceMaps(
 		map[string]*schema.Resource{
 			"google_folder_access_approval_settings":                       ResourceAccessApprovalFolderSettings(),
 			"google_organization_access_approval_settings":                 ResourceAccessApprovalOrganizationSettings(),
@@ -927,7 +953,10 @@ func ResourceMapWithErrors() (map[string]*schema.Resource, error) {
 			"google_cloud_asset_organization_feed":                         ResourceCloudAssetOrganizationFeed(),
 			"google_cloud_asset_project_feed":                              ResourceCloudAssetProjectFeed(),
 			"google_cloudbuild_bitbucket_server_config":                    ResourceCloudBuildBitbucketServerConfig(),
+			"google_cloudbuild_build":                                      ResourceCloudBuildBuild(),
 			"google_cloudbuild_trigger":                                    ResourceCloudBuildTrigger(),
+			"google_clouddeploy_release":                                   ResourceClouddeployRelease(),
+			"google_clouddeploy_rollout":                                   ResourceClouddeployRollout(),
 			"google_cloudfunctions_function_iam_binding":                   tpgiamresource.ResourceIamBinding(CloudFunctionsCloudFunctionIamSchema, CloudFunctionsCloudFunctionIamUpdaterProducer, CloudFunctionsCloudFunctionIdParseFunc),
 			"google_cloudfunctions_function_iam_member":                    tpgiamresource.ResourceIamMember(CloudFunctionsCloudFunctionIamSchema, CloudFunctionsCloudFunctionIamUpdaterProducer, CloudFunctionsCloudFunctionIdParseFunc),
 			"google_cloudfunctions_function_iam_policy":                    tpgiamresource.ResourceIamPolicy(CloudFunctionsCloudFunctionIamSchema, CloudFunctionsCloudFunctionIamUpdaterProducer, CloudFunctionsCloudFunctionIdParseFunc),
@@ -957,6 +986,7 @@ func ResourceMapWithErrors() (map[string]*schema.Resource, error) {
 			"google_cloud_run_v2_service_iam_member":                       tpgiamresource.ResourceIamMember(CloudRunV2ServiceIamSchema, CloudRunV2ServiceIamUpdaterProducer, CloudRunV2ServiceIdParseFunc),
 			"google_cloud_run_v2_service_iam_policy":                       tpgiamresource.ResourceIamPolicy(CloudRunV2ServiceIamSchema, CloudRunV2ServiceIamUpdaterProducer, CloudRunV2ServiceIdParseFunc),
 			"google_cloud_scheduler_job":                                   ResourceCloudSchedulerJob(),
+			"google_cloud_scheduler_job_run":                               ResourceCloudSchedulerJobRun(),
 			"google_cloud_tasks_queue":                                     ResourceCloudTasksQueue(),
 			"google_cloud_tasks_queue_iam_binding":                         tpgiamresource.ResourceIamBinding(CloudTasksQueueIamSchema, CloudTasksQueueIamUpdaterProducer, CloudTasksQueueIdParseFunc),
 			"google_cloud_tasks_queue_iam_member":                          tpgiamresource.ResourceIamMember(CloudTasksQueueIamSchema, CloudTasksQueueIamUpdaterProducer, CloudTasksQueueIdParseFunc),
@@ -983,6 +1013,7 @@ func ResourceMapWithErrors() (map[string]*schema.Resource, error) {
 			"google_compute_health_check":                                  ResourceComputeHealthCheck(),
 			"google_compute_http_health_check":                             ResourceComputeHttpHealthCheck(),
 			"google_compute_https_health_check":                            ResourceComputeHttpsHealthCheck(),
+			"google_compute_https_load_balancer":                           ResourceComputeHttpsLoadBalancer(),
 			"google_compute_image":                                         ResourceComputeImage(),
 			"google_compute_image_iam_binding":                             tpgiamresource.ResourceIamBinding(ComputeImageIamSchema, ComputeImageIamUpdaterProducer, ComputeImageIdParseFunc),
 			"google_compute_image_iam_member":                              tpgiamresource.ResourceIamMember(ComputeImageIamSchema, ComputeImageIamUpdaterProducer, ComputeImageIdParseFunc),
@@ -1191,6 +1222,7 @@ func ResourceMapWithErrors() (map[string]*schema.Resource, error) {
 			"google_kms_key_ring":                                          ResourceKMSKeyRing(),
 			"google_kms_key_ring_import_job":                               ResourceKMSKeyRingImportJob(),
 			"google_kms_secret_ciphertext":                                 ResourceKMSSecretCiphertext(),
+			"google_kms_secret_envelope":                                   ResourceKMSSecretEnvelope(),
 			"google_logging_linked_dataset":                                ResourceLoggingLinkedDataset(),
 			"google_logging_log_view":                                      ResourceLoggingLogView(),
 			"google_logging_metric":                                        ResourceLoggingMetric(),
@@ -1309,6 +1341,7 @@ func ResourceMapWithErrors() (map[string]*schema.Resource, error) {
 			"google_cloudfunctions_function":                ResourceCloudFunctionsFunction(),
 			"google_composer_environment":                   ResourceComposerEnvironment(),
 			"google_compute_attached_disk":                  ResourceComputeAttachedDisk(),
+			"google_compute_image_lock":                     ResourceComputeImageLock(),
 			"google_compute_instance":                       ResourceComputeInstance(),
 			"google_compute_instance_from_template":         ResourceComputeInstanceFromTemplate(),
 			"google_compute_instance_group":                 ResourceComputeInstanceGroup(),
@@ -1323,6 +1356,7 @@ func ResourceMapWithErrors() (map[string]*schema.Resource, error) {
 			"google_compute_security_policy":                ResourceComputeSecurityPolicy(),
 			"google_compute_shared_vpc_host_project":        ResourceComputeSharedVpcHostProject(),
 			"google_compute_shared_vpc_service_project":     ResourceComputeSharedVpcServiceProject(),
+			"google_compute_snapshot_schedule_attachment":   ResourceComputeSnapshotScheduleAttachment(),
 			"google_compute_target_pool":                    ResourceComputeTargetPool(),
 			"google_container_cluster":                      ResourceContainerCluster(),
 			"google_container_node_pool":                    ResourceContainerNodePool(),
@@ -1332,6 +1366,8 @@ func ResourceMapWithErrors() (map[string]*schema.Resource, error) {
 			"google_dataproc_job":                           ResourceDataprocJob(),
 			"google_dialogflow_cx_version":                  ResourceDialogflowCXVersion(),
 			"google_dialogflow_cx_environment":              ResourceDialogflowCXEnvironment(),
+			"google_dns_managed_zone_delegation":            ResourceDnsManagedZoneDelegation(),
+			"google_dns_managed_zone_records":               ResourceDnsManagedZoneRecords(),
 			"google_dns_record_set":                         ResourceDnsRecordSet(),
 			"google_endpoints_service":                      ResourceEndpointsService(),
 			"google_folder":                                 ResourceGoogleFolder(),
@@ -1350,7 +1386,9 @@ func ResourceMapWithErrors() (map[string]*schema.Resource, error) {
 			"google_logging_project_bucket_config":          ResourceLoggingProjectBucketConfig(),
 			"google_monitoring_dashboard":                   ResourceMonitoringDashboard(),
 			"google_service_networking_connection":          ResourceServiceNetworkingConnection(),
+			"google_spanner_database_schema":                ResourceSpannerDatabaseSchema(),
 			"google_sql_database_instance":                  ResourceSqlDatabaseInstance(),
+			"google_sql_database_instance_restore":          ResourceSqlDatabaseInstanceRestore(),
 			"google_sql_ssl_cert":                           ResourceSqlSslCert(),
 			"google_sql_user":                               ResourceSqlUser(),
 			"google_organization_iam_custom_role":           ResourceGoogleOrganizationIamCustomRole(),
@@ -1439,7 +1477,7 @@ func ResourceMapWithErrors() (map[string]*schema.Resource, error) {
 			// ####### END non-generated IAM resources ###########
 		},
 		dclResources,
-	)
+	))
 }
 
 func providerConfigure(ctx context.Context, d *schema.ResourceData, p *schema.Provider) (interface{}, diag.Diagnostics) {
@@ -1476,6 +1514,13 @@ func providerConfigure(ctx context.Context, d *schema.ResourceData, p *schema.Pr
 		config.RequestReason = v.(string)
 	}
 
+	if v, ok := d.GetOk("secret_state_mode"); ok {
+		config.SecretStateMode = v.(string)
+	}
+
+	config.BigQueryDryRun = d.Get("bigquery_dry_run").(bool)
+	config.BigQueryDryRunMaxBytesBilled = int64(d.Get("bigquery_dry_run_max_bytes_billed").(int))
+
 	// Check for primary credentials in config. Note that if neither is set, ADCs
 	// will be used if available.
 	if v, ok := d.GetOk("access_token"); ok {
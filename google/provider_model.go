@@ -23,6 +23,9 @@ type ProviderModel struct {
 	UserProjectOverride                types.Bool   `tfsdk:"user_project_override"`
 	RequestTimeout                     types.String `tfsdk:"request_timeout"`
 	RequestReason                      types.String `tfsdk:"request_reason"`
+	SecretStateMode                    types.String `tfsdk:"secret_state_mode"`
+	BigQueryDryRun                     types.Bool   `tfsdk:"bigquery_dry_run"`
+	BigQueryDryRunMaxBytesBilled       types.Int64  `tfsdk:"bigquery_dry_run_max_bytes_billed"`
 
 	// Generated Products
 	AccessApprovalCustomEndpoint           types.String `tfsdk:"access_approval_custom_endpoint"`
@@ -1,12 +1,23 @@
 package google
 
 import (
+	"context"
+	"encoding/base64"
 	"fmt"
+	"log"
 	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/hashicorp/terraform-provider-google/google/tpgresource"
+	transport_tpg "github.com/hashicorp/terraform-provider-google/google/transport"
 )
 
 const PubsubTopicRegex = "projects\\/.*\\/topics\\/.*"
 
+var pubsubSchemaNameRegex = regexp.MustCompile("^projects\\/[^/]+\\/schemas\\/[^/]+$")
+
 func getComputedSubscriptionName(project, subscription string) string {
 	match, _ := regexp.MatchString("projects\\/.*\\/subscriptions\\/.*", subscription)
 	if match {
@@ -22,3 +33,185 @@ func getComputedTopicName(project, topic string) string {
 	}
 	return fmt.Sprintf("projects/%s/topics/%s", project, topic)
 }
+
+func pubsubSchemaDefinitionChanged(_ context.Context, d *schema.ResourceDiff, meta interface{}) bool {
+	return d.Id() != "" && d.HasChange("definition")
+}
+
+// validatePubsubSchemaDefinition checks that the configured definition is a
+// valid schema, and that the sample messages are valid against it. If
+// againstRevisions is set, the sample messages also have to be valid against
+// every existing revision of the schema, so that committing the definition
+// doesn't break publishers or subscribers of messages of an earlier shape,
+// such as those of topics pinned to a revision range.
+func validatePubsubSchemaDefinition(d *schema.ResourceData, config *transport_tpg.Config, billingProject, userAgent string, againstRevisions bool) error {
+	project, err := tpgresource.GetProject(d, config)
+	if err != nil {
+		return err
+	}
+	name, err := tpgresource.ReplaceVars(d, config, "projects/{{project}}/schemas/{{name}}")
+	if err != nil {
+		return err
+	}
+
+	definition := map[string]interface{}{
+		"type":       d.Get("type").(string),
+		"definition": d.Get("definition").(string),
+	}
+
+	url := fmt.Sprintf("%sprojects/%s/schemas:validate", config.PubsubBasePath, project)
+	_, err = transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
+		Config:    config,
+		Method:    "POST",
+		Project:   billingProject,
+		RawURL:    url,
+		UserAgent: userAgent,
+		Body: map[string]interface{}{
+			"schema": definition,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("Error validating definition of Schema %q: %s", name, err)
+	}
+
+	var revisions []interface{}
+	if againstRevisions {
+		revisions, err = listPubsubSchemaRevisions(config, name, billingProject, userAgent)
+		if err != nil {
+			return err
+		}
+	}
+
+	url = fmt.Sprintf("%sprojects/%s/schemas:validateMessage", config.PubsubBasePath, project)
+	for i, raw := range d.Get("sample_messages").([]interface{}) {
+		sample := raw.(map[string]interface{})
+		message := sample["data"].(string)
+		if sample["encoding"].(string) != "BINARY" {
+			message = base64.StdEncoding.EncodeToString([]byte(message))
+		}
+
+		if err := validatePubsubSchemaMessage(config, billingProject, userAgent, url, definition, message, sample["encoding"]); err != nil {
+			return fmt.Errorf("sample_messages.%d is not valid against the new definition of Schema %q: %s", i, name, err)
+		}
+
+		for _, raw := range revisions {
+			revision := raw.(map[string]interface{})
+			revisionDefinition := map[string]interface{}{
+				"type":       revision["type"],
+				"definition": revision["definition"],
+			}
+			if err := validatePubsubSchemaMessage(config, billingProject, userAgent, url, revisionDefinition, message, sample["encoding"]); err != nil {
+				return fmt.Errorf("sample_messages.%d is not valid against revision %s of Schema %q: %s", i, revision["revisionId"], name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validatePubsubSchemaMessage validates a base64-encoded message against a
+// schema definition.
+func validatePubsubSchemaMessage(config *transport_tpg.Config, billingProject, userAgent, url string, definition map[string]interface{}, message string, encoding interface{}) error {
+	_, err := transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
+		Config:    config,
+		Method:    "POST",
+		Project:   billingProject,
+		RawURL:    url,
+		UserAgent: userAgent,
+		Body: map[string]interface{}{
+			"schema":   definition,
+			"message":  message,
+			"encoding": encoding,
+		},
+	})
+	return err
+}
+
+// commitPubsubSchemaRevision makes the configured definition the current
+// revision of the schema. If an existing revision has the same definition,
+// the schema is rolled back to it rather than a duplicate being committed.
+func commitPubsubSchemaRevision(d *schema.ResourceData, config *transport_tpg.Config, billingProject, userAgent string) (map[string]interface{}, error) {
+	name, err := tpgresource.ReplaceVars(d, config, "projects/{{project}}/schemas/{{name}}")
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := listPubsubSchemaRevisions(config, name, billingProject, userAgent)
+	if err != nil {
+		return nil, err
+	}
+
+	schemaType := d.Get("type").(string)
+	definition := d.Get("definition").(string)
+	url := fmt.Sprintf("%s%s:commit", config.PubsubBasePath, name)
+	body := map[string]interface{}{
+		"schema": map[string]interface{}{
+			"name":       name,
+			"type":       schemaType,
+			"definition": definition,
+		},
+	}
+	if revisionId := pubsubSchemaRevisionWithDefinition(revisions, schemaType, definition); revisionId != "" {
+		log.Printf("[DEBUG] Rolling back Schema %q to revision %s", name, revisionId)
+		url = fmt.Sprintf("%s%s:rollback", config.PubsubBasePath, name)
+		body = map[string]interface{}{
+			"revisionId": revisionId,
+		}
+	}
+
+	return transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
+		Config:    config,
+		Method:    "POST",
+		Project:   billingProject,
+		RawURL:    url,
+		UserAgent: userAgent,
+		Body:      body,
+		Timeout:   d.Timeout(schema.TimeoutUpdate),
+	})
+}
+
+// pubsubSchemaRevisionWithDefinition returns the ID of the most recent
+// revision with the given type and definition, or "" if there is none.
+// Revisions are listed newest first.
+func pubsubSchemaRevisionWithDefinition(revisions []interface{}, schemaType, definition string) string {
+	for _, raw := range revisions {
+		revision := raw.(map[string]interface{})
+		if revision["type"] == schemaType && strings.TrimSpace(revision["definition"].(string)) == strings.TrimSpace(definition) {
+			return revision["revisionId"].(string)
+		}
+	}
+	return ""
+}
+
+// listPubsubSchemaRevisions returns all revisions of a schema, newest first,
+// including their definitions.
+func listPubsubSchemaRevisions(config *transport_tpg.Config, name, billingProject, userAgent string) ([]interface{}, error) {
+	var revisions []interface{}
+	params := map[string]string{"view": "FULL"}
+	for {
+		url, err := transport_tpg.AddQueryParams(fmt.Sprintf("%s%s:listRevisions", config.PubsubBasePath, name), params)
+		if err != nil {
+			return nil, err
+		}
+		res, err := transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
+			Config:    config,
+			Method:    "GET",
+			Project:   billingProject,
+			RawURL:    url,
+			UserAgent: userAgent,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("Error listing revisions of Schema %q: %s", name, err)
+		}
+
+		if v, ok := res["schemas"].([]interface{}); ok {
+			revisions = append(revisions, v...)
+		}
+
+		token, ok := res["nextPageToken"].(string)
+		if !ok || token == "" {
+			return revisions, nil
+		}
+		params["pageToken"] = token
+	}
+}
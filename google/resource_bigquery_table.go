@@ -12,6 +12,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/structure"
@@ -323,27 +324,64 @@ func resourceBigQueryTableSchemaIsChangeable(old, new interface{}) (bool, error)
 	}
 }
 
+// bigQueryTableSchemaChange returns the old and new schema, unmarshaled.
+func bigQueryTableSchemaChange(d tpgresource.TerraformResourceDataChange) (interface{}, interface{}) {
+	oldSchema, newSchema := d.GetChange("schema")
+	oldSchemaText := oldSchema.(string)
+	newSchemaText := newSchema.(string)
+	if oldSchemaText == "null" {
+		// The API can return an empty schema which gets encoded to "null" during read.
+		oldSchemaText = "[]"
+	}
+	if newSchemaText == "null" {
+		newSchemaText = "[]"
+	}
+	var old, new interface{}
+	if err := json.Unmarshal([]byte(oldSchemaText), &old); err != nil {
+		// don't return error, its possible we are going from no schema to schema
+		// this case will be cover on the conparision regardless.
+		log.Printf("[DEBUG] unable to unmarshal json customized diff - %v", err)
+	}
+	if err := json.Unmarshal([]byte(newSchemaText), &new); err != nil {
+		// same as above
+		log.Printf("[DEBUG] unable to unmarshal json customized diff - %v", err)
+	}
+	return old, new
+}
+
+// resourceBigQueryTableFieldsCustomizeDiffFunc recreates the table for
+// changes to the field blocks that can't be made in place. The field blocks
+// follow the same rules as the JSON schema, so they're compared in their JSON
+// form.
+func resourceBigQueryTableFieldsCustomizeDiffFunc(d tpgresource.TerraformResourceDiff) error {
+	oldFields, newFields := d.GetChange("field")
+	old, err := bigQueryTableFieldsToJson(oldFields)
+	if err != nil {
+		return err
+	}
+	new, err := bigQueryTableFieldsToJson(newFields)
+	if err != nil {
+		return err
+	}
+	isChangeable, err := resourceBigQueryTableSchemaIsChangeable(old, new)
+	if err != nil {
+		return err
+	}
+	if !isChangeable {
+		if err := d.ForceNew("field"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func resourceBigQueryTableSchemaCustomizeDiffFunc(d tpgresource.TerraformResourceDiff) error {
 	if _, hasSchema := d.GetOk("schema"); hasSchema {
-		oldSchema, newSchema := d.GetChange("schema")
-		oldSchemaText := oldSchema.(string)
-		newSchemaText := newSchema.(string)
-		if oldSchemaText == "null" {
-			// The API can return an empty schema which gets encoded to "null" during read.
-			oldSchemaText = "[]"
-		}
-		if newSchemaText == "null" {
-			newSchemaText = "[]"
-		}
-		var old, new interface{}
-		if err := json.Unmarshal([]byte(oldSchemaText), &old); err != nil {
-			// don't return error, its possible we are going from no schema to schema
-			// this case will be cover on the conparision regardless.
-			log.Printf("[DEBUG] unable to unmarshal json customized diff - %v", err)
-		}
-		if err := json.Unmarshal([]byte(newSchemaText), &new); err != nil {
-			// same as above
-			log.Printf("[DEBUG] unable to unmarshal json customized diff - %v", err)
+		old, new := bigQueryTableSchemaChange(d)
+		if renames, ok := bigQueryTableSchemaMigrationRenames(d); ok {
+			// Only the changes left over after the in-place migration need
+			// to be made by a regular update.
+			old, _ = bigQueryTableSchemaMigration("", old, new, renames)
 		}
 		isChangeable, err := resourceBigQueryTableSchemaIsChangeable(old, new)
 		if err != nil {
@@ -360,6 +398,11 @@ func resourceBigQueryTableSchemaCustomizeDiffFunc(d tpgresource.TerraformResourc
 }
 
 func resourceBigQueryTableSchemaCustomizeDiff(_ context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	// Both field and schema are always read into state, so whether the field
+	// blocks are used can only be told from the configuration.
+	if bigQueryTableFieldsConfigured(d) {
+		return resourceBigQueryTableFieldsCustomizeDiffFunc(d)
+	}
 	return resourceBigQueryTableSchemaCustomizeDiffFunc(d)
 }
 
@@ -374,6 +417,7 @@ func ResourceBigQueryTable() *schema.Resource {
 		},
 		CustomizeDiff: customdiff.All(
 			resourceBigQueryTableSchemaCustomizeDiff,
+			resourceBigQueryTableSchemaMigrationCustomizeDiff,
 		),
 		Schema: map[string]*schema.Schema{
 			// TableId: [Required] The ID of the table. The ID must contain only
@@ -696,6 +740,40 @@ func ResourceBigQueryTable() *schema.Resource {
 				DiffSuppressFunc: bigQueryTableSchemaDiffSuppress,
 				Description:      `A JSON schema for the table.`,
 			},
+			// Field: [Optional] Describes the schema of this table as blocks, as
+			// an alternative to the JSON schema.
+			"field": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{"schema", "schema_migration"},
+				Description:   `The schema of the table as field blocks, as an alternative to the JSON schema. Changes to individual fields are shown in plans.`,
+				Elem:          bigQueryTableFieldSchema(bigQueryTableFieldMaxDepth),
+			},
+			// SchemaMigration: If set, schema changes that BigQuery supports in
+			// place are made with DDL query jobs instead of recreating the table.
+			"schema_migration": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: `If set, dropped columns, renamed columns and widened column types are migrated in place with ALTER TABLE statements, instead of recreating the table. The table is still recreated for changes that can't be made in place.`,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"column_renames": {
+							Type:        schema.TypeMap,
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: `A map of old to new top-level column names. A column that is removed from the schema while a column with the new name is added is renamed instead of being dropped.`,
+						},
+					},
+				},
+			},
+			"schema_migration_ddl": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: `The DDL statements run by the most recent in-place schema migration.`,
+			},
 			// View: [Optional] If specified, configures this table as a view.
 			"view": {
 				Type:        schema.TypeList,
@@ -1043,7 +1121,13 @@ func resourceTable(d *schema.ResourceData, meta interface{}) (*bigquery.Table, e
 		table.Labels = labels
 	}
 
-	if v, ok := d.GetOk("schema"); ok {
+	// Both schema and field are computed from the table schema, so use
+	// whichever one is configured.
+	if bigQueryTableFieldsConfigured(d) {
+		table.Schema = &bigquery.TableSchema{
+			Fields: expandBigQueryTableFields(d.Get("field").([]interface{})),
+		}
+	} else if v, ok := d.GetOk("schema"); ok {
 		schema, err := expandSchema(v)
 		if err != nil {
 			return nil, err
@@ -1262,6 +1346,9 @@ func resourceBigQueryTableRead(d *schema.ResourceData, meta interface{}) error {
 		if err := d.Set("schema", schema); err != nil {
 			return fmt.Errorf("Error setting schema: %s", err)
 		}
+		if err := d.Set("field", flattenBigQueryTableFields(res.Schema.Fields)); err != nil {
+			return fmt.Errorf("Error setting field: %s", err)
+		}
 	}
 
 	if res.View != nil {
@@ -1304,6 +1391,10 @@ func resourceBigQueryTableUpdate(d *schema.ResourceData, meta interface{}) error
 	datasetID := d.Get("dataset_id").(string)
 	tableID := d.Get("table_id").(string)
 
+	if err := resourceBigQueryTableSchemaMigrate(d, config, project, userAgent); err != nil {
+		return err
+	}
+
 	if _, err = config.NewBigQueryClient(userAgent).Tables.Update(project, datasetID, tableID, table).Do(); err != nil {
 		return err
 	}
@@ -1636,6 +1727,137 @@ func flattenSchema(tableSchema *bigquery.TableSchema) (string, error) {
 	return string(schema), nil
 }
 
+// BigQuery supports up to 15 levels of nested fields.
+const bigQueryTableFieldMaxDepth = 15
+
+// bigQueryTableFieldSchema returns the schema of a field block, with nested
+// field blocks down to the given depth.
+func bigQueryTableFieldSchema(depth int) *schema.Resource {
+	s := map[string]*schema.Schema{
+		"name": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: `The name of the field.`,
+		},
+		"type": {
+			Type:     schema.TypeString,
+			Required: true,
+			DiffSuppressFunc: func(_, old, new string, _ *schema.ResourceData) bool {
+				return bigQueryTableTypeEq(old, new)
+			},
+			Description: `The type of the field, for example STRING, INTEGER or RECORD.`,
+		},
+		"mode": {
+			Type:             schema.TypeString,
+			Optional:         true,
+			DiffSuppressFunc: bigQueryTableFieldModeDiffSuppress,
+			Description:      `The mode of the field, one of NULLABLE, REQUIRED or REPEATED. Defaults to NULLABLE.`,
+		},
+		"description": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: `The description of the field.`,
+		},
+		"policy_tags": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: `The policy tags attached to the field, for column-level access control.`,
+		},
+	}
+	if depth > 1 {
+		s["field"] = &schema.Schema{
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: `The nested fields of a RECORD field.`,
+			Elem:        bigQueryTableFieldSchema(depth - 1),
+		}
+	}
+	return &schema.Resource{Schema: s}
+}
+
+func bigQueryTableFieldModeDiffSuppress(_, old, new string, _ *schema.ResourceData) bool {
+	if old == "" {
+		old = "NULLABLE"
+	}
+	if new == "" {
+		new = "NULLABLE"
+	}
+	return strings.EqualFold(old, new)
+}
+
+// bigQueryTableFieldsConfigured returns whether the table schema is
+// configured with field blocks rather than the JSON schema.
+func bigQueryTableFieldsConfigured(d interface{ GetRawConfig() cty.Value }) bool {
+	rawConfig := d.GetRawConfig()
+	if rawConfig.IsNull() || !rawConfig.IsKnown() {
+		return false
+	}
+	fields := rawConfig.GetAttr("field")
+	return !fields.IsNull() && fields.IsKnown() && fields.LengthInt() > 0
+}
+
+func expandBigQueryTableFields(configured []interface{}) []*bigquery.TableFieldSchema {
+	fields := make([]*bigquery.TableFieldSchema, 0, len(configured))
+	for _, raw := range configured {
+		if raw == nil {
+			continue
+		}
+		f := raw.(map[string]interface{})
+		field := &bigquery.TableFieldSchema{
+			Name:        f["name"].(string),
+			Type:        f["type"].(string),
+			Mode:        f["mode"].(string),
+			Description: f["description"].(string),
+		}
+		if v, ok := f["policy_tags"]; ok && len(v.([]interface{})) > 0 {
+			field.PolicyTags = &bigquery.TableFieldSchemaPolicyTags{
+				Names: tpgresource.ConvertStringArr(v.([]interface{})),
+			}
+		}
+		if v, ok := f["field"]; ok && len(v.([]interface{})) > 0 {
+			field.Fields = expandBigQueryTableFields(v.([]interface{}))
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func flattenBigQueryTableFields(fields []*bigquery.TableFieldSchema) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(fields))
+	for _, field := range fields {
+		f := map[string]interface{}{
+			"name":        field.Name,
+			"type":        field.Type,
+			"mode":        field.Mode,
+			"description": field.Description,
+		}
+		if field.PolicyTags != nil {
+			f["policy_tags"] = field.PolicyTags.Names
+		}
+		if len(field.Fields) > 0 {
+			f["field"] = flattenBigQueryTableFields(field.Fields)
+		}
+		result = append(result, f)
+	}
+	return result
+}
+
+// bigQueryTableFieldsToJson converts field blocks to the unmarshaled JSON
+// schema, so that they can be compared like the JSON schema.
+func bigQueryTableFieldsToJson(fields interface{}) (interface{}, error) {
+	configured, _ := fields.([]interface{})
+	b, err := json.Marshal(expandBigQueryTableFields(configured))
+	if err != nil {
+		return nil, err
+	}
+	var result interface{}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 func expandTimePartitioning(configured interface{}) *bigquery.TimePartitioning {
 	raw := configured.([]interface{})[0].(map[string]interface{})
 	tp := &bigquery.TimePartitioning{Type: raw["type"].(string)}
@@ -0,0 +1,196 @@
+package google
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	transport_tpg "github.com/hashicorp/terraform-provider-google/google/transport"
+)
+
+// Column type changes that ALTER COLUMN SET DATA TYPE can make in place,
+// keyed by the standard SQL name of the current type.
+// https://cloud.google.com/bigquery/docs/reference/standard-sql/conversion_rules#coercion
+var bigQueryTableCoercibleTypes = map[string][]string{
+	"INT64":   {"NUMERIC", "BIGNUMERIC", "FLOAT64"},
+	"NUMERIC": {"BIGNUMERIC", "FLOAT64"},
+}
+
+// bigQueryTableStandardSqlType maps the legacy type names used in table
+// schemas to the names used in DDL.
+func bigQueryTableStandardSqlType(t string) string {
+	switch t = strings.ToUpper(t); t {
+	case "INTEGER":
+		return "INT64"
+	case "FLOAT":
+		return "FLOAT64"
+	case "BOOLEAN":
+		return "BOOL"
+	case "RECORD":
+		return "STRUCT"
+	}
+	return t
+}
+
+func bigQueryTableTypeIsCoercible(old, new string) bool {
+	for _, t := range bigQueryTableCoercibleTypes[bigQueryTableStandardSqlType(old)] {
+		if t == bigQueryTableStandardSqlType(new) {
+			return true
+		}
+	}
+	return false
+}
+
+// bigQueryTableSchemaChangeData is implemented by both ResourceData and
+// ResourceDiff, so that migrations are planned and applied by the same code.
+type bigQueryTableSchemaChangeData interface {
+	HasChange(string) bool
+	GetChange(string) (interface{}, interface{})
+	Get(string) interface{}
+}
+
+// bigQueryTableSchemaMigrationRenames returns the configured column renames,
+// and whether schema migrations are enabled at all.
+func bigQueryTableSchemaMigrationRenames(d bigQueryTableSchemaChangeData) (map[string]string, bool) {
+	l, ok := d.Get("schema_migration").([]interface{})
+	if !ok || len(l) == 0 {
+		return nil, false
+	}
+	renames := make(map[string]string)
+	// An empty schema_migration block is read as a nil element.
+	if raw, ok := l[0].(map[string]interface{}); ok {
+		columnRenames, _ := raw["column_renames"].(map[string]interface{})
+		for k, v := range columnRenames {
+			renames[k] = v.(string)
+		}
+	}
+	return renames, true
+}
+
+// bigQueryTableSchemaMigration works out the DDL statements that take the
+// top-level columns of the old schema as close as possible to the new one:
+// it drops removed columns, renames columns according to renames, and widens
+// column types where BigQuery can coerce the existing data. It returns the
+// old schema as it looks after running the statements, so the caller can
+// check whether the remaining differences can be made by a regular update.
+func bigQueryTableSchemaMigration(table string, old, new interface{}, renames map[string]string) (interface{}, []string) {
+	arrayOld, ok := old.([]interface{})
+	if !ok {
+		return old, nil
+	}
+	arrayNew, ok := new.([]interface{})
+	if !ok {
+		return old, nil
+	}
+	if bigQueryTablecheckNameExists(arrayOld) != nil || bigQueryTablecheckNameExists(arrayNew) != nil {
+		return old, nil
+	}
+	mapOld := bigQueryArrayToMapIndexedByName(arrayOld)
+	mapNew := bigQueryArrayToMapIndexedByName(arrayNew)
+
+	renamed := make(map[string]string)
+	renameTargets := make(map[string]bool)
+	for from, to := range renames {
+		_, inOld := mapOld[from]
+		_, stillInNew := mapNew[from]
+		_, inNew := mapNew[to]
+		if inOld && !stillInNew && inNew {
+			renamed[from] = to
+			renameTargets[to] = true
+		}
+	}
+
+	var drops, renameDdl, alters []string
+	var migrated []interface{}
+	for _, raw := range arrayOld {
+		column := raw.(map[string]interface{})
+		name := column["name"].(string)
+
+		if _, ok := renamed[name]; !ok {
+			if _, ok := mapNew[name]; !ok || renameTargets[name] {
+				drops = append(drops, fmt.Sprintf("ALTER TABLE %s DROP COLUMN `%s`", table, name))
+				continue
+			}
+		}
+
+		copied := make(map[string]interface{})
+		for k, v := range column {
+			copied[k] = v
+		}
+		if to, ok := renamed[name]; ok {
+			renameDdl = append(renameDdl, fmt.Sprintf("ALTER TABLE %s RENAME COLUMN `%s` TO `%s`", table, name, to))
+			name = to
+			copied["name"] = to
+		}
+
+		oldType, _ := copied["type"].(string)
+		newType, _ := mapNew[name].(map[string]interface{})["type"].(string)
+		if oldType != "" && newType != "" && !bigQueryTableTypeEq(oldType, newType) && bigQueryTableTypeIsCoercible(oldType, newType) {
+			alters = append(alters, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN `%s` SET DATA TYPE %s", table, name, bigQueryTableStandardSqlType(newType)))
+			copied["type"] = newType
+		}
+
+		migrated = append(migrated, copied)
+	}
+
+	// Drops go first so a column can be renamed to the name of one that was
+	// removed in the same change.
+	ddl := append(append(drops, renameDdl...), alters...)
+	if migrated == nil {
+		migrated = []interface{}{}
+	}
+	return migrated, ddl
+}
+
+// bigQueryTableSchemaMigrationDdl returns the DDL that a schema change will
+// run, or nil if the change doesn't need any or can't be made in place.
+func bigQueryTableSchemaMigrationDdl(d bigQueryTableSchemaChangeData) ([]string, error) {
+	renames, ok := bigQueryTableSchemaMigrationRenames(d)
+	if !ok || !d.HasChange("schema") {
+		return nil, nil
+	}
+
+	old, new := bigQueryTableSchemaChange(d)
+	table := fmt.Sprintf("`%s.%s`", d.Get("dataset_id").(string), d.Get("table_id").(string))
+	migrated, ddl := bigQueryTableSchemaMigration(table, old, new, renames)
+	if len(ddl) == 0 {
+		return nil, nil
+	}
+
+	isChangeable, err := resourceBigQueryTableSchemaIsChangeable(migrated, new)
+	if err != nil || !isChangeable {
+		return nil, err
+	}
+	return ddl, nil
+}
+
+func resourceBigQueryTableSchemaMigrationCustomizeDiff(_ context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" {
+		return nil
+	}
+
+	ddl, err := bigQueryTableSchemaMigrationDdl(d)
+	if err != nil {
+		return err
+	}
+	if len(ddl) > 0 {
+		return d.SetNew("schema_migration_ddl", ddl)
+	}
+	return nil
+}
+
+// resourceBigQueryTableSchemaMigrate runs the DDL for an in-place schema
+// migration, ahead of the table update that makes any remaining changes.
+func resourceBigQueryTableSchemaMigrate(d *schema.ResourceData, config *transport_tpg.Config, project, userAgent string) error {
+	ddl, err := bigQueryTableSchemaMigrationDdl(d)
+	if err != nil || len(ddl) == 0 {
+		return err
+	}
+
+	log.Printf("[INFO] Migrating schema of BigQuery table %s: %s", d.Id(), strings.Join(ddl, "; "))
+	script := strings.Join(ddl, ";\n") + ";"
+	return bigQueryRunQuery(config, project, d.Get("location").(string), script, fmt.Sprintf("schema migration of BigQuery table %s", d.Id()), userAgent, d.Timeout(schema.TimeoutUpdate))
+}
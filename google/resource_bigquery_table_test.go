@@ -1,12 +1,15 @@
 package google
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"regexp"
 	"strings"
 	"testing"
 
+	ctyjson "github.com/hashicorp/go-cty/cty/json"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/hashicorp/terraform-provider-google/google/acctest"
@@ -426,6 +429,89 @@ func TestAccBigQueryTable_Basic(t *testing.T) {
 	})
 }
 
+func TestAccBigQueryTable_fieldBlocks(t *testing.T) {
+	t.Parallel()
+
+	datasetID := fmt.Sprintf("tf_test_%s", RandString(t, 10))
+	tableID := fmt.Sprintf("tf_test_%s", RandString(t, 10))
+
+	VcrTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.AccTestPreCheck(t) },
+		ProtoV5ProviderFactories: ProtoV5ProviderFactories(t),
+		CheckDestroy:             testAccCheckBigQueryTableDestroyProducer(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccBigQueryTableFieldBlocks(datasetID, tableID, "The city", ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("google_bigquery_table.test", "field.#", "2"),
+					resource.TestCheckResourceAttr("google_bigquery_table.test", "field.1.field.1.field.0.name", "lon"),
+				),
+			},
+			{
+				ResourceName:            "google_bigquery_table.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"deletion_protection"},
+			},
+			{
+				Config: testAccBigQueryTableFieldBlocks(datasetID, tableID, "The city of the event", `
+  field {
+    name = "some_string"
+    type = "STRING"
+  }
+`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("google_bigquery_table.test", "field.#", "3"),
+					resource.TestCheckResourceAttr("google_bigquery_table.test", "field.1.description", "The city of the event"),
+				),
+			},
+			{
+				ResourceName:            "google_bigquery_table.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"deletion_protection"},
+			},
+		},
+	})
+}
+
+func TestAccBigQueryTable_schemaMigration(t *testing.T) {
+	t.Parallel()
+
+	datasetID := fmt.Sprintf("tf_test_%s", RandString(t, 10))
+	tableID := fmt.Sprintf("tf_test_%s", RandString(t, 10))
+
+	VcrTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.AccTestPreCheck(t) },
+		ProtoV5ProviderFactories: ProtoV5ProviderFactories(t),
+		CheckDestroy:             testAccCheckBigQueryTableDestroyProducer(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccBigQueryTableSchemaMigration(datasetID, tableID, `[
+    {"name": "id", "type": "INTEGER"},
+    {"name": "old_name", "type": "STRING"},
+    {"name": "unused", "type": "STRING"}
+  ]`),
+			},
+			{
+				Config: testAccBigQueryTableSchemaMigration(datasetID, tableID, `[
+    {"name": "id", "type": "NUMERIC"},
+    {"name": "new_name", "type": "STRING"}
+  ]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("google_bigquery_table.test", "schema_migration_ddl.#", "3"),
+				),
+			},
+			{
+				ResourceName:            "google_bigquery_table.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"deletion_protection", "schema_migration", "schema_migration_ddl"},
+			},
+		},
+	})
+}
+
 func TestAccBigQueryTable_Kms(t *testing.T) {
 	t.Parallel()
 	resourceName := "google_bigquery_table.test"
@@ -1392,6 +1478,202 @@ func TestUnitBigQueryDataTable_schemaIsChangable(t *testing.T) {
 	}
 }
 
+// testUnitBigQueryDataTableRequiresNew runs the plan of a table with the
+// given state and configuration, and returns whether it recreates the table.
+func testUnitBigQueryDataTableRequiresNew(t *testing.T, state map[string]string, config map[string]interface{}) bool {
+	r := ResourceBigQueryTable()
+	configJson, err := json.Marshal(config)
+	if err != nil {
+		t.Fatalf("unable to marshal config - %v", err)
+	}
+	rawConfig, err := ctyjson.Unmarshal(configJson, r.CoreConfigSchema().ImpliedType())
+	if err != nil {
+		t.Fatalf("unable to convert config - %v", err)
+	}
+	is := &terraform.InstanceState{
+		ID:         "projects/p/datasets/d/tables/t",
+		Attributes: state,
+		RawConfig:  rawConfig,
+	}
+	diff, err := r.SimpleDiff(context.Background(), is, terraform.NewResourceConfigRaw(config), nil)
+	if err != nil {
+		t.Fatalf("unexpected error - %v", err)
+	}
+	return diff != nil && diff.RequiresNew()
+}
+
+func TestUnitBigQueryDataTable_schemaCustomizeDiff(t *testing.T) {
+	t.Parallel()
+
+	// Both schema and field are read into state, whichever is configured.
+	state := map[string]string{
+		"id":                  "projects/p/datasets/d/tables/t",
+		"project":             "p",
+		"dataset_id":          "d",
+		"table_id":            "t",
+		"schema":              `[{"name":"a","type":"STRING"},{"name":"b","type":"STRING"}]`,
+		"field.#":             "2",
+		"field.0.name":        "a",
+		"field.0.type":        "STRING",
+		"field.0.mode":        "NULLABLE",
+		"field.1.name":        "b",
+		"field.1.type":        "STRING",
+		"field.1.mode":        "NULLABLE",
+		"deletion_protection": "false",
+	}
+
+	cases := map[string]struct {
+		config      map[string]interface{}
+		requiresNew bool
+	}{
+		"schemaDropColumn": {
+			config: map[string]interface{}{
+				"schema": `[{"name":"a","type":"STRING"}]`,
+			},
+			requiresNew: true,
+		},
+		"schemaAddColumn": {
+			config: map[string]interface{}{
+				"schema": `[{"name":"a","type":"STRING"},{"name":"b","type":"STRING"},{"name":"c","type":"STRING"}]`,
+			},
+		},
+		"schemaMigrationDropColumn": {
+			config: map[string]interface{}{
+				"schema":           `[{"name":"a","type":"STRING"}]`,
+				"schema_migration": []interface{}{map[string]interface{}{}},
+			},
+		},
+		"fieldDropColumn": {
+			config: map[string]interface{}{
+				"field": []interface{}{
+					map[string]interface{}{"name": "a", "type": "STRING"},
+				},
+			},
+			requiresNew: true,
+		},
+		"fieldAddColumn": {
+			config: map[string]interface{}{
+				"field": []interface{}{
+					map[string]interface{}{"name": "a", "type": "STRING"},
+					map[string]interface{}{"name": "b", "type": "STRING"},
+					map[string]interface{}{"name": "c", "type": "STRING"},
+				},
+			},
+		},
+	}
+
+	for tn, tc := range cases {
+		tc.config["project"] = "p"
+		tc.config["dataset_id"] = "d"
+		tc.config["table_id"] = "t"
+		tc.config["deletion_protection"] = false
+		if requiresNew := testUnitBigQueryDataTableRequiresNew(t, state, tc.config); requiresNew != tc.requiresNew {
+			t.Errorf("%s: expected requiresNew to be %v, but was %v", tn, tc.requiresNew, requiresNew)
+		}
+	}
+}
+
+func TestUnitBigQueryDataTable_schemaMigration(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		jsonOld     string
+		jsonNew     string
+		renames     map[string]interface{}
+		expectedDdl []string
+		forceNew    bool
+	}{
+		"noChange": {
+			jsonOld: `[{"name": "a", "type": "STRING"}]`,
+			jsonNew: `[{"name": "a", "type": "STRING"}]`,
+		},
+		"addColumn": {
+			jsonOld: `[{"name": "a", "type": "STRING"}]`,
+			jsonNew: `[{"name": "a", "type": "STRING"}, {"name": "b", "type": "STRING"}]`,
+		},
+		"dropColumn": {
+			jsonOld:     `[{"name": "a", "type": "STRING"}, {"name": "b", "type": "STRING"}]`,
+			jsonNew:     `[{"name": "a", "type": "STRING"}]`,
+			expectedDdl: []string{"ALTER TABLE `d.t` DROP COLUMN `b`"},
+		},
+		"renameColumn": {
+			jsonOld:     `[{"name": "a", "type": "STRING"}, {"name": "b", "type": "STRING"}]`,
+			jsonNew:     `[{"name": "a", "type": "STRING"}, {"name": "c", "type": "STRING"}]`,
+			renames:     map[string]interface{}{"b": "c"},
+			expectedDdl: []string{"ALTER TABLE `d.t` RENAME COLUMN `b` TO `c`"},
+		},
+		"renameOverDroppedColumn": {
+			jsonOld: `[{"name": "a", "type": "STRING"}, {"name": "b", "type": "STRING"}]`,
+			jsonNew: `[{"name": "a", "type": "STRING"}]`,
+			renames: map[string]interface{}{"b": "a"},
+			expectedDdl: []string{
+				"ALTER TABLE `d.t` DROP COLUMN `a`",
+				"ALTER TABLE `d.t` RENAME COLUMN `b` TO `a`",
+			},
+		},
+		"widenType": {
+			jsonOld:     `[{"name": "a", "type": "INTEGER"}]`,
+			jsonNew:     `[{"name": "a", "type": "NUMERIC"}]`,
+			expectedDdl: []string{"ALTER TABLE `d.t` ALTER COLUMN `a` SET DATA TYPE NUMERIC"},
+		},
+		"renameAndWidenType": {
+			jsonOld: `[{"name": "a", "type": "INT64"}]`,
+			jsonNew: `[{"name": "b", "type": "FLOAT"}]`,
+			renames: map[string]interface{}{"a": "b"},
+			expectedDdl: []string{
+				"ALTER TABLE `d.t` RENAME COLUMN `a` TO `b`",
+				"ALTER TABLE `d.t` ALTER COLUMN `b` SET DATA TYPE FLOAT64",
+			},
+		},
+		"incompatibleType": {
+			jsonOld:  `[{"name": "a", "type": "STRING"}, {"name": "b", "type": "STRING"}]`,
+			jsonNew:  `[{"name": "a", "type": "INTEGER"}]`,
+			forceNew: true,
+		},
+		"requiredColumnAdded": {
+			jsonOld:  `[{"name": "a", "type": "STRING"}, {"name": "b", "type": "STRING"}]`,
+			jsonNew:  `[{"name": "a", "type": "STRING"}, {"name": "c", "type": "STRING", "mode": "REQUIRED"}]`,
+			forceNew: true,
+		},
+	}
+
+	for tn, tc := range cases {
+		d := &tpgresource.ResourceDiffMock{
+			Before: map[string]interface{}{
+				"schema": tc.jsonOld,
+			},
+			After: map[string]interface{}{
+				"schema":     tc.jsonNew,
+				"dataset_id": "d",
+				"table_id":   "t",
+				"schema_migration": []interface{}{
+					map[string]interface{}{
+						"column_renames": tc.renames,
+					},
+				},
+			},
+		}
+		if tc.renames == nil {
+			d.After["schema_migration"] = []interface{}{nil}
+		}
+
+		if err := resourceBigQueryTableSchemaCustomizeDiffFunc(d); err != nil {
+			t.Errorf("%s: unexpected error: %s", tn, err)
+		}
+		if d.IsForceNew != tc.forceNew {
+			t.Errorf("%s: expected d.IsForceNew to be %v, but was %v", tn, tc.forceNew, d.IsForceNew)
+		}
+
+		ddl, err := bigQueryTableSchemaMigrationDdl(d)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tn, err)
+		}
+		if !reflect.DeepEqual(ddl, tc.expectedDdl) {
+			t.Errorf("%s: expected DDL %v, got %v", tn, tc.expectedDdl, ddl)
+		}
+	}
+}
+
 func testAccCheckBigQueryExtData(t *testing.T, expectedQuoteChar string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		for _, rs := range s.RootModule().Resources {
@@ -1438,6 +1720,30 @@ func testAccCheckBigQueryTableDestroyProducer(t *testing.T) func(s *terraform.St
 	}
 }
 
+func testAccBigQueryTableSchemaMigration(datasetID, tableID, schema string) string {
+	return fmt.Sprintf(`
+resource "google_bigquery_dataset" "test" {
+  dataset_id = "%s"
+}
+
+resource "google_bigquery_table" "test" {
+  deletion_protection = false
+  table_id            = "%s"
+  dataset_id          = google_bigquery_dataset.test.dataset_id
+
+  schema = <<EOH
+  %s
+EOH
+
+  schema_migration {
+    column_renames = {
+      old_name = "new_name"
+    }
+  }
+}
+`, datasetID, tableID, schema)
+}
+
 func testAccBigQueryTableTimePartitioning(datasetID, tableID, partitioningType string) string {
 	return fmt.Sprintf(`
 resource "google_bigquery_dataset" "test" {
@@ -1496,6 +1802,48 @@ EOH
 `, datasetID, tableID, partitioningType)
 }
 
+func testAccBigQueryTableFieldBlocks(datasetID, tableID, cityDescription, extraFields string) string {
+	return fmt.Sprintf(`
+resource "google_bigquery_dataset" "test" {
+  dataset_id = "%s"
+}
+
+resource "google_bigquery_table" "test" {
+  deletion_protection = false
+  table_id            = "%s"
+  dataset_id          = google_bigquery_dataset.test.dataset_id
+
+  field {
+    name = "ts"
+    type = "TIMESTAMP"
+    mode = "REQUIRED"
+  }
+
+  field {
+    name        = "city"
+    type        = "RECORD"
+    description = "%s"
+
+    field {
+      name = "id"
+      type = "INT64"
+    }
+
+    field {
+      name = "coord"
+      type = "RECORD"
+
+      field {
+        name = "lon"
+        type = "FLOAT"
+      }
+    }
+  }
+%s
+}
+`, datasetID, tableID, cityDescription, extraFields)
+}
+
 func testAccBigQueryTableKms(cryptoKeyName, datasetID, tableID string) string {
 	return fmt.Sprintf(`
 resource "google_bigquery_dataset" "test" {
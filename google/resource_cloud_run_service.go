@@ -26,6 +26,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 
 	"github.com/hashicorp/terraform-provider-google/google/tpgresource"
@@ -117,7 +118,10 @@ func ResourceCloudRunService() *schema.Resource {
 		},
 
 		SchemaVersion: 1,
-		CustomizeDiff: revisionNameCustomizeDiff,
+		CustomizeDiff: customdiff.All(
+			revisionNameCustomizeDiff,
+			cloudRunRolloutCustomizeDiff,
+		),
 
 		Schema: map[string]*schema.Schema{
 			"location": {
@@ -846,6 +850,7 @@ allowed to change on PUT operations.`,
 					},
 				},
 			},
+			"rollout": cloudRunRolloutSchema(),
 			"traffic": {
 				Type:     schema.TypeList,
 				Computed: true,
@@ -1367,6 +1372,10 @@ func resourceCloudRunServiceUpdate(d *schema.ResourceData, meta interface{}) err
 		billingProject = bp
 	}
 
+	if err := runCloudRunServiceRollout(d, meta, obj); err != nil {
+		return err
+	}
+
 	res, err := transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
 		Config:               config,
 		Method:               "PUT",
@@ -41,6 +41,8 @@ func ResourceCloudRunV2Service() *schema.Resource {
 			State: resourceCloudRunV2ServiceImport,
 		},
 
+		CustomizeDiff: cloudRunRolloutCustomizeDiff,
+
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(20 * time.Minute),
 			Update: schema.DefaultTimeout(20 * time.Minute),
@@ -702,6 +704,7 @@ For example, if ALPHA is provided as input, but only BETA and GA-level features
 				ForceNew:    true,
 				Description: `The location of the cloud run service`,
 			},
+			"rollout": cloudRunRolloutSchema(),
 			"traffic": {
 				Type:        schema.TypeList,
 				Computed:    true,
@@ -1254,6 +1257,10 @@ func resourceCloudRunV2ServiceUpdate(d *schema.ResourceData, meta interface{}) e
 		billingProject = bp
 	}
 
+	if err := runCloudRunV2ServiceRollout(d, meta, obj); err != nil {
+		return err
+	}
+
 	res, err := transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
 		Config:    config,
 		Method:    "PATCH",
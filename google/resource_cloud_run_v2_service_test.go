@@ -335,6 +335,67 @@ func TestAccCloudRunV2Service_cloudrunv2ServiceGRPCProbesUpdate(t *testing.T) {
 	})
 }
 
+func TestAccCloudRunV2Service_cloudrunv2ServiceRollout(t *testing.T) {
+	t.Parallel()
+
+	context := map[string]interface{}{
+		"random_suffix": RandString(t, 10),
+		"env_value":     "blue",
+	}
+	updated := map[string]interface{}{
+		"random_suffix": context["random_suffix"],
+		"env_value":     "green",
+	}
+
+	VcrTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.AccTestPreCheck(t) },
+		ProtoV5ProviderFactories: ProtoV5ProviderFactories(t),
+		CheckDestroy:             testAccCheckCloudRunV2ServiceDestroyProducer(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudRunV2Service_cloudrunv2ServiceRollout(context),
+			},
+			{
+				ResourceName:            "google_cloud_run_v2_service.default",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"name", "location", "rollout"},
+			},
+			{
+				Config: testAccCloudRunV2Service_cloudrunv2ServiceRollout(updated),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("google_cloud_run_v2_service.default", "traffic_statuses.#", "1"),
+					resource.TestCheckResourceAttr("google_cloud_run_v2_service.default", "traffic_statuses.0.percent", "100"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCloudRunV2Service_cloudrunv2ServiceRollout(context map[string]interface{}) string {
+	return Nprintf(`
+resource "google_cloud_run_v2_service" "default" {
+  name     = "tf-test-cloudrun-service%{random_suffix}"
+  location = "us-central1"
+
+  template {
+    containers {
+      image = "us-docker.pkg.dev/cloudrun/container/hello"
+      env {
+        name  = "COLOR"
+        value = "%{env_value}"
+      }
+    }
+  }
+
+  rollout {
+    steps         = [10, 50]
+    wait_duration = "10s"
+  }
+}
+`, context)
+}
+
 func testAccCheckCloudRunV2ServiceDestroyByNameProducer(t *testing.T, serviceName string) func() {
 	return func() {
 		config := GoogleProviderConfig(t)
@@ -0,0 +1,247 @@
+package google
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"regexp"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-provider-google/google/tpgresource"
+	transport_tpg "github.com/hashicorp/terraform-provider-google/google/transport"
+	"github.com/hashicorp/terraform-provider-google/google/verify"
+)
+
+// cloudSchedulerJobIdRegex matches the ID of a google_cloud_scheduler_job.
+var cloudSchedulerJobIdRegex = regexp.MustCompile("^projects/([^/]+)/locations/[^/]+/jobs/[^/]+$")
+
+func ResourceCloudSchedulerJobRun() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceCloudSchedulerJobRunCreate,
+		Read:   resourceCloudSchedulerJobRunRead,
+		Delete: resourceCloudSchedulerJobRunDelete,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(20 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"job": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: verify.ValidateRegexp(cloudSchedulerJobIdRegex.String()),
+				Description:  `The ID of the job to run, in the format projects/{{project}}/locations/{{region}}/jobs/{{name}}.`,
+			},
+
+			"keepers": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: `Arbitrary map of values that, when changed, runs the job again.`,
+			},
+
+			"attempt_time": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The time the run of the job was attempted.`,
+			},
+
+			"status_code": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: `The google.rpc.Code of the response of the target, 0 (OK) when the run succeeded.`,
+			},
+
+			"status_message": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The message of the response of the target, if the run failed.`,
+			},
+		},
+		UseJSONNumber: true,
+	}
+}
+
+func resourceCloudSchedulerJobRunCreate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	job := d.Get("job").(string)
+	project := cloudSchedulerJobIdRegex.FindStringSubmatch(job)[1]
+	url := config.CloudSchedulerBasePath + job
+
+	getJob := func() (map[string]interface{}, error) {
+		return transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
+			Config:    config,
+			Method:    "GET",
+			Project:   project,
+			RawURL:    url,
+			UserAgent: userAgent,
+		})
+	}
+
+	// The run is asynchronous, so the attempt it makes is told apart from the
+	// ones made before by its lastAttemptTime, and its result by its status.
+	before, err := getJob()
+	if err != nil {
+		return fmt.Errorf("Error reading Job %s: %s", job, err)
+	}
+
+	log.Printf("[DEBUG] Running Job %s", job)
+	_, err = transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
+		Config:    config,
+		Method:    "POST",
+		Project:   project,
+		RawURL:    url + ":run",
+		UserAgent: userAgent,
+		Body:      map[string]interface{}{},
+		Timeout:   d.Timeout(schema.TimeoutCreate),
+	})
+	if err != nil {
+		return fmt.Errorf("Error running Job %s: %s", job, err)
+	}
+
+	var attempted map[string]interface{}
+	pollRead := func() (map[string]interface{}, error) {
+		res, err := getJob()
+		if err == nil {
+			attempted = res
+		}
+		return res, err
+	}
+	err = transport_tpg.PollingWaitTime(pollRead, cloudSchedulerJobRunPollCheck(before, time.Now), "Running Job", d.Timeout(schema.TimeoutCreate), 1)
+	if err != nil {
+		return fmt.Errorf("Error waiting for Job %s to run: %s", job, err)
+	}
+
+	attemptTime, _ := attempted["lastAttemptTime"].(string)
+	d.SetId(fmt.Sprintf("%s/runs/%s", job, attemptTime))
+	if err := d.Set("attempt_time", attemptTime); err != nil {
+		return fmt.Errorf("Error setting attempt_time: %s", err)
+	}
+
+	code, message := flattenCloudSchedulerJobRunStatus(attempted["status"])
+	if err := d.Set("status_code", code); err != nil {
+		return fmt.Errorf("Error setting status_code: %s", err)
+	}
+	if err := d.Set("status_message", message); err != nil {
+		return fmt.Errorf("Error setting status_message: %s", err)
+	}
+	if code != 0 {
+		// The attempt is kept in state, so that it's tainted and run again on
+		// the next apply.
+		return fmt.Errorf("Job %s failed with status %d: %s", job, code, message)
+	}
+
+	log.Printf("[DEBUG] Finished running Job %s at %s", job, attemptTime)
+	return resourceCloudSchedulerJobRunRead(d, meta)
+}
+
+// cloudSchedulerJobRunDefaultAttemptDeadline is the attemptDeadline of jobs
+// that don't set one.
+const cloudSchedulerJobRunDefaultAttemptDeadline = 3 * time.Minute
+
+// cloudSchedulerJobRunPollCheck waits for the job to record the result of an
+// attempt made after the one in before. lastAttemptTime is set when an attempt
+// starts, while status still holds the result of the previous attempt, so the
+// new attempt is only done once its status differs from the previous one, or
+// once its attemptDeadline has passed, after which it can't be running anymore.
+func cloudSchedulerJobRunPollCheck(before map[string]interface{}, now func() time.Time) transport_tpg.PollCheckResponseFunc {
+	previousAttempt, _ := before["lastAttemptTime"].(string)
+	return func(resp map[string]interface{}, respErr error) transport_tpg.PollResult {
+		if respErr != nil {
+			return transport_tpg.ErrorPollResult(respErr)
+		}
+		attempt, _ := resp["lastAttemptTime"].(string)
+		if attempt == "" {
+			return transport_tpg.PendingStatusPollResult("not attempted")
+		}
+		attemptTime, err := time.Parse(time.RFC3339Nano, attempt)
+		if err != nil {
+			return transport_tpg.ErrorPollResult(fmt.Errorf("Error parsing lastAttemptTime %q: %s", attempt, err))
+		}
+		if previousAttempt != "" {
+			previousTime, err := time.Parse(time.RFC3339Nano, previousAttempt)
+			if err != nil {
+				return transport_tpg.ErrorPollResult(fmt.Errorf("Error parsing lastAttemptTime %q: %s", previousAttempt, err))
+			}
+			if !attemptTime.After(previousTime) {
+				return transport_tpg.PendingStatusPollResult("last attempted at " + attempt)
+			}
+		}
+
+		if !reflect.DeepEqual(resp["status"], before["status"]) {
+			return transport_tpg.SuccessPollResult()
+		}
+		deadline := cloudSchedulerJobRunDefaultAttemptDeadline
+		if v, ok := resp["attemptDeadline"].(string); ok && v != "" {
+			deadline, err = time.ParseDuration(v)
+			if err != nil {
+				return transport_tpg.ErrorPollResult(fmt.Errorf("Error parsing attemptDeadline %q: %s", v, err))
+			}
+		}
+		if now().Before(attemptTime.Add(deadline)) {
+			return transport_tpg.PendingStatusPollResult("waiting for the result of the attempt at " + attempt)
+		}
+		return transport_tpg.SuccessPollResult()
+	}
+}
+
+// flattenCloudSchedulerJobRunStatus returns the code and message of the
+// google.rpc.Status of the last attempt of a job. The code is omitted when
+// the attempt succeeded.
+func flattenCloudSchedulerJobRunStatus(v interface{}) (int, string) {
+	status, ok := v.(map[string]interface{})
+	if !ok {
+		return 0, ""
+	}
+	message, _ := status["message"].(string)
+	var code int64
+	switch c := status["code"].(type) {
+	case json.Number:
+		code, _ = c.Int64()
+	case float64:
+		code = int64(c)
+	}
+	return int(code), message
+}
+
+func resourceCloudSchedulerJobRunRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	// Later attempts of the job, scheduled or not, don't change the run, so
+	// only the job is checked to still exist.
+	job := d.Get("job").(string)
+	_, err = transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
+		Config:    config,
+		Method:    "GET",
+		Project:   cloudSchedulerJobIdRegex.FindStringSubmatch(job)[1],
+		RawURL:    config.CloudSchedulerBasePath + job,
+		UserAgent: userAgent,
+	})
+	if err != nil {
+		return transport_tpg.HandleNotFoundError(err, d, fmt.Sprintf("CloudSchedulerJobRun %q", d.Id()))
+	}
+
+	return nil
+}
+
+func resourceCloudSchedulerJobRunDelete(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[WARNING] CloudScheduler JobRun resources"+
+		" cannot be deleted from Google Cloud. The resource %s will be removed from Terraform"+
+		" state, but will still be present on Google Cloud.", d.Id())
+	d.SetId("")
+
+	return nil
+}
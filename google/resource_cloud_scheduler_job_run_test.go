@@ -0,0 +1,157 @@
+package google
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+
+	"github.com/hashicorp/terraform-provider-google/google/acctest"
+)
+
+func TestCloudSchedulerJobRunPollCheck(t *testing.T) {
+	t.Parallel()
+
+	now := func() time.Time { return time.Date(2023, 5, 1, 10, 1, 0, 0, time.UTC) }
+	failed := map[string]interface{}{"code": json.Number("5"), "message": "HTTP 404 Not Found"}
+	cases := map[string]struct {
+		Before  map[string]interface{}
+		Job     map[string]interface{}
+		Err     error
+		Pending bool
+		Error   bool
+	}{
+		"first attempt": {
+			Before: map[string]interface{}{},
+			Job:    map[string]interface{}{"lastAttemptTime": "2023-05-01T10:00:00.123Z", "status": map[string]interface{}{}},
+		},
+		"not attempted": {
+			Before:  map[string]interface{}{},
+			Job:     map[string]interface{}{},
+			Pending: true,
+		},
+		"previous attempt": {
+			Before:  map[string]interface{}{"lastAttemptTime": "2023-05-01T10:00:00.123Z"},
+			Job:     map[string]interface{}{"lastAttemptTime": "2023-05-01T10:00:00.123Z"},
+			Pending: true,
+		},
+		"new attempt without its status yet": {
+			Before:  map[string]interface{}{"lastAttemptTime": "2023-05-01T09:00:00Z", "status": failed},
+			Job:     map[string]interface{}{"lastAttemptTime": "2023-05-01T10:00:30Z", "status": failed},
+			Pending: true,
+		},
+		"new attempt with its status": {
+			Before: map[string]interface{}{"lastAttemptTime": "2023-05-01T09:00:00Z", "status": failed},
+			Job:    map[string]interface{}{"lastAttemptTime": "2023-05-01T10:00:30Z", "status": map[string]interface{}{}},
+		},
+		"new attempt with the same status past its deadline": {
+			Before: map[string]interface{}{"lastAttemptTime": "2023-05-01T09:00:00Z", "status": failed},
+			Job:    map[string]interface{}{"lastAttemptTime": "2023-05-01T10:00:30Z", "status": failed, "attemptDeadline": "15s"},
+		},
+		"request error": {
+			Before: map[string]interface{}{},
+			Err:    fmt.Errorf("quota exceeded"),
+			Error:  true,
+		},
+	}
+
+	for tn, tc := range cases {
+		result := cloudSchedulerJobRunPollCheck(tc.Before, now)(tc.Job, tc.Err)
+		switch {
+		case tc.Error:
+			if result == nil || result.Retryable {
+				t.Errorf("%s: expected an error, got %v", tn, result)
+			}
+		case tc.Pending:
+			if result == nil || !result.Retryable {
+				t.Errorf("%s: expected pending, got %v", tn, result)
+			}
+		default:
+			if result != nil {
+				t.Errorf("%s: expected success, got %v", tn, result.Err)
+			}
+		}
+	}
+}
+
+func TestFlattenCloudSchedulerJobRunStatus(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		Status  interface{}
+		Code    int
+		Message string
+	}{
+		"unset": {},
+		"ok": {
+			Status: map[string]interface{}{},
+		},
+		"not found": {
+			Status:  map[string]interface{}{"code": json.Number("5"), "message": "HTTP 404 Not Found"},
+			Code:    5,
+			Message: "HTTP 404 Not Found",
+		},
+	}
+
+	for tn, tc := range cases {
+		code, message := flattenCloudSchedulerJobRunStatus(tc.Status)
+		if code != tc.Code || message != tc.Message {
+			t.Errorf("%s: got %d %q, expected %d %q", tn, code, message, tc.Code, tc.Message)
+		}
+	}
+}
+
+func TestAccCloudSchedulerJobRun_basic(t *testing.T) {
+	t.Parallel()
+
+	context := map[string]interface{}{
+		"random_suffix": RandString(t, 10),
+	}
+
+	VcrTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.AccTestPreCheck(t) },
+		ProtoV5ProviderFactories: ProtoV5ProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudSchedulerJobRun_basic(context, "1"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("google_cloud_scheduler_job_run.run", "status_code", "0"),
+					resource.TestCheckResourceAttrSet("google_cloud_scheduler_job_run.run", "attempt_time"),
+				),
+			},
+			{
+				Config: testAccCloudSchedulerJobRun_basic(context, "2"),
+				Check:  resource.TestCheckResourceAttr("google_cloud_scheduler_job_run.run", "status_code", "0"),
+			},
+		},
+	})
+}
+
+func testAccCloudSchedulerJobRun_basic(context map[string]interface{}, run string) string {
+	context["run"] = run
+	return Nprintf(`
+resource "google_pubsub_topic" "topic" {
+  name = "tf-test-job-topic%{random_suffix}"
+}
+
+resource "google_cloud_scheduler_job" "job" {
+  name     = "tf-test-test-job%{random_suffix}"
+  schedule = "0 0 1 1 *"
+
+  pubsub_target {
+    topic_name = google_pubsub_topic.topic.id
+    data       = base64encode("maintenance")
+  }
+}
+
+resource "google_cloud_scheduler_job_run" "run" {
+  job = google_cloud_scheduler_job.job.id
+
+  keepers = {
+    run = "%{run}"
+  }
+}
+`, context)
+}
@@ -28,6 +28,7 @@ import (
 
 	"github.com/hashicorp/terraform-provider-google/google/tpgresource"
 	transport_tpg "github.com/hashicorp/terraform-provider-google/google/transport"
+	"github.com/hashicorp/terraform-provider-google/google/verify"
 )
 
 func suppressOmittedMaxDuration(_, old, new string, _ *schema.ResourceData) bool {
@@ -55,6 +56,8 @@ func ResourceCloudTasksQueue() *schema.Resource {
 			Delete: schema.DefaultTimeout(20 * time.Minute),
 		},
 
+		CustomizeDiff: cloudTasksQueueStateCustomizeDiff,
+
 		Schema: map[string]*schema.Schema{
 			"location": {
 				Type:        schema.TypeString,
@@ -233,6 +236,23 @@ default and means that no operations are logged.`,
 					},
 				},
 			},
+			"state": {
+				Type:         schema.TypeString,
+				Computed:     true,
+				Optional:     true,
+				ValidateFunc: verify.ValidateEnum([]string{"RUNNING", "PAUSED", "DISABLED", ""}),
+				Description: `The state of the queue. Tasks are not dispatched from a PAUSED queue, but can
+still be added to it. A queue is DISABLED when its App Engine application is
+disabled: DISABLED can only be read or imported, and a disabled queue can't be
+paused or resumed. Possible values: ["RUNNING", "PAUSED", "DISABLED"]`,
+			},
+			"purge_keepers": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Description: `Arbitrary map of values that, when changed, purges all tasks from the queue.
+Purging is not done when the queue is created.`,
+			},
 			"project": {
 				Type:     schema.TypeString,
 				Optional: true,
@@ -324,6 +344,10 @@ func resourceCloudTasksQueueCreate(d *schema.ResourceData, meta interface{}) err
 
 	log.Printf("[DEBUG] Finished creating Queue %q: %#v", d.Id(), res)
 
+	if err := cloudTasksQueuePostCreateSetState(d, config, billingProject, userAgent); err != nil {
+		return err
+	}
+
 	return resourceCloudTasksQueueRead(d, meta)
 }
 
@@ -382,6 +406,9 @@ func resourceCloudTasksQueueRead(d *schema.ResourceData, meta interface{}) error
 	if err := d.Set("stackdriver_logging_config", flattenCloudTasksQueueStackdriverLoggingConfig(res["stackdriverLoggingConfig"], d, config)); err != nil {
 		return fmt.Errorf("Error reading Queue: %s", err)
 	}
+	if err := d.Set("state", flattenCloudTasksQueueState(res["state"], d, config)); err != nil {
+		return fmt.Errorf("Error reading Queue: %s", err)
+	}
 
 	return nil
 }
@@ -462,20 +489,26 @@ func resourceCloudTasksQueueUpdate(d *schema.ResourceData, meta interface{}) err
 		billingProject = bp
 	}
 
-	res, err := transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
-		Config:    config,
-		Method:    "PATCH",
-		Project:   billingProject,
-		RawURL:    url,
-		UserAgent: userAgent,
-		Body:      obj,
-		Timeout:   d.Timeout(schema.TimeoutUpdate),
-	})
+	if len(updateMask) > 0 {
+		res, err := transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
+			Config:    config,
+			Method:    "PATCH",
+			Project:   billingProject,
+			RawURL:    url,
+			UserAgent: userAgent,
+			Body:      obj,
+			Timeout:   d.Timeout(schema.TimeoutUpdate),
+		})
+
+		if err != nil {
+			return fmt.Errorf("Error updating Queue %q: %s", d.Id(), err)
+		} else {
+			log.Printf("[DEBUG] Finished updating Queue %q: %#v", d.Id(), res)
+		}
 
-	if err != nil {
-		return fmt.Errorf("Error updating Queue %q: %s", d.Id(), err)
-	} else {
-		log.Printf("[DEBUG] Finished updating Queue %q: %#v", d.Id(), res)
+	}
+	if err := cloudTasksQueuePostUpdate(d, config, billingProject, userAgent); err != nil {
+		return err
 	}
 
 	return resourceCloudTasksQueueRead(d, meta)
@@ -711,6 +744,10 @@ func flattenCloudTasksQueueStackdriverLoggingConfigSamplingRatio(v interface{},
 	return v
 }
 
+func flattenCloudTasksQueueState(v interface{}, d *schema.ResourceData, config *transport_tpg.Config) interface{} {
+	return v
+}
+
 func expandCloudTasksQueueName(v interface{}, d tpgresource.TerraformResourceData, config *transport_tpg.Config) (interface{}, error) {
 	return tpgresource.ReplaceVars(d, config, "projects/{{project}}/locations/{{location}}/queues/{{name}}")
 }
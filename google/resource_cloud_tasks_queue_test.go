@@ -90,6 +90,37 @@ func TestAccCloudTasksQueue_MaxRetryDiffSuppress0s(t *testing.T) {
 	})
 }
 
+func TestAccCloudTasksQueue_state(t *testing.T) {
+	t.Parallel()
+
+	name := "cloudtasksqueuetest-" + RandString(t, 10)
+
+	VcrTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.AccTestPreCheck(t) },
+		ProtoV5ProviderFactories: ProtoV5ProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudTasksQueue_state(name, "PAUSED", "1"),
+				Check:  resource.TestCheckResourceAttr("google_cloud_tasks_queue.default", "state", "PAUSED"),
+			},
+			{
+				ResourceName:            "google_cloud_tasks_queue.default",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"purge_keepers"},
+			},
+			{
+				Config: testAccCloudTasksQueue_state(name, "PAUSED", "2"),
+				Check:  resource.TestCheckResourceAttr("google_cloud_tasks_queue.default", "state", "PAUSED"),
+			},
+			{
+				Config: testAccCloudTasksQueue_state(name, "RUNNING", "2"),
+				Check:  resource.TestCheckResourceAttr("google_cloud_tasks_queue.default", "state", "RUNNING"),
+			},
+		},
+	})
+}
+
 func testAccCloudTasksQueue_basic(name string) string {
 	return fmt.Sprintf(`
 resource "google_cloud_tasks_queue" "default" {
@@ -184,3 +215,17 @@ func testAccCloudtasksQueueMaxRetry0s(cloudTaskName string) string {
 	}
 `, cloudTaskName)
 }
+
+func testAccCloudTasksQueue_state(name, state, purge string) string {
+	return fmt.Sprintf(`
+resource "google_cloud_tasks_queue" "default" {
+  name     = "%s"
+  location = "us-central1"
+  state    = "%s"
+
+  purge_keepers = {
+    maintenance = "%s"
+  }
+}
+`, name, state, purge)
+}
@@ -0,0 +1,403 @@
+package google
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-provider-google/google/tpgresource"
+	transport_tpg "github.com/hashicorp/terraform-provider-google/google/transport"
+)
+
+// cloudBuildBuildFinishedStatuses are the statuses of a build that is done,
+// successfully or not.
+var cloudBuildBuildFinishedStatuses = []string{"SUCCESS", "FAILURE", "INTERNAL_ERROR", "TIMEOUT", "CANCELLED", "EXPIRED"}
+
+func ResourceCloudBuildBuild() *schema.Resource {
+	build := cloudBuildBuildForceNewSchema(ResourceCloudBuildTrigger().Schema["build"])
+	build.Description = `The build to run. Either build or trigger must be provided.`
+	build.ExactlyOneOf = []string{"build", "trigger"}
+
+	return &schema.Resource{
+		Create: resourceCloudBuildBuildCreate,
+		Read:   resourceCloudBuildBuildRead,
+		Delete: resourceCloudBuildBuildDelete,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(30 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"build": build,
+
+			"trigger": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{"build", "trigger"},
+				Description:  `The ID or name of the trigger to run. Either build or trigger must be provided.`,
+			},
+
+			"substitutions": {
+				Type:          schema.TypeMap,
+				Optional:      true,
+				ForceNew:      true,
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				ConflictsWith: []string{"build"},
+				Description:   `Substitutions for the trigger run, overriding those of the trigger.`,
+			},
+
+			"branch_name": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"build", "tag_name", "commit_sha"},
+				Description:   `The branch to build with the trigger, instead of the trigger's.`,
+			},
+
+			"tag_name": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"build", "branch_name", "commit_sha"},
+				Description:   `The tag to build with the trigger, instead of the trigger's branch.`,
+			},
+
+			"commit_sha": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"build", "branch_name", "tag_name"},
+				Description:   `The commit to build with the trigger, instead of the trigger's branch.`,
+			},
+
+			"location": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "global",
+				Description: `The location to run the build in, which must be the one of the trigger.`,
+			},
+
+			"keepers": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Description: `Arbitrary map of values that, when changed, will run the build again.`,
+			},
+
+			"project": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"build_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The ID of the build.`,
+			},
+
+			"status": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The status of the build.`,
+			},
+
+			"log_url": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The URL of the build's logs in the Google Cloud console.`,
+			},
+
+			"built_images": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: `The container images pushed by the build.`,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: `The name of the image, as given in the build's images.`,
+						},
+						"digest": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: `The digest of the pushed image.`,
+						},
+						"image": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: `The name of the image pinned to its digest, as name@digest.`,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// cloudBuildBuildForceNewSchema returns a copy of s where every field that can
+// be set forces a new resource, as a build can't be updated once it has run.
+func cloudBuildBuildForceNewSchema(s *schema.Schema) *schema.Schema {
+	c := *s
+	if c.Computed && !c.Optional {
+		return &c
+	}
+	c.ForceNew = true
+	if elem, ok := c.Elem.(*schema.Resource); ok {
+		nested := make(map[string]*schema.Schema, len(elem.Schema))
+		for k, v := range elem.Schema {
+			nested[k] = cloudBuildBuildForceNewSchema(v)
+		}
+		c.Elem = &schema.Resource{Schema: nested}
+	}
+	return &c
+}
+
+func resourceCloudBuildBuildCreate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	project, err := tpgresource.GetProject(d, config)
+	if err != nil {
+		return fmt.Errorf("Error fetching project for Build: %s", err)
+	}
+	location := d.Get("location").(string)
+	parent := fmt.Sprintf("projects/%s/locations/%s", project, location)
+
+	var url string
+	var obj map[string]interface{}
+	if trigger, ok := d.GetOk("trigger"); ok {
+		url = fmt.Sprintf("%s%s/triggers/%s:run", config.CloudBuildBasePath, parent, trigger)
+		obj = expandCloudBuildBuildTriggerRun(d, parent)
+	} else {
+		build, err := expandCloudBuildTriggerBuild(d.Get("build"), d, config)
+		if err != nil {
+			return err
+		}
+		url = fmt.Sprintf("%s%s/builds", config.CloudBuildBasePath, parent)
+		obj = build.(map[string]interface{})
+	}
+
+	log.Printf("[DEBUG] Starting new Build: %#v", obj)
+	op, err := transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
+		Config:    config,
+		Method:    "POST",
+		Project:   project,
+		RawURL:    url,
+		UserAgent: userAgent,
+		Body:      obj,
+		Timeout:   d.Timeout(schema.TimeoutCreate),
+	})
+	if err != nil {
+		return fmt.Errorf("Error starting Build: %s", err)
+	}
+
+	buildId, err := cloudBuildOperationBuildId(op)
+	if err != nil {
+		return err
+	}
+
+	waitErr := CloudBuildOperationWaitTime(config, op, project, fmt.Sprintf("Waiting for Build %s", buildId), userAgent, d.Timeout(schema.TimeoutCreate))
+
+	// A failed build also fails its operation, so the build is read either
+	// way to report why it failed.
+	buildUrl := fmt.Sprintf("%s%s/builds/%s", config.CloudBuildBasePath, parent, buildId)
+	build, err := transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
+		Config:    config,
+		Method:    "GET",
+		Project:   project,
+		RawURL:    buildUrl,
+		UserAgent: userAgent,
+	})
+	if err != nil {
+		if waitErr != nil {
+			return fmt.Errorf("Error waiting for Build %s: %s", buildId, waitErr)
+		}
+		return fmt.Errorf("Error reading Build %s: %s", buildId, err)
+	}
+
+	status, _ := build["status"].(string)
+	if !tpgresource.StringInSlice(cloudBuildBuildFinishedStatuses, status) {
+		// The wait timed out. The build is cancelled rather than left running
+		// untracked, as the next apply starts a new one.
+		_, err := transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
+			Config:    config,
+			Method:    "POST",
+			Project:   project,
+			RawURL:    buildUrl + ":cancel",
+			UserAgent: userAgent,
+			Body:      map[string]interface{}{},
+		})
+		if err != nil {
+			log.Printf("[WARN] Error cancelling Build %s: %s", buildId, err)
+		}
+		return fmt.Errorf("Error waiting for Build %s, which was cancelled: %s. Logs: %s", buildId, waitErr, build["logUrl"])
+	}
+	if status != "SUCCESS" {
+		return cloudBuildBuildFailure(build)
+	}
+
+	d.SetId(fmt.Sprintf("%s/builds/%s", parent, buildId))
+	log.Printf("[DEBUG] Finished running Build %q", d.Id())
+
+	return flattenCloudBuildBuild(d, build)
+}
+
+// expandCloudBuildBuildTriggerRun returns the body of a request running the
+// trigger with the resource's source and substitutions.
+func expandCloudBuildBuildTriggerRun(d *schema.ResourceData, parent string) map[string]interface{} {
+	source := map[string]interface{}{}
+	for field, key := range map[string]string{"branch_name": "branchName", "tag_name": "tagName", "commit_sha": "commitSha"} {
+		if v, ok := d.GetOk(field); ok {
+			source[key] = v
+		}
+	}
+	if v, ok := d.GetOk("substitutions"); ok {
+		source["substitutions"] = v
+	}
+
+	obj := map[string]interface{}{
+		"name": fmt.Sprintf("%s/triggers/%s", parent, d.Get("trigger")),
+	}
+	if len(source) > 0 {
+		obj["source"] = source
+	}
+	return obj
+}
+
+// cloudBuildOperationBuildId returns the ID of the build started by op.
+func cloudBuildOperationBuildId(op map[string]interface{}) (string, error) {
+	if metadata, ok := op["metadata"].(map[string]interface{}); ok {
+		if build, ok := metadata["build"].(map[string]interface{}); ok {
+			if id, ok := build["id"].(string); ok && id != "" {
+				return id, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("Error starting Build: no build ID in operation %v", op["name"])
+}
+
+// cloudBuildBuildFailure describes why build failed, with the first step that
+// didn't succeed and the URL of the logs.
+func cloudBuildBuildFailure(build map[string]interface{}) error {
+	msg := fmt.Sprintf("Build %v finished with status %v", build["id"], build["status"])
+
+	steps, _ := build["steps"].([]interface{})
+	for i, raw := range steps {
+		step, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		status, _ := step["status"].(string)
+		if status == "" || status == "SUCCESS" || status == "QUEUED" {
+			continue
+		}
+		label, _ := step["id"].(string)
+		if label == "" {
+			label, _ = step["name"].(string)
+		}
+		msg += fmt.Sprintf(": step %d (%s) finished with status %s", i, label, status)
+		break
+	}
+
+	if info, ok := build["failureInfo"].(map[string]interface{}); ok && info["detail"] != nil {
+		msg += fmt.Sprintf(": %v", info["detail"])
+	} else if detail, ok := build["statusDetail"].(string); ok && detail != "" {
+		msg += fmt.Sprintf(": %s", detail)
+	}
+
+	if logUrl, ok := build["logUrl"].(string); ok && logUrl != "" {
+		msg += fmt.Sprintf(". Logs: %s", logUrl)
+	}
+	return fmt.Errorf("%s", msg)
+}
+
+func flattenCloudBuildBuild(d *schema.ResourceData, build map[string]interface{}) error {
+	if err := d.Set("build_id", build["id"]); err != nil {
+		return fmt.Errorf("Error setting build_id: %s", err)
+	}
+	if err := d.Set("status", build["status"]); err != nil {
+		return fmt.Errorf("Error setting status: %s", err)
+	}
+	if err := d.Set("log_url", build["logUrl"]); err != nil {
+		return fmt.Errorf("Error setting log_url: %s", err)
+	}
+	if err := d.Set("built_images", flattenCloudBuildBuildImages(build)); err != nil {
+		return fmt.Errorf("Error setting built_images: %s", err)
+	}
+	return nil
+}
+
+func flattenCloudBuildBuildImages(build map[string]interface{}) []interface{} {
+	results, _ := build["results"].(map[string]interface{})
+	images, _ := results["images"].([]interface{})
+
+	transformed := make([]interface{}, 0, len(images))
+	for _, raw := range images {
+		image, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := image["name"].(string)
+		digest, _ := image["digest"].(string)
+		// Images pushed with a tag are reported with it, which a digest
+		// replaces.
+		repository := name
+		if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+			repository = name[:i]
+		}
+		transformed = append(transformed, map[string]interface{}{
+			"name":   name,
+			"digest": digest,
+			"image":  fmt.Sprintf("%s@%s", repository, digest),
+		})
+	}
+	return transformed
+}
+
+func resourceCloudBuildBuildRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	project, err := tpgresource.GetProject(d, config)
+	if err != nil {
+		return fmt.Errorf("Error fetching project for Build: %s", err)
+	}
+
+	build, err := transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
+		Config:    config,
+		Method:    "GET",
+		Project:   project,
+		RawURL:    config.CloudBuildBasePath + d.Id(),
+		UserAgent: userAgent,
+	})
+	if err != nil {
+		return transport_tpg.HandleNotFoundError(err, d, fmt.Sprintf("CloudBuildBuild %q", d.Id()))
+	}
+
+	if err := d.Set("project", project); err != nil {
+		return fmt.Errorf("Error setting project: %s", err)
+	}
+	return flattenCloudBuildBuild(d, build)
+}
+
+func resourceCloudBuildBuildDelete(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[WARNING] CloudBuild Build resources"+
+		" cannot be deleted from Google Cloud. The resource %s will be removed from Terraform"+
+		" state, but will still be present on Google Cloud.", d.Id())
+	d.SetId("")
+
+	return nil
+}
@@ -0,0 +1,192 @@
+package google
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/hashicorp/terraform-provider-google/google/acctest"
+)
+
+func TestResourceCloudBuildBuild_forceNew(t *testing.T) {
+	t.Parallel()
+
+	var check func(path string, s *schema.Schema)
+	check = func(path string, s *schema.Schema) {
+		if s.Computed && !s.Optional {
+			return
+		}
+		if !s.ForceNew {
+			t.Errorf("%s: expected ForceNew", path)
+		}
+		if elem, ok := s.Elem.(*schema.Resource); ok {
+			for k, v := range elem.Schema {
+				check(path+"."+k, v)
+			}
+		}
+	}
+	for k, v := range ResourceCloudBuildBuild().Schema {
+		check(k, v)
+	}
+
+	if ResourceCloudBuildTrigger().Schema["build"].Elem.(*schema.Resource).Schema["step"].ForceNew {
+		t.Errorf("expected the schema of google_cloudbuild_trigger to be left unchanged")
+	}
+}
+
+func TestCloudBuildBuildFailure(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		Build    map[string]interface{}
+		Expected string
+	}{
+		"failing step": {
+			Build: map[string]interface{}{
+				"id":     "1234",
+				"status": "FAILURE",
+				"steps": []interface{}{
+					map[string]interface{}{"name": "ubuntu", "status": "SUCCESS"},
+					map[string]interface{}{"id": "test", "name": "golang", "status": "FAILURE"},
+					map[string]interface{}{"name": "gcr.io/cloud-builders/docker", "status": "QUEUED"},
+				},
+				"failureInfo": map[string]interface{}{"type": "USER_BUILD_STEP", "detail": `Build step failure: build step 1 "golang" failed: step exited with non-zero status: 1`},
+				"logUrl":      "https://console.cloud.google.com/cloud-build/builds/1234",
+			},
+			Expected: `Build 1234 finished with status FAILURE: step 1 (test) finished with status FAILURE: Build step failure: build step 1 "golang" failed: step exited with non-zero status: 1. Logs: https://console.cloud.google.com/cloud-build/builds/1234`,
+		},
+		"timeout": {
+			Build: map[string]interface{}{
+				"id":           "1234",
+				"status":       "TIMEOUT",
+				"statusDetail": "build timed out",
+				"steps": []interface{}{
+					map[string]interface{}{"name": "ubuntu", "status": "TIMEOUT"},
+				},
+			},
+			Expected: "Build 1234 finished with status TIMEOUT: step 0 (ubuntu) finished with status TIMEOUT: build timed out",
+		},
+		"no steps": {
+			Build: map[string]interface{}{
+				"id":     "1234",
+				"status": "EXPIRED",
+				"logUrl": "https://console.cloud.google.com/cloud-build/builds/1234",
+			},
+			Expected: "Build 1234 finished with status EXPIRED. Logs: https://console.cloud.google.com/cloud-build/builds/1234",
+		},
+	}
+
+	for tn, tc := range cases {
+		if got := cloudBuildBuildFailure(tc.Build).Error(); got != tc.Expected {
+			t.Errorf("%s: got %q, expected %q", tn, got, tc.Expected)
+		}
+	}
+}
+
+func TestFlattenCloudBuildBuildImages(t *testing.T) {
+	t.Parallel()
+
+	build := map[string]interface{}{
+		"results": map[string]interface{}{
+			"images": []interface{}{
+				map[string]interface{}{"name": "us-docker.pkg.dev/my-project/repo/app:v1", "digest": "sha256:abc"},
+				map[string]interface{}{"name": "localhost:5000/app", "digest": "sha256:def"},
+			},
+		},
+	}
+	expected := []interface{}{
+		map[string]interface{}{"name": "us-docker.pkg.dev/my-project/repo/app:v1", "digest": "sha256:abc", "image": "us-docker.pkg.dev/my-project/repo/app@sha256:abc"},
+		map[string]interface{}{"name": "localhost:5000/app", "digest": "sha256:def", "image": "localhost:5000/app@sha256:def"},
+	}
+
+	if got := flattenCloudBuildBuildImages(build); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+	if got := flattenCloudBuildBuildImages(map[string]interface{}{}); len(got) != 0 {
+		t.Errorf("expected no images for a build without results, got %v", got)
+	}
+}
+
+func TestAccCloudBuildBuild_basic(t *testing.T) {
+	t.Parallel()
+
+	context := map[string]interface{}{
+		"random_suffix": RandString(t, 10),
+	}
+
+	VcrTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.AccTestPreCheck(t) },
+		ProtoV5ProviderFactories: ProtoV5ProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudBuildBuild_basic(context),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("google_cloudbuild_build.build", "status", "SUCCESS"),
+					resource.TestCheckResourceAttr("google_cloudbuild_build.build", "built_images.#", "1"),
+					resource.TestMatchResourceAttr("google_cloudbuild_build.build", "built_images.0.image", regexp.MustCompile("@sha256:")),
+				),
+			},
+		},
+	})
+}
+
+func TestAccCloudBuildBuild_failure(t *testing.T) {
+	t.Parallel()
+
+	VcrTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.AccTestPreCheck(t) },
+		ProtoV5ProviderFactories: ProtoV5ProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccCloudBuildBuild_failure(),
+				ExpectError: regexp.MustCompile(`step 1 \(fail\) finished with status FAILURE.*Logs: https://`),
+			},
+		},
+	})
+}
+
+func testAccCloudBuildBuild_basic(context map[string]interface{}) string {
+	return Nprintf(`
+resource "google_artifact_registry_repository" "repo" {
+  location      = "us-central1"
+  repository_id = "tf-test-repo-%{random_suffix}"
+  format        = "DOCKER"
+}
+
+resource "google_cloudbuild_build" "build" {
+  build {
+    step {
+      name = "gcr.io/cloud-builders/docker"
+      args = ["pull", "busybox"]
+    }
+    step {
+      name = "gcr.io/cloud-builders/docker"
+      args = ["tag", "busybox", "us-central1-docker.pkg.dev/${google_artifact_registry_repository.repo.project}/${google_artifact_registry_repository.repo.repository_id}/busybox:latest"]
+    }
+    images = ["us-central1-docker.pkg.dev/${google_artifact_registry_repository.repo.project}/${google_artifact_registry_repository.repo.repository_id}/busybox:latest"]
+  }
+}
+`, context)
+}
+
+func testAccCloudBuildBuild_failure() string {
+	return `
+resource "google_cloudbuild_build" "build" {
+  build {
+    step {
+      name = "ubuntu"
+      args = ["echo", "hello"]
+    }
+    step {
+      id         = "fail"
+      name       = "ubuntu"
+      entrypoint = "bash"
+      args       = ["-c", "exit 1"]
+    }
+  }
+}
+`
+}
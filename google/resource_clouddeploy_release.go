@@ -0,0 +1,407 @@
+package google
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-provider-google/google/tpgresource"
+	transport_tpg "github.com/hashicorp/terraform-provider-google/google/transport"
+	"github.com/hashicorp/terraform-provider-google/google/verify"
+)
+
+func ResourceClouddeployRelease() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceClouddeployReleaseCreate,
+		Read:   resourceClouddeployReleaseRead,
+		Delete: resourceClouddeployReleaseDelete,
+
+		Importer: &schema.ResourceImporter{
+			State: resourceClouddeployReleaseImport,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(20 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: verify.ValidateRegexp(`^[a-z]([a-z0-9-]{0,61}[a-z0-9])?$`),
+				Description:  `The name of the release.`,
+			},
+
+			"delivery_pipeline": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: `The name of the delivery pipeline of the release.`,
+			},
+
+			"location": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: `The location of the delivery pipeline.`,
+			},
+
+			"skaffold_config_uri": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: `The Cloud Storage URI of the tar.gz archive of the Skaffold configuration and the manifests to render.`,
+			},
+
+			"skaffold_config_path": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: `The path of the Skaffold configuration in the archive. Defaults to skaffold.yaml.`,
+			},
+
+			"skaffold_version": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: `The Skaffold version to render the manifests with. Defaults to the default version of Cloud Deploy.`,
+			},
+
+			"build_artifacts": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				ForceNew:    true,
+				Description: `The images to substitute into the manifests, by the image names used in the Skaffold configuration.`,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"image": {
+							Type:        schema.TypeString,
+							Required:    true,
+							ForceNew:    true,
+							Description: `The image name in the Skaffold configuration.`,
+						},
+						"tag": {
+							Type:        schema.TypeString,
+							Required:    true,
+							ForceNew:    true,
+							Description: `The image to deploy, preferably pinned to a digest.`,
+						},
+					},
+				},
+			},
+
+			"deploy_parameters": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: `Parameters substituted into the manifests when they're rendered.`,
+			},
+
+			"description": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: `A description of the release.`,
+			},
+
+			"annotations": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: `User annotations of the release.`,
+			},
+
+			"labels": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: `Labels of the release.`,
+			},
+
+			"project": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"uid": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The unique identifier of the release.`,
+			},
+
+			"create_time": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The time the release was created.`,
+			},
+
+			"render_state": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The state of the rendering of the manifests for every target.`,
+			},
+
+			"target_ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: `The targets of the delivery pipeline at the time of the release, in the order of its stages.`,
+			},
+
+			"abandoned": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: `Whether the release was abandoned, which prevents new rollouts of it.`,
+			},
+		},
+	}
+}
+
+func resourceClouddeployReleaseCreate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	project, err := tpgresource.GetProject(d, config)
+	if err != nil {
+		return fmt.Errorf("Error fetching project for Release: %s", err)
+	}
+
+	obj := make(map[string]interface{})
+	for field, key := range map[string]string{
+		"description":          "description",
+		"annotations":          "annotations",
+		"labels":               "labels",
+		"skaffold_config_uri":  "skaffoldConfigUri",
+		"skaffold_config_path": "skaffoldConfigPath",
+		"skaffold_version":     "skaffoldVersion",
+		"deploy_parameters":    "deployParameters",
+	} {
+		if v, ok := d.GetOk(field); ok {
+			obj[key] = v
+		}
+	}
+	if v, ok := d.GetOk("build_artifacts"); ok {
+		obj["buildArtifacts"] = v
+	}
+
+	parent := fmt.Sprintf("projects/%s/locations/%s/deliveryPipelines/%s", project, d.Get("location"), d.Get("delivery_pipeline"))
+	url := fmt.Sprintf("%s%s/releases?releaseId=%s", clouddeployBasePath(config), parent, d.Get("name"))
+
+	log.Printf("[DEBUG] Creating new Release: %#v", obj)
+	op, err := transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
+		Config:    config,
+		Method:    "POST",
+		Project:   project,
+		RawURL:    url,
+		UserAgent: userAgent,
+		Body:      obj,
+		Timeout:   d.Timeout(schema.TimeoutCreate),
+	})
+	if err != nil {
+		return fmt.Errorf("Error creating Release: %s", err)
+	}
+
+	id := fmt.Sprintf("%s/releases/%s", parent, d.Get("name"))
+	if err := ClouddeployOperationWaitTime(config, op, project, "Creating Release", userAgent, d.Timeout(schema.TimeoutCreate)); err != nil {
+		return fmt.Errorf("Error waiting to create Release: %s", err)
+	}
+
+	// The manifests are rendered for every target once the release is
+	// created, which rollouts need to have succeeded.
+	pollRead := func() (map[string]interface{}, error) {
+		return transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
+			Config:    config,
+			Method:    "GET",
+			Project:   project,
+			RawURL:    clouddeployBasePath(config) + id,
+			UserAgent: userAgent,
+		})
+	}
+	err = transport_tpg.PollingWaitTime(pollRead, clouddeployReleasePollCheck, "Rendering Release", d.Timeout(schema.TimeoutCreate), 1)
+	if err != nil {
+		return fmt.Errorf("Error waiting for Release %s to render: %s", id, err)
+	}
+
+	d.SetId(id)
+	log.Printf("[DEBUG] Finished creating Release %q", d.Id())
+
+	return resourceClouddeployReleaseRead(d, meta)
+}
+
+func clouddeployReleasePollCheck(resp map[string]interface{}, respErr error) transport_tpg.PollResult {
+	if respErr != nil {
+		return transport_tpg.ErrorPollResult(respErr)
+	}
+	switch state, _ := resp["renderState"].(string); state {
+	case "SUCCEEDED":
+		return transport_tpg.SuccessPollResult()
+	case "FAILED":
+		return transport_tpg.ErrorPollResult(clouddeployReleaseRenderFailure(resp))
+	default:
+		return transport_tpg.PendingStatusPollResult(state)
+	}
+}
+
+// clouddeployReleaseRenderFailure describes why rendering the manifests of
+// release failed, for every target it failed for.
+func clouddeployReleaseRenderFailure(release map[string]interface{}) error {
+	renders, _ := release["targetRenders"].(map[string]interface{})
+	targets := make([]string, 0, len(renders))
+	for target := range renders {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+
+	failures := []string{}
+	for _, target := range targets {
+		render, _ := renders[target].(map[string]interface{})
+		if render["renderingState"] != "FAILED" {
+			continue
+		}
+		failure := fmt.Sprintf("target %s: %v", target, render["failureCause"])
+		if msg, ok := render["failureMessage"].(string); ok && msg != "" {
+			failure += ": " + msg
+		}
+		if build, ok := render["renderingBuild"].(string); ok && build != "" {
+			failure += fmt.Sprintf(" (build %s)", build)
+		}
+		failures = append(failures, failure)
+	}
+	if len(failures) == 0 {
+		return fmt.Errorf("Release %v failed to render", release["name"])
+	}
+	return fmt.Errorf("Release %v failed to render for %s", release["name"], strings.Join(failures, "; "))
+}
+
+func resourceClouddeployReleaseRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	project, err := tpgresource.GetProject(d, config)
+	if err != nil {
+		return fmt.Errorf("Error fetching project for Release: %s", err)
+	}
+
+	res, err := transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
+		Config:    config,
+		Method:    "GET",
+		Project:   project,
+		RawURL:    clouddeployBasePath(config) + d.Id(),
+		UserAgent: userAgent,
+	})
+	if err != nil {
+		return transport_tpg.HandleNotFoundError(err, d, fmt.Sprintf("ClouddeployRelease %q", d.Id()))
+	}
+
+	artifacts := []interface{}{}
+	if raw, ok := res["buildArtifacts"].([]interface{}); ok {
+		for _, a := range raw {
+			artifact, _ := a.(map[string]interface{})
+			artifacts = append(artifacts, map[string]interface{}{"image": artifact["image"], "tag": artifact["tag"]})
+		}
+	}
+
+	if err := d.Set("project", project); err != nil {
+		return fmt.Errorf("Error setting project: %s", err)
+	}
+	if err := d.Set("description", res["description"]); err != nil {
+		return fmt.Errorf("Error setting description: %s", err)
+	}
+	if err := d.Set("annotations", res["annotations"]); err != nil {
+		return fmt.Errorf("Error setting annotations: %s", err)
+	}
+	if err := d.Set("labels", res["labels"]); err != nil {
+		return fmt.Errorf("Error setting labels: %s", err)
+	}
+	if err := d.Set("skaffold_config_uri", res["skaffoldConfigUri"]); err != nil {
+		return fmt.Errorf("Error setting skaffold_config_uri: %s", err)
+	}
+	if err := d.Set("skaffold_config_path", res["skaffoldConfigPath"]); err != nil {
+		return fmt.Errorf("Error setting skaffold_config_path: %s", err)
+	}
+	if err := d.Set("skaffold_version", res["skaffoldVersion"]); err != nil {
+		return fmt.Errorf("Error setting skaffold_version: %s", err)
+	}
+	if err := d.Set("build_artifacts", artifacts); err != nil {
+		return fmt.Errorf("Error setting build_artifacts: %s", err)
+	}
+	if err := d.Set("deploy_parameters", res["deployParameters"]); err != nil {
+		return fmt.Errorf("Error setting deploy_parameters: %s", err)
+	}
+	if err := d.Set("uid", res["uid"]); err != nil {
+		return fmt.Errorf("Error setting uid: %s", err)
+	}
+	if err := d.Set("create_time", res["createTime"]); err != nil {
+		return fmt.Errorf("Error setting create_time: %s", err)
+	}
+	if err := d.Set("render_state", res["renderState"]); err != nil {
+		return fmt.Errorf("Error setting render_state: %s", err)
+	}
+	if err := d.Set("target_ids", flattenClouddeployReleaseTargetIds(res)); err != nil {
+		return fmt.Errorf("Error setting target_ids: %s", err)
+	}
+	if err := d.Set("abandoned", res["abandoned"]); err != nil {
+		return fmt.Errorf("Error setting abandoned: %s", err)
+	}
+	return nil
+}
+
+// flattenClouddeployReleaseTargetIds returns the targets of the stages of the
+// pipeline snapshotted by release, in order.
+func flattenClouddeployReleaseTargetIds(release map[string]interface{}) []interface{} {
+	targets := []interface{}{}
+	pipeline, _ := release["deliveryPipelineSnapshot"].(map[string]interface{})
+	serial, _ := pipeline["serialPipeline"].(map[string]interface{})
+	stages, _ := serial["stages"].([]interface{})
+	for _, raw := range stages {
+		stage, _ := raw.(map[string]interface{})
+		if target, ok := stage["targetId"].(string); ok {
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+func resourceClouddeployReleaseDelete(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[WARNING] Clouddeploy Release resources"+
+		" cannot be deleted from Google Cloud. The resource %s will be removed from Terraform"+
+		" state, but will still be present on Google Cloud.", d.Id())
+	d.SetId("")
+
+	return nil
+}
+
+func resourceClouddeployReleaseImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	config := meta.(*transport_tpg.Config)
+	if err := tpgresource.ParseImportId([]string{
+		"projects/(?P<project>[^/]+)/locations/(?P<location>[^/]+)/deliveryPipelines/(?P<delivery_pipeline>[^/]+)/releases/(?P<name>[^/]+)",
+		"(?P<project>[^/]+)/(?P<location>[^/]+)/(?P<delivery_pipeline>[^/]+)/(?P<name>[^/]+)",
+		"(?P<location>[^/]+)/(?P<delivery_pipeline>[^/]+)/(?P<name>[^/]+)",
+	}, d, config); err != nil {
+		return nil, err
+	}
+
+	id, err := tpgresource.ReplaceVars(d, config, "projects/{{project}}/locations/{{location}}/deliveryPipelines/{{delivery_pipeline}}/releases/{{name}}")
+	if err != nil {
+		return nil, fmt.Errorf("Error constructing id: %s", err)
+	}
+	d.SetId(id)
+
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,186 @@
+package google
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+
+	"github.com/hashicorp/terraform-provider-google/google/acctest"
+)
+
+func TestClouddeployReleaseRenderFailure(t *testing.T) {
+	t.Parallel()
+
+	release := map[string]interface{}{
+		"name":        "projects/p/locations/us-central1/deliveryPipelines/web/releases/r1",
+		"renderState": "FAILED",
+		"targetRenders": map[string]interface{}{
+			"staging": map[string]interface{}{
+				"renderingState": "FAILED",
+				"failureCause":   "EXECUTION_FAILED",
+				"failureMessage": "skaffold render failed",
+				"renderingBuild": "projects/p/locations/us-central1/builds/1234",
+			},
+			"prod": map[string]interface{}{
+				"renderingState": "SUCCEEDED",
+			},
+			"dev": map[string]interface{}{
+				"renderingState": "FAILED",
+				"failureCause":   "CLOUD_BUILD_UNAVAILABLE",
+			},
+		},
+	}
+	expected := "Release projects/p/locations/us-central1/deliveryPipelines/web/releases/r1 failed to render for " +
+		"target dev: CLOUD_BUILD_UNAVAILABLE; " +
+		"target staging: EXECUTION_FAILED: skaffold render failed (build projects/p/locations/us-central1/builds/1234)"
+
+	if got := clouddeployReleaseRenderFailure(release).Error(); got != expected {
+		t.Errorf("got %q, expected %q", got, expected)
+	}
+
+	if result := clouddeployReleasePollCheck(release, nil); result == nil || result.Retryable {
+		t.Errorf("expected a failed render to stop polling, got %v", result)
+	}
+	if result := clouddeployReleasePollCheck(map[string]interface{}{"renderState": "IN_PROGRESS"}, nil); result == nil || !result.Retryable {
+		t.Errorf("expected a render in progress to be polled, got %v", result)
+	}
+	if result := clouddeployReleasePollCheck(map[string]interface{}{"renderState": "SUCCEEDED"}, nil); result != nil {
+		t.Errorf("expected a successful render to stop polling, got %v", result.Err)
+	}
+}
+
+func TestFlattenClouddeployReleaseTargetIds(t *testing.T) {
+	t.Parallel()
+
+	release := map[string]interface{}{
+		"deliveryPipelineSnapshot": map[string]interface{}{
+			"serialPipeline": map[string]interface{}{
+				"stages": []interface{}{
+					map[string]interface{}{"targetId": "dev"},
+					map[string]interface{}{"targetId": "staging", "profiles": []interface{}{"staging"}},
+					map[string]interface{}{"targetId": "prod"},
+				},
+			},
+		},
+	}
+
+	if got, expected := flattenClouddeployReleaseTargetIds(release), []interface{}{"dev", "staging", "prod"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+}
+
+func TestAccClouddeployRelease_rollout(t *testing.T) {
+	t.Parallel()
+
+	context := map[string]interface{}{
+		"project":       acctest.GetTestProjectFromEnv(),
+		"region":        acctest.GetTestRegionFromEnv(),
+		"random_suffix": RandString(t, 10),
+	}
+
+	VcrTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.AccTestPreCheck(t) },
+		ProtoV5ProviderFactories: ProtoV5ProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccClouddeployRelease_rollout(context),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("google_clouddeploy_release.release", "render_state", "SUCCEEDED"),
+					resource.TestCheckResourceAttr("google_clouddeploy_release.release", "target_ids.0", "tf-test-dev-"+context["random_suffix"].(string)),
+					resource.TestCheckResourceAttr("google_clouddeploy_rollout.dev", "state", "SUCCEEDED"),
+					resource.TestCheckResourceAttr("google_clouddeploy_rollout.dev", "phases.0.id", "stable"),
+				),
+			},
+			{
+				ResourceName:      "google_clouddeploy_release.release",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				ResourceName:      "google_clouddeploy_rollout.dev",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccClouddeployRelease_rollout(context map[string]interface{}) string {
+	return Nprintf(`
+resource "google_storage_bucket" "source" {
+  name                        = "tf-test-deploy-%{random_suffix}"
+  location                    = "US"
+  uniform_bucket_level_access = true
+  force_destroy               = true
+}
+
+resource "google_cloudbuild_build" "source" {
+  build {
+    step {
+      name       = "gcr.io/cloud-builders/gsutil"
+      entrypoint = "bash"
+      args = ["-c", <<-EOT
+        cat > skaffold.yaml <<EOF
+        apiVersion: skaffold/v4beta1
+        kind: Config
+        manifests:
+          rawYaml:
+          - service.yaml
+        deploy:
+          cloudrun: {}
+        EOF
+        cat > service.yaml <<EOF
+        apiVersion: serving.knative.dev/v1
+        kind: Service
+        metadata:
+          name: tf-test-app-%{random_suffix}
+        spec:
+          template:
+            spec:
+              containers:
+              - image: us-docker.pkg.dev/cloudrun/container/hello
+        EOF
+        tar czf source.tar.gz skaffold.yaml service.yaml
+        gsutil cp source.tar.gz gs://${google_storage_bucket.source.name}/source.tar.gz
+      EOT
+      ]
+    }
+  }
+}
+
+resource "google_clouddeploy_target" "dev" {
+  location = "%{region}"
+  name     = "tf-test-dev-%{random_suffix}"
+
+  run {
+    location = "projects/%{project}/locations/%{region}"
+  }
+}
+
+resource "google_clouddeploy_delivery_pipeline" "pipeline" {
+  location = "%{region}"
+  name     = "tf-test-pipeline-%{random_suffix}"
+
+  serial_pipeline {
+    stages {
+      target_id = google_clouddeploy_target.dev.name
+    }
+  }
+}
+
+resource "google_clouddeploy_release" "release" {
+  location            = "%{region}"
+  delivery_pipeline   = google_clouddeploy_delivery_pipeline.pipeline.name
+  name                = "tf-test-release-%{random_suffix}"
+  skaffold_config_uri = "gs://${google_storage_bucket.source.name}/source.tar.gz"
+
+  depends_on = [google_cloudbuild_build.source]
+}
+
+resource "google_clouddeploy_rollout" "dev" {
+  release   = google_clouddeploy_release.release.id
+  target_id = google_clouddeploy_release.release.target_ids[0]
+}
+`, context)
+}
@@ -0,0 +1,378 @@
+package google
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-provider-google/google/tpgresource"
+	transport_tpg "github.com/hashicorp/terraform-provider-google/google/transport"
+	"github.com/hashicorp/terraform-provider-google/google/verify"
+)
+
+// clouddeployReleaseIdRegex matches the ID of a google_clouddeploy_release.
+var clouddeployReleaseIdRegex = regexp.MustCompile("^projects/([^/]+)/locations/[^/]+/deliveryPipelines/[^/]+/releases/[^/]+$")
+
+// clouddeployRolloutActiveStates are the states of a rollout that hasn't
+// finished yet, including one waiting for an approval.
+var clouddeployRolloutActiveStates = []string{"STATE_UNSPECIFIED", "PENDING_RELEASE", "PENDING_APPROVAL", "PENDING", "IN_PROGRESS"}
+
+func ResourceClouddeployRollout() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceClouddeployRolloutCreate,
+		Read:   resourceClouddeployRolloutRead,
+		Delete: resourceClouddeployRolloutDelete,
+
+		Importer: &schema.ResourceImporter{
+			State: resourceClouddeployRolloutImport,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(60 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"release": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: verify.ValidateRegexp(clouddeployReleaseIdRegex.String()),
+				Description:  `The ID of the release to roll out, in the format projects/{{project}}/locations/{{location}}/deliveryPipelines/{{delivery_pipeline}}/releases/{{name}}.`,
+			},
+
+			"target_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: `The ID of the target to roll the release out to.`,
+			},
+
+			"rollout_id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ValidateFunc: verify.ValidateRegexp(`^[a-z]([a-z0-9-]{0,61}[a-z0-9])?$`),
+				Description:  `The ID of the rollout. Defaults to a unique ID, so that a failed rollout can be retried.`,
+			},
+
+			"description": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: `A description of the rollout.`,
+			},
+
+			"annotations": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: `User annotations of the rollout.`,
+			},
+
+			"labels": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: `Labels of the rollout.`,
+			},
+
+			"uid": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The unique identifier of the rollout.`,
+			},
+
+			"state": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The state of the rollout.`,
+			},
+
+			"approval_state": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The approval state of the rollout.`,
+			},
+
+			"failure_reason": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `Why the rollout failed, if it did.`,
+			},
+
+			"deploy_failure_cause": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The cause of the failure of the deployment, if it failed.`,
+			},
+
+			"deploying_build": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The Cloud Build build that deployed the release.`,
+			},
+
+			"create_time": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The time the rollout was created.`,
+			},
+
+			"approve_time": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The time the rollout was approved.`,
+			},
+
+			"deploy_start_time": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The time the deployment started.`,
+			},
+
+			"deploy_end_time": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The time the deployment finished.`,
+			},
+
+			"phases": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: `The phases of the rollout, e.g. the stable phase or the phases of a canary deployment.`,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: `The ID of the phase.`,
+						},
+						"state": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: `The state of the phase.`,
+						},
+						"skip_message": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: `Why the phase was skipped, if it was.`,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func resourceClouddeployRolloutCreate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	release := d.Get("release").(string)
+	project := clouddeployReleaseIdRegex.FindStringSubmatch(release)[1]
+
+	rolloutId := d.Get("rollout_id").(string)
+	if rolloutId == "" {
+		rolloutId = resource.PrefixedUniqueId("rollout-")
+	}
+
+	obj := map[string]interface{}{
+		"targetId": d.Get("target_id"),
+	}
+	for field, key := range map[string]string{"description": "description", "annotations": "annotations", "labels": "labels"} {
+		if v, ok := d.GetOk(field); ok {
+			obj[key] = v
+		}
+	}
+
+	url := fmt.Sprintf("%s%s/rollouts?rolloutId=%s", clouddeployBasePath(config), release, rolloutId)
+	log.Printf("[DEBUG] Creating new Rollout: %#v", obj)
+	op, err := transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
+		Config:    config,
+		Method:    "POST",
+		Project:   project,
+		RawURL:    url,
+		UserAgent: userAgent,
+		Body:      obj,
+		Timeout:   d.Timeout(schema.TimeoutCreate),
+	})
+	if err != nil {
+		return fmt.Errorf("Error creating Rollout: %s", err)
+	}
+
+	id := fmt.Sprintf("%s/rollouts/%s", release, rolloutId)
+	if err := ClouddeployOperationWaitTime(config, op, project, "Creating Rollout", userAgent, d.Timeout(schema.TimeoutCreate)); err != nil {
+		return fmt.Errorf("Error waiting to create Rollout: %s", err)
+	}
+
+	// The rollout may wait for an approval before it's deployed, which is
+	// waited for as long as the rest of the rollout.
+	var rollout map[string]interface{}
+	pollRead := func() (map[string]interface{}, error) {
+		res, err := transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
+			Config:    config,
+			Method:    "GET",
+			Project:   project,
+			RawURL:    clouddeployBasePath(config) + id,
+			UserAgent: userAgent,
+		})
+		if err == nil {
+			rollout = res
+		}
+		return res, err
+	}
+	err = transport_tpg.PollingWaitTime(pollRead, clouddeployRolloutPollCheck, "Rolling out", d.Timeout(schema.TimeoutCreate), 1)
+	if err != nil {
+		if state, _ := rollout["state"].(string); tpgresource.StringInSlice(clouddeployRolloutActiveStates, state) {
+			// The wait timed out. The rollout is cancelled rather than left to
+			// deploy later, as the next apply creates a new one.
+			_, cancelErr := transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
+				Config:    config,
+				Method:    "POST",
+				Project:   project,
+				RawURL:    clouddeployBasePath(config) + id + ":cancel",
+				UserAgent: userAgent,
+				Body:      map[string]interface{}{},
+			})
+			if cancelErr != nil {
+				log.Printf("[WARN] Error cancelling Rollout %s: %s", id, cancelErr)
+			}
+			return fmt.Errorf("Error waiting for Rollout %s in state %s, which was cancelled: %s", id, state, err)
+		}
+		return fmt.Errorf("Error waiting for Rollout %s: %s", id, err)
+	}
+
+	d.SetId(id)
+	log.Printf("[DEBUG] Finished creating Rollout %q", d.Id())
+
+	return resourceClouddeployRolloutRead(d, meta)
+}
+
+func clouddeployRolloutPollCheck(resp map[string]interface{}, respErr error) transport_tpg.PollResult {
+	if respErr != nil {
+		return transport_tpg.ErrorPollResult(respErr)
+	}
+	state, _ := resp["state"].(string)
+	if state == "SUCCEEDED" {
+		return transport_tpg.SuccessPollResult()
+	}
+	if tpgresource.StringInSlice(clouddeployRolloutActiveStates, state) {
+		if state == "PENDING_APPROVAL" {
+			log.Printf("[INFO] Rollout %v is waiting for an approval", resp["name"])
+		}
+		return transport_tpg.PendingStatusPollResult(state)
+	}
+	return transport_tpg.ErrorPollResult(clouddeployRolloutFailure(resp))
+}
+
+// clouddeployRolloutFailure describes why rollout didn't succeed.
+func clouddeployRolloutFailure(rollout map[string]interface{}) error {
+	msg := fmt.Sprintf("Rollout %v finished with state %v", rollout["name"], rollout["state"])
+	if cause, ok := rollout["deployFailureCause"].(string); ok && cause != "" {
+		msg += fmt.Sprintf(" (%s)", cause)
+	}
+	if reason, ok := rollout["failureReason"].(string); ok && reason != "" {
+		msg += ": " + reason
+	}
+	if build, ok := rollout["deployingBuild"].(string); ok && build != "" {
+		msg += fmt.Sprintf(". Deploying build: %s", build)
+	}
+	return fmt.Errorf("%s", msg)
+}
+
+func flattenClouddeployRolloutPhases(rollout map[string]interface{}) []interface{} {
+	phases := []interface{}{}
+	raw, _ := rollout["phases"].([]interface{})
+	for _, p := range raw {
+		phase, _ := p.(map[string]interface{})
+		phases = append(phases, map[string]interface{}{
+			"id":           phase["id"],
+			"state":        phase["state"],
+			"skip_message": phase["skipMessage"],
+		})
+	}
+	return phases
+}
+
+func resourceClouddeployRolloutRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	release := d.Get("release").(string)
+	project := clouddeployReleaseIdRegex.FindStringSubmatch(release)[1]
+
+	res, err := transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
+		Config:    config,
+		Method:    "GET",
+		Project:   project,
+		RawURL:    clouddeployBasePath(config) + d.Id(),
+		UserAgent: userAgent,
+	})
+	if err != nil {
+		return transport_tpg.HandleNotFoundError(err, d, fmt.Sprintf("ClouddeployRollout %q", d.Id()))
+	}
+
+	if err := d.Set("rollout_id", tpgresource.GetResourceNameFromSelfLink(d.Id())); err != nil {
+		return fmt.Errorf("Error setting rollout_id: %s", err)
+	}
+	for field, key := range map[string]string{
+		"target_id":            "targetId",
+		"description":          "description",
+		"annotations":          "annotations",
+		"labels":               "labels",
+		"uid":                  "uid",
+		"state":                "state",
+		"approval_state":       "approvalState",
+		"failure_reason":       "failureReason",
+		"deploy_failure_cause": "deployFailureCause",
+		"deploying_build":      "deployingBuild",
+		"create_time":          "createTime",
+		"approve_time":         "approveTime",
+		"deploy_start_time":    "deployStartTime",
+		"deploy_end_time":      "deployEndTime",
+	} {
+		if err := d.Set(field, res[key]); err != nil {
+			return fmt.Errorf("Error setting %s: %s", field, err)
+		}
+	}
+	if err := d.Set("phases", flattenClouddeployRolloutPhases(res)); err != nil {
+		return fmt.Errorf("Error setting phases: %s", err)
+	}
+	return nil
+}
+
+func resourceClouddeployRolloutDelete(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[WARNING] Clouddeploy Rollout resources"+
+		" cannot be deleted from Google Cloud. The resource %s will be removed from Terraform"+
+		" state, but will still be present on Google Cloud.", d.Id())
+	d.SetId("")
+
+	return nil
+}
+
+func resourceClouddeployRolloutImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	parts := regexp.MustCompile("^(projects/[^/]+/locations/[^/]+/deliveryPipelines/[^/]+/releases/[^/]+)/rollouts/([^/]+)$").FindStringSubmatch(d.Id())
+	if parts == nil {
+		return nil, fmt.Errorf("Invalid Rollout id %q, expected projects/{{project}}/locations/{{location}}/deliveryPipelines/{{delivery_pipeline}}/releases/{{release}}/rollouts/{{rollout_id}}", d.Id())
+	}
+	if err := d.Set("release", parts[1]); err != nil {
+		return nil, fmt.Errorf("Error setting release: %s", err)
+	}
+	if err := d.Set("rollout_id", parts[2]); err != nil {
+		return nil, fmt.Errorf("Error setting rollout_id: %s", err)
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,85 @@
+package google
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestClouddeployRolloutPollCheck(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		Rollout map[string]interface{}
+		Err     error
+		Pending bool
+		Error   string
+	}{
+		"succeeded": {
+			Rollout: map[string]interface{}{"state": "SUCCEEDED"},
+		},
+		"pending approval": {
+			Rollout: map[string]interface{}{"state": "PENDING_APPROVAL", "approvalState": "NEEDS_APPROVAL"},
+			Pending: true,
+		},
+		"in progress": {
+			Rollout: map[string]interface{}{"state": "IN_PROGRESS"},
+			Pending: true,
+		},
+		"rejected": {
+			Rollout: map[string]interface{}{"name": "r", "state": "APPROVAL_REJECTED", "approvalState": "REJECTED"},
+			Error:   "Rollout r finished with state APPROVAL_REJECTED",
+		},
+		"failed": {
+			Rollout: map[string]interface{}{
+				"name":               "r",
+				"state":              "FAILED",
+				"deployFailureCause": "EXECUTION_FAILED",
+				"failureReason":      "Cloud Run service failed to become ready",
+				"deployingBuild":     "projects/p/locations/us-central1/builds/1234",
+			},
+			Error: "Rollout r finished with state FAILED (EXECUTION_FAILED): Cloud Run service failed to become ready. Deploying build: projects/p/locations/us-central1/builds/1234",
+		},
+		"request error": {
+			Err:   fmt.Errorf("quota exceeded"),
+			Error: "quota exceeded",
+		},
+	}
+
+	for tn, tc := range cases {
+		result := clouddeployRolloutPollCheck(tc.Rollout, tc.Err)
+		switch {
+		case tc.Error != "":
+			if result == nil || result.Retryable || result.Err.Error() != tc.Error {
+				t.Errorf("%s: expected error %q, got %v", tn, tc.Error, result)
+			}
+		case tc.Pending:
+			if result == nil || !result.Retryable {
+				t.Errorf("%s: expected pending, got %v", tn, result)
+			}
+		default:
+			if result != nil {
+				t.Errorf("%s: expected success, got %v", tn, result.Err)
+			}
+		}
+	}
+}
+
+func TestFlattenClouddeployRolloutPhases(t *testing.T) {
+	t.Parallel()
+
+	rollout := map[string]interface{}{
+		"phases": []interface{}{
+			map[string]interface{}{"id": "canary-25", "state": "SUCCEEDED", "deploymentJobs": map[string]interface{}{}},
+			map[string]interface{}{"id": "stable", "state": "SKIPPED", "skipMessage": "skipped by user"},
+		},
+	}
+	expected := []interface{}{
+		map[string]interface{}{"id": "canary-25", "state": "SUCCEEDED", "skip_message": nil},
+		map[string]interface{}{"id": "stable", "state": "SKIPPED", "skip_message": "skipped by user"},
+	}
+
+	if got := flattenClouddeployRolloutPhases(rollout); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+}
@@ -1,6 +1,8 @@
 package google
 
 import (
+	"io/ioutil"
+	"path/filepath"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
@@ -51,6 +53,69 @@ func TestAccCloudFunctions2Function_update(t *testing.T) {
 	})
 }
 
+func TestAccCloudFunctions2Function_sourceDir(t *testing.T) {
+	t.Parallel()
+
+	sourceDir := createSourceDirForCloudFunction(t, testHTTPTriggerPath)
+	context := map[string]interface{}{
+		"source_dir":    sourceDir,
+		"random_suffix": RandString(t, 10),
+	}
+
+	VcrTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.AccTestPreCheck(t) },
+		ProtoV5ProviderFactories: ProtoV5ProviderFactories(t),
+		CheckDestroy:             testAccCheckCloudfunctions2functionDestroyProducer(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudFunctions2Function_sourceDir(context),
+				Check:  resource.TestCheckResourceAttrSet("google_cloudfunctions2_function.terraform-test2", "source_dir_hash"),
+			},
+			{
+				ResourceName:            "google_cloudfunctions2_function.terraform-test2",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"location", "source_dir", "source_dir_hash", "build_config.0.source"},
+			},
+			{
+				PreConfig: func() {
+					source, err := ioutil.ReadFile(testHTTPTriggerUpdatePath)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if err := ioutil.WriteFile(filepath.Join(sourceDir, "index.js"), source, 0644); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: testAccCloudFunctions2Function_sourceDir(context),
+				Check:  resource.TestCheckResourceAttrSet("google_cloudfunctions2_function.terraform-test2", "source_dir_hash"),
+			},
+		},
+	})
+}
+
+func testAccCloudFunctions2Function_sourceDir(context map[string]interface{}) string {
+	return Nprintf(`
+resource "google_cloudfunctions2_function" "terraform-test2" {
+  name = "tf-test-test-function%{random_suffix}"
+  location = "us-central1"
+  description = "a function deployed from a local directory"
+  source_dir = "%{source_dir}"
+
+  build_config {
+    runtime = "nodejs16"
+    entry_point = "helloGET"
+  }
+
+  service_config {
+    max_instance_count  = 1
+    available_memory    = "256Mi"
+    timeout_seconds     = 60
+  }
+}
+`, context)
+}
+
 func testAccCloudfunctions2function_basic(context map[string]interface{}) string {
 	return Nprintf(`
 resource "google_storage_bucket" "bucket" {
@@ -42,6 +42,8 @@ func ResourceCloudfunctions2function() *schema.Resource {
 			State: resourceCloudfunctions2functionImport,
 		},
 
+		CustomizeDiff: cloudFunctionsSourceDirCustomizeDiff,
+
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(60 * time.Minute),
 			Update: schema.DefaultTimeout(60 * time.Minute),
@@ -440,6 +442,14 @@ timeout period. Defaults to 60 seconds.`,
 					},
 				},
 			},
+			"source_dir": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: `Path to a local directory containing the function source. The directory is zipped,
+honoring .gcloudignore, and uploaded to a staging bucket managed by Cloud Functions.
+Cannot be set alongside 'build_config.0.source'.`,
+				ConflictsWith: []string{"build_config.0.source"},
+			},
 			"environment": {
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -450,6 +460,11 @@ timeout period. Defaults to 60 seconds.`,
 				Computed:    true,
 				Description: `Describes the current state of the function.`,
 			},
+			"source_dir_hash": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `SHA-256 hash of the packaged content of 'source_dir'. The function is redeployed when it changes.`,
+			},
 			"update_time": {
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -540,6 +555,11 @@ func resourceCloudfunctions2functionCreate(d *schema.ResourceData, meta interfac
 		obj["labels"] = labelsProp
 	}
 
+	obj, err = resourceCloudfunctions2functionEncoder(d, meta, obj)
+	if err != nil {
+		return err
+	}
+
 	url, err := tpgresource.ReplaceVars(d, config, "{{Cloudfunctions2BasePath}}projects/{{project}}/locations/{{location}}/functions?functionId={{name}}")
 	if err != nil {
 		return err
@@ -726,6 +746,11 @@ func resourceCloudfunctions2functionUpdate(d *schema.ResourceData, meta interfac
 		obj["labels"] = labelsProp
 	}
 
+	obj, err = resourceCloudfunctions2functionUpdateEncoder(d, meta, obj)
+	if err != nil {
+		return err
+	}
+
 	url, err := tpgresource.ReplaceVars(d, config, "{{Cloudfunctions2BasePath}}projects/{{project}}/locations/{{location}}/functions/{{name}}")
 	if err != nil {
 		return err
@@ -738,7 +763,7 @@ func resourceCloudfunctions2functionUpdate(d *schema.ResourceData, meta interfac
 		updateMask = append(updateMask, "description")
 	}
 
-	if d.HasChange("build_config") {
+	if d.HasChange("build_config") || d.HasChange("source_dir_hash") {
 		updateMask = append(updateMask, "buildConfig")
 	}
 
@@ -925,6 +950,11 @@ func flattenCloudfunctions2functionBuildConfigSource(v interface{}, d *schema.Re
 	if v == nil {
 		return nil
 	}
+	// Source uploaded from source_dir lives in a bucket managed by Cloud
+	// Functions and is tracked by source_dir_hash instead.
+	if _, ok := d.GetOk("source_dir"); ok {
+		return nil
+	}
 	original := v.(map[string]interface{})
 	if len(original) == 0 {
 		return nil
@@ -2166,3 +2196,89 @@ func expandCloudfunctions2functionLabels(v interface{}, d tpgresource.TerraformR
 	}
 	return m, nil
 }
+
+func resourceCloudfunctions2functionEncoder(d *schema.ResourceData, meta interface{}, obj map[string]interface{}) (map[string]interface{}, error) {
+	if _, ok := d.GetOk("source_dir"); !ok {
+		return obj, nil
+	}
+	return cloudfunctions2functionUploadSourceDir(d, meta, obj)
+}
+
+func resourceCloudfunctions2functionUpdateEncoder(d *schema.ResourceData, meta interface{}, obj map[string]interface{}) (map[string]interface{}, error) {
+	if _, ok := d.GetOk("source_dir"); !ok {
+		return obj, nil
+	}
+	// buildConfig is replaced as a whole, so the source has to be uploaded
+	// again whenever it is part of the update.
+	if !d.HasChange("build_config") && !d.HasChange("source_dir_hash") {
+		return obj, nil
+	}
+	return cloudfunctions2functionUploadSourceDir(d, meta, obj)
+}
+
+// cloudfunctions2functionUploadSourceDir packages source_dir, uploads it to the
+// staging bucket returned by generateUploadUrl and points buildConfig at it.
+func cloudfunctions2functionUploadSourceDir(d *schema.ResourceData, meta interface{}, obj map[string]interface{}) (map[string]interface{}, error) {
+	config := meta.(*transport_tpg.Config)
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return nil, err
+	}
+
+	archive, hash, err := zipCloudFunctionsSourceDir(d.Get("source_dir").(string))
+	if err != nil {
+		return nil, err
+	}
+
+	url, err := tpgresource.ReplaceVars(d, config, "{{Cloudfunctions2BasePath}}projects/{{project}}/locations/{{location}}/functions:generateUploadUrl")
+	if err != nil {
+		return nil, err
+	}
+
+	billingProject := ""
+
+	project, err := tpgresource.GetProject(d, config)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching project for function: %s", err)
+	}
+	billingProject = project
+
+	// err == nil indicates that the billing_project value was found
+	if bp, err := tpgresource.GetBillingProject(d, config); err == nil {
+		billingProject = bp
+	}
+
+	res, err := transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
+		Config:    config,
+		Method:    "POST",
+		Project:   billingProject,
+		RawURL:    url,
+		UserAgent: userAgent,
+		Body:      map[string]interface{}{},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error generating upload URL for function source: %s", err)
+	}
+
+	uploadUrl, ok := res["uploadUrl"].(string)
+	if !ok || uploadUrl == "" {
+		return nil, fmt.Errorf("Error generating upload URL for function source: no uploadUrl in response")
+	}
+	if err := uploadCloudFunctionsSource(config.Context, uploadUrl, archive, nil); err != nil {
+		return nil, err
+	}
+
+	buildConfig, ok := obj["buildConfig"].(map[string]interface{})
+	if !ok || buildConfig == nil {
+		buildConfig = make(map[string]interface{})
+	}
+	buildConfig["source"] = map[string]interface{}{
+		"storageSource": res["storageSource"],
+	}
+	obj["buildConfig"] = buildConfig
+
+	if err := d.Set("source_dir_hash", hash); err != nil {
+		return nil, fmt.Errorf("Error setting source_dir_hash: %s", err)
+	}
+	return obj, nil
+}
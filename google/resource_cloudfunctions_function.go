@@ -134,6 +134,8 @@ func ResourceCloudFunctionsFunction() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
+		CustomizeDiff: cloudFunctionsSourceDirCustomizeDiff,
+
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(5 * time.Minute),
 			Read:   schema.DefaultTimeout(5 * time.Minute),
@@ -190,6 +192,19 @@ func ResourceCloudFunctionsFunction() *schema.Resource {
 				},
 			},
 
+			"source_dir": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   `Path to a local directory containing the function source. The directory is zipped, honoring .gcloudignore, and uploaded to a staging bucket managed by Cloud Functions. Cannot be set alongside source_archive_bucket, source_archive_object or source_repository.`,
+				ConflictsWith: []string{"source_archive_bucket", "source_archive_object", "source_repository"},
+			},
+
+			"source_dir_hash": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `SHA-256 hash of the packaged content of source_dir. The function is redeployed when it changes.`,
+			},
+
 			"docker_registry": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -504,11 +519,17 @@ func resourceCloudFunctionsCreate(d *schema.ResourceData, meta interface{}) erro
 	sourceRepos := d.Get("source_repository").([]interface{})
 	if len(sourceRepos) > 0 {
 		function.SourceRepository = expandSourceRepository(sourceRepos)
+	} else if _, ok := d.GetOk("source_dir"); ok {
+		uploadUrl, err := uploadCloudFunctionsSourceDir(d, config, userAgent, cloudFuncId)
+		if err != nil {
+			return err
+		}
+		function.SourceUploadUrl = uploadUrl
 	} else {
 		sourceArchiveBucket := d.Get("source_archive_bucket").(string)
 		sourceArchiveObj := d.Get("source_archive_object").(string)
 		if sourceArchiveBucket == "" || sourceArchiveObj == "" {
-			return fmt.Errorf("either source_repository or both of source_archive_bucket+source_archive_object must be set")
+			return fmt.Errorf("one of source_repository, source_dir or both of source_archive_bucket+source_archive_object must be set")
 		}
 		function.SourceArchiveUrl = fmt.Sprintf("gs://%v/%v", sourceArchiveBucket, sourceArchiveObj)
 	}
@@ -805,6 +826,17 @@ func resourceCloudFunctionsUpdate(d *schema.ResourceData, meta interface{}) erro
 		updateMaskArr = append(updateMaskArr, "sourceRepository")
 	}
 
+	if _, ok := d.GetOk("source_dir"); ok && d.HasChange("source_dir_hash") {
+		uploadUrl, err := uploadCloudFunctionsSourceDir(d, config, userAgent, cloudFuncId)
+		if err != nil {
+			return err
+		}
+		function.SourceUploadUrl = uploadUrl
+		function.SourceArchiveUrl = ""
+		function.SourceRepository = nil
+		updateMaskArr = append(updateMaskArr, "sourceUploadUrl")
+	}
+
 	if d.HasChange("secret_environment_variables") {
 		function.SecretEnvironmentVariables = expandSecretEnvironmentVariables(d.Get("secret_environment_variables").([]interface{}))
 		updateMaskArr = append(updateMaskArr, "secretEnvironmentVariables")
@@ -925,6 +957,38 @@ func resourceCloudFunctionsUpdate(d *schema.ResourceData, meta interface{}) erro
 	return resourceCloudFunctionsRead(d, meta)
 }
 
+// uploadCloudFunctionsSourceDir packages source_dir and uploads it to a signed
+// URL in the staging bucket of the function's location, returning the URL to
+// deploy from.
+func uploadCloudFunctionsSourceDir(d *schema.ResourceData, config *transport_tpg.Config, userAgent string, cloudFuncId *cloudFunctionId) (string, error) {
+	dir := d.Get("source_dir").(string)
+	archive, hash, err := zipCloudFunctionsSourceDir(dir)
+	if err != nil {
+		return "", err
+	}
+
+	req := &cloudfunctions.GenerateUploadUrlRequest{
+		KmsKeyName: d.Get("kms_key_name").(string),
+	}
+	res, err := config.NewCloudFunctionsClient(userAgent).Projects.Locations.Functions.GenerateUploadUrl(cloudFuncId.locationId(), req).Do()
+	if err != nil {
+		return "", fmt.Errorf("Error generating upload URL for function source: %s", err)
+	}
+
+	// Signed URLs of 1st gen functions require the content length range header.
+	headers := map[string]string{
+		"x-goog-content-length-range": "0,104857600",
+	}
+	if err := uploadCloudFunctionsSource(config.Context, res.UploadUrl, archive, headers); err != nil {
+		return "", err
+	}
+
+	if err := d.Set("source_dir_hash", hash); err != nil {
+		return "", fmt.Errorf("Error setting source_dir_hash: %s", err)
+	}
+	return res.UploadUrl, nil
+}
+
 func resourceCloudFunctionsDestroy(d *schema.ResourceData, meta interface{}) error {
 	config := meta.(*transport_tpg.Config)
 	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
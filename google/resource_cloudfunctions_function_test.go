@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -687,6 +688,55 @@ func TestAccCloudFunctionsFunction_secretMount(t *testing.T) {
 	})
 }
 
+func TestAccCloudFunctionsFunction_sourceDir(t *testing.T) {
+	t.Parallel()
+
+	var function cloudfunctions.CloudFunction
+
+	funcResourceName := "google_cloudfunctions_function.function"
+	functionName := fmt.Sprintf("tf-test-%s", RandString(t, 10))
+	sourceDir := createSourceDirForCloudFunction(t, testHTTPTriggerPath)
+
+	VcrTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.AccTestPreCheck(t) },
+		ProtoV5ProviderFactories: ProtoV5ProviderFactories(t),
+		CheckDestroy:             testAccCheckCloudFunctionsFunctionDestroyProducer(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudFunctionsFunction_sourceDir(functionName, sourceDir),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCloudFunctionsFunctionExists(
+						t, funcResourceName, &function),
+					resource.TestCheckResourceAttrSet(funcResourceName, "source_dir_hash"),
+				),
+			},
+			{
+				ResourceName:            funcResourceName,
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"build_environment_variables", "source_dir", "source_dir_hash"},
+			},
+			{
+				PreConfig: func() {
+					source, err := ioutil.ReadFile(testHTTPTriggerUpdatePath)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if err := ioutil.WriteFile(filepath.Join(sourceDir, "index.js"), source, 0644); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: testAccCloudFunctionsFunction_sourceDir(functionName, sourceDir),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCloudFunctionsFunctionExists(
+						t, funcResourceName, &function),
+					resource.TestCheckResourceAttrSet(funcResourceName, "source_dir_hash"),
+				),
+			},
+		},
+	})
+}
+
 func testAccCheckCloudFunctionsFunctionDestroyProducer(t *testing.T) func(s *terraform.State) error {
 	return func(s *terraform.State) error {
 		config := GoogleProviderConfig(t)
@@ -838,6 +888,27 @@ func createZIPArchiveForCloudFunctionSource(t *testing.T, sourcePath string) str
 	return tmpfile.Name()
 }
 
+// createSourceDirForCloudFunction creates a temporary source directory with
+// sourcePath as index.js and a file that is excluded by .gcloudignore.
+func createSourceDirForCloudFunction(t *testing.T, sourcePath string) string {
+	source, err := ioutil.ReadFile(sourcePath)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		"index.js":      string(source),
+		".gcloudignore": ".gcloudignore\n*.log\n",
+		"debug.log":     "ignored",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+	return dir
+}
+
 func sweepCloudFunctionSourceZipArchives(_ string) error {
 	files, err := ioutil.ReadDir(os.TempDir())
 	if err != nil {
@@ -900,6 +971,20 @@ resource "google_cloudfunctions_function" "function" {
 `, bucketName, zipFilePath, functionName)
 }
 
+func testAccCloudFunctionsFunction_sourceDir(functionName string, sourceDir string) string {
+	return fmt.Sprintf(`
+resource "google_cloudfunctions_function" "function" {
+  name                = "%s"
+  runtime             = "nodejs10"
+  description         = "test function"
+  available_memory_mb = 128
+  source_dir          = "%s"
+  trigger_http        = true
+  entry_point         = "helloGET"
+}
+`, functionName, sourceDir)
+}
+
 func testAccCloudFunctionsFunction_updated(functionName string, bucketName string, zipFilePath string, randomSuffix string) string {
 	return fmt.Sprintf(`
 resource "google_storage_bucket" "bucket" {
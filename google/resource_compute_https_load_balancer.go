@@ -0,0 +1,866 @@
+package google
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-provider-google/google/tpgresource"
+	transport_tpg "github.com/hashicorp/terraform-provider-google/google/transport"
+	"github.com/hashicorp/terraform-provider-google/google/verify"
+
+	"google.golang.org/api/compute/v1"
+)
+
+func ResourceComputeHttpsLoadBalancer() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceComputeHttpsLoadBalancerCreate,
+		Read:   resourceComputeHttpsLoadBalancerRead,
+		Update: resourceComputeHttpsLoadBalancerUpdate,
+		Delete: resourceComputeHttpsLoadBalancerDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceComputeHttpsLoadBalancerImport,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(20 * time.Minute),
+			Update: schema.DefaultTimeout(20 * time.Minute),
+			Delete: schema.DefaultTimeout(20 * time.Minute),
+		},
+
+		CustomizeDiff: customdiff.All(
+			customdiff.ComputedIf("certificate", computeHttpsLoadBalancerDomainsChanged),
+			customdiff.ComputedIf("certificate_status", computeHttpsLoadBalancerDomainsChanged),
+			customdiff.ComputedIf("certificate_domain_status", computeHttpsLoadBalancerDomainsChanged),
+			customdiff.ComputedIf("retired_certificates", computeHttpsLoadBalancerDomainsChanged),
+			computeHttpsLoadBalancerRetireCertificates,
+			customdiff.ComputedIf("http_redirect_forwarding_rule", func(_ context.Context, d *schema.ResourceDiff, _ interface{}) bool {
+				return d.HasChange("http_redirect")
+			}),
+		),
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: verify.ValidateRegexp(`^[a-z]([-a-z0-9]{0,52}[a-z0-9])?$`),
+				Description:  `A name for the load balancer, used for the names of the resources it's made of. Changing this forces a new resource to be created.`,
+			},
+
+			"domains": {
+				Type:        schema.TypeList,
+				Required:    true,
+				MinItems:    1,
+				MaxItems:    100,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: `The domains of the Google-managed SSL certificate of the load balancer.`,
+			},
+
+			"default_backend": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateFunc:     validateComputeHttpsLoadBalancerBackend,
+				DiffSuppressFunc: tpgresource.CompareSelfLinkRelativePaths,
+				Description:      `The self link of the backend service or backend bucket requests go to when no host_rule matches.`,
+			},
+
+			"host_rule": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: `Rules routing requests for some hosts to other backends.`,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"hosts": {
+							Type:        schema.TypeList,
+							Required:    true,
+							MinItems:    1,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: `The hosts matched by this rule.`,
+						},
+						"backend": {
+							Type:             schema.TypeString,
+							Required:         true,
+							ValidateFunc:     validateComputeHttpsLoadBalancerBackend,
+							DiffSuppressFunc: tpgresource.CompareSelfLinkRelativePaths,
+							Description:      `The self link of the backend service or backend bucket requests for these hosts go to when no path_rule matches.`,
+						},
+						"path_rule": {
+							Type:        schema.TypeList,
+							Optional:    true,
+							Description: `Rules routing requests for some paths of these hosts to other backends.`,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"paths": {
+										Type:        schema.TypeList,
+										Required:    true,
+										MinItems:    1,
+										Elem:        &schema.Schema{Type: schema.TypeString},
+										Description: `The paths matched by this rule, which may end with /*.`,
+									},
+									"backend": {
+										Type:             schema.TypeString,
+										Required:         true,
+										ValidateFunc:     validateComputeHttpsLoadBalancerBackend,
+										DiffSuppressFunc: tpgresource.CompareSelfLinkRelativePaths,
+										Description:      `The self link of the backend service or backend bucket requests for these paths go to.`,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+
+			"http_redirect": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: `Whether HTTP requests on port 80 of the load balancer's address are redirected to HTTPS.`,
+			},
+
+			"ssl_policy": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				DiffSuppressFunc: tpgresource.CompareSelfLinkOrResourceName,
+				Description:      `The SSL policy of the target HTTPS proxy.`,
+			},
+
+			"load_balancing_scheme": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				Default:      "EXTERNAL_MANAGED",
+				ValidateFunc: verify.ValidateEnum([]string{"EXTERNAL", "EXTERNAL_MANAGED"}),
+				Description:  `The load balancing scheme of the forwarding rules, which must match the one of the backend services. EXTERNAL is the classic Application Load Balancer.`,
+			},
+
+			"project": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"ip_address": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The IP address of the load balancer.`,
+			},
+
+			"certificate": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The self link of the Google-managed SSL certificate.`,
+			},
+
+			"certificate_status": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The provisioning status of the certificate, e.g. PROVISIONING or ACTIVE.`,
+			},
+
+			"certificate_domain_status": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: `The provisioning status of each domain of the certificate.`,
+			},
+
+			"retired_certificates": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: `The self links of the certificates replaced by a change of domains. They stay attached to the target HTTPS proxy until certificate is ACTIVE, and are detached and deleted by the next apply after that.`,
+			},
+
+			"url_map": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The self link of the URL map.`,
+			},
+
+			"target_https_proxy": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The self link of the target HTTPS proxy.`,
+			},
+
+			"forwarding_rule": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The self link of the global forwarding rule on port 443.`,
+			},
+
+			"http_redirect_forwarding_rule": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The self link of the global forwarding rule on port 80 when http_redirect is enabled.`,
+			},
+		},
+	}
+}
+
+func computeHttpsLoadBalancerDomainsChanged(_ context.Context, d *schema.ResourceDiff, _ interface{}) bool {
+	return d.Id() != "" && d.HasChange("domains")
+}
+
+// computeHttpsLoadBalancerRetireCertificates plans detaching and deleting the
+// certificates replaced by a change of domains once the new certificate is
+// ACTIVE, so the proxy keeps serving the old domains while it's provisioned.
+func computeHttpsLoadBalancerRetireCertificates(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	if d.Id() == "" || d.HasChange("domains") || len(d.Get("retired_certificates").([]interface{})) == 0 {
+		return nil
+	}
+	if d.Get("certificate_status").(string) != "ACTIVE" {
+		return nil
+	}
+	return d.SetNew("retired_certificates", []string{})
+}
+
+// computeHttpsLoadBalancerDomains returns the domains of the certificate in
+// the order of current when they're the same, as the API may reorder them.
+func computeHttpsLoadBalancerDomains(current, returned []string) []string {
+	if len(current) != len(returned) {
+		return returned
+	}
+	seen := make(map[string]bool, len(current))
+	for _, domain := range current {
+		seen[domain] = true
+	}
+	for _, domain := range returned {
+		if !seen[domain] {
+			return returned
+		}
+	}
+	return current
+}
+
+// computeHttpsLoadBalancerOldCertificate returns the name of the certificate
+// attached to the proxy before the update. The certificate is unknown in the
+// plan when the domains change, so it's read from the prior state.
+func computeHttpsLoadBalancerOldCertificate(d tpgresource.TerraformResourceDataChange) string {
+	o, _ := d.GetChange("certificate")
+	return tpgresource.GetResourceNameFromSelfLink(o.(string))
+}
+
+func validateComputeHttpsLoadBalancerBackend(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(string)
+	if !strings.Contains(value, "/backendServices/") && !strings.Contains(value, "/backendBuckets/") {
+		errors = append(errors, fmt.Errorf("%q must be the self link of a google_compute_backend_service or google_compute_backend_bucket, got %q", k, value))
+	}
+	return
+}
+
+// computeHttpsLoadBalancerCertificateName names the certificate after its
+// domains, because a managed certificate can't be updated: changing the
+// domains creates a new certificate alongside the old one.
+func computeHttpsLoadBalancerCertificateName(name string, domains []string) string {
+	return fmt.Sprintf("%s-%x", name, sha256.Sum256([]byte(strings.Join(domains, ","))))[:len(name)+9]
+}
+
+func computeHttpsLoadBalancerRedirectName(name string) string {
+	return name + "-redirect"
+}
+
+// computeHttpsLoadBalancerPart is one of the resources a load balancer is made
+// of, with the calls creating and deleting it.
+type computeHttpsLoadBalancerPart struct {
+	kind   string
+	name   string
+	insert func() (*compute.Operation, error)
+	delete func() (*compute.Operation, error)
+}
+
+// computeHttpsLoadBalancerWait waits for an operation, described by activity.
+type computeHttpsLoadBalancerWait func(op *compute.Operation, activity string) error
+
+// createComputeHttpsLoadBalancerParts creates parts in order. When one fails,
+// the parts already created are deleted in reverse order. leftover reports
+// whether that rollback failed too, leaving some parts behind.
+func createComputeHttpsLoadBalancerParts(parts []*computeHttpsLoadBalancerPart, wait computeHttpsLoadBalancerWait) (leftover bool, err error) {
+	for i, part := range parts {
+		op, err := part.insert()
+		if err == nil {
+			err = wait(op, fmt.Sprintf("Creating %s %q", part.kind, part.name))
+		}
+		if err == nil {
+			continue
+		}
+
+		err = fmt.Errorf("Error creating %s %q: %s", part.kind, part.name, err)
+		if rbErr := deleteComputeHttpsLoadBalancerParts(parts[:i], wait); rbErr != nil {
+			return true, fmt.Errorf("%s; rolling back failed: %s", err, rbErr)
+		}
+		return false, err
+	}
+	return false, nil
+}
+
+// deleteComputeHttpsLoadBalancerParts deletes parts in reverse order, skipping
+// parts that don't exist.
+func deleteComputeHttpsLoadBalancerParts(parts []*computeHttpsLoadBalancerPart, wait computeHttpsLoadBalancerWait) error {
+	for i := len(parts) - 1; i >= 0; i-- {
+		part := parts[i]
+		op, err := part.delete()
+		if err == nil {
+			err = wait(op, fmt.Sprintf("Deleting %s %q", part.kind, part.name))
+		}
+		if err != nil {
+			if transport_tpg.IsGoogleApiErrorWithCode(err, 404) {
+				log.Printf("[DEBUG] %s %q is already gone", part.kind, part.name)
+				continue
+			}
+			return fmt.Errorf("Error deleting %s %q: %s", part.kind, part.name, err)
+		}
+	}
+	return nil
+}
+
+func computeHttpsLoadBalancerCertificatePart(client *compute.Service, project, name string, domains []string) *computeHttpsLoadBalancerPart {
+	return &computeHttpsLoadBalancerPart{
+		kind: "SSL certificate",
+		name: name,
+		insert: func() (*compute.Operation, error) {
+			return client.SslCertificates.Insert(project, &compute.SslCertificate{
+				Name:    name,
+				Type:    "MANAGED",
+				Managed: &compute.SslCertificateManagedSslCertificate{Domains: domains},
+			}).Do()
+		},
+		delete: func() (*compute.Operation, error) {
+			return client.SslCertificates.Delete(project, name).Do()
+		},
+	}
+}
+
+// computeHttpsLoadBalancerParts lists the resources behind HTTPS traffic, in
+// the order they're created.
+func computeHttpsLoadBalancerParts(d *schema.ResourceData, client *compute.Service, project string) []*computeHttpsLoadBalancerPart {
+	name := d.Get("name").(string)
+	domains := tpgresource.ConvertStringArr(d.Get("domains").([]interface{}))
+	certificate := computeHttpsLoadBalancerCertificateName(name, domains)
+	scheme := d.Get("load_balancing_scheme").(string)
+
+	return []*computeHttpsLoadBalancerPart{
+		{
+			kind: "global address",
+			name: name,
+			insert: func() (*compute.Operation, error) {
+				return client.GlobalAddresses.Insert(project, &compute.Address{
+					Name:        name,
+					AddressType: "EXTERNAL",
+					IpVersion:   "IPV4",
+				}).Do()
+			},
+			delete: func() (*compute.Operation, error) {
+				return client.GlobalAddresses.Delete(project, name).Do()
+			},
+		},
+		computeHttpsLoadBalancerCertificatePart(client, project, certificate, domains),
+		{
+			kind: "URL map",
+			name: name,
+			insert: func() (*compute.Operation, error) {
+				urlMap := expandComputeHttpsLoadBalancerUrlMap(d)
+				urlMap.Name = name
+				return client.UrlMaps.Insert(project, urlMap).Do()
+			},
+			delete: func() (*compute.Operation, error) {
+				return client.UrlMaps.Delete(project, name).Do()
+			},
+		},
+		{
+			kind: "target HTTPS proxy",
+			name: name,
+			insert: func() (*compute.Operation, error) {
+				return client.TargetHttpsProxies.Insert(project, &compute.TargetHttpsProxy{
+					Name:            name,
+					UrlMap:          fmt.Sprintf("projects/%s/global/urlMaps/%s", project, name),
+					SslCertificates: []string{fmt.Sprintf("projects/%s/global/sslCertificates/%s", project, certificate)},
+					SslPolicy:       d.Get("ssl_policy").(string),
+				}).Do()
+			},
+			delete: func() (*compute.Operation, error) {
+				return client.TargetHttpsProxies.Delete(project, name).Do()
+			},
+		},
+		{
+			kind: "global forwarding rule",
+			name: name,
+			insert: func() (*compute.Operation, error) {
+				return client.GlobalForwardingRules.Insert(project, &compute.ForwardingRule{
+					Name:                name,
+					IPAddress:           fmt.Sprintf("projects/%s/global/addresses/%s", project, name),
+					IPProtocol:          "TCP",
+					PortRange:           "443",
+					Target:              fmt.Sprintf("projects/%s/global/targetHttpsProxies/%s", project, name),
+					LoadBalancingScheme: scheme,
+				}).Do()
+			},
+			delete: func() (*compute.Operation, error) {
+				return client.GlobalForwardingRules.Delete(project, name).Do()
+			},
+		},
+	}
+}
+
+// computeHttpsLoadBalancerRedirectParts lists the resources redirecting HTTP
+// traffic to HTTPS on the same address, in the order they're created.
+func computeHttpsLoadBalancerRedirectParts(d *schema.ResourceData, client *compute.Service, project string) []*computeHttpsLoadBalancerPart {
+	name := d.Get("name").(string)
+	redirect := computeHttpsLoadBalancerRedirectName(name)
+	scheme := d.Get("load_balancing_scheme").(string)
+
+	return []*computeHttpsLoadBalancerPart{
+		{
+			kind: "URL map",
+			name: redirect,
+			insert: func() (*compute.Operation, error) {
+				return client.UrlMaps.Insert(project, &compute.UrlMap{
+					Name: redirect,
+					DefaultUrlRedirect: &compute.HttpRedirectAction{
+						HttpsRedirect:        true,
+						RedirectResponseCode: "MOVED_PERMANENTLY_DEFAULT",
+						StripQuery:           false,
+						ForceSendFields:      []string{"StripQuery"},
+					},
+				}).Do()
+			},
+			delete: func() (*compute.Operation, error) {
+				return client.UrlMaps.Delete(project, redirect).Do()
+			},
+		},
+		{
+			kind: "target HTTP proxy",
+			name: redirect,
+			insert: func() (*compute.Operation, error) {
+				return client.TargetHttpProxies.Insert(project, &compute.TargetHttpProxy{
+					Name:   redirect,
+					UrlMap: fmt.Sprintf("projects/%s/global/urlMaps/%s", project, redirect),
+				}).Do()
+			},
+			delete: func() (*compute.Operation, error) {
+				return client.TargetHttpProxies.Delete(project, redirect).Do()
+			},
+		},
+		{
+			kind: "global forwarding rule",
+			name: redirect,
+			insert: func() (*compute.Operation, error) {
+				return client.GlobalForwardingRules.Insert(project, &compute.ForwardingRule{
+					Name:                redirect,
+					IPAddress:           fmt.Sprintf("projects/%s/global/addresses/%s", project, name),
+					IPProtocol:          "TCP",
+					PortRange:           "80",
+					Target:              fmt.Sprintf("projects/%s/global/targetHttpProxies/%s", project, redirect),
+					LoadBalancingScheme: scheme,
+				}).Do()
+			},
+			delete: func() (*compute.Operation, error) {
+				return client.GlobalForwardingRules.Delete(project, redirect).Do()
+			},
+		},
+	}
+}
+
+// expandComputeHttpsLoadBalancerUrlMap returns the routing of the URL map.
+// Each host_rule gets its own path matcher.
+func expandComputeHttpsLoadBalancerUrlMap(d *schema.ResourceData) *compute.UrlMap {
+	urlMap := &compute.UrlMap{
+		DefaultService: d.Get("default_backend").(string),
+	}
+	for i, raw := range d.Get("host_rule").([]interface{}) {
+		hostRule := raw.(map[string]interface{})
+		matcher := fmt.Sprintf("host-rule-%d", i)
+		urlMap.HostRules = append(urlMap.HostRules, &compute.HostRule{
+			Hosts:       tpgresource.ConvertStringArr(hostRule["hosts"].([]interface{})),
+			PathMatcher: matcher,
+		})
+
+		pathMatcher := &compute.PathMatcher{
+			Name:           matcher,
+			DefaultService: hostRule["backend"].(string),
+		}
+		for _, raw := range hostRule["path_rule"].([]interface{}) {
+			pathRule := raw.(map[string]interface{})
+			pathMatcher.PathRules = append(pathMatcher.PathRules, &compute.PathRule{
+				Paths:   tpgresource.ConvertStringArr(pathRule["paths"].([]interface{})),
+				Service: pathRule["backend"].(string),
+			})
+		}
+		urlMap.PathMatchers = append(urlMap.PathMatchers, pathMatcher)
+	}
+	return urlMap
+}
+
+func flattenComputeHttpsLoadBalancerHostRules(urlMap *compute.UrlMap) []interface{} {
+	pathMatchers := make(map[string]*compute.PathMatcher, len(urlMap.PathMatchers))
+	for _, pathMatcher := range urlMap.PathMatchers {
+		pathMatchers[pathMatcher.Name] = pathMatcher
+	}
+
+	transformed := make([]interface{}, 0, len(urlMap.HostRules))
+	for _, hostRule := range urlMap.HostRules {
+		pathMatcher, ok := pathMatchers[hostRule.PathMatcher]
+		if !ok {
+			continue
+		}
+		pathRules := make([]interface{}, 0, len(pathMatcher.PathRules))
+		for _, pathRule := range pathMatcher.PathRules {
+			pathRules = append(pathRules, map[string]interface{}{
+				"paths":   tpgresource.ConvertStringArrToInterface(pathRule.Paths),
+				"backend": pathRule.Service,
+			})
+		}
+		transformed = append(transformed, map[string]interface{}{
+			"hosts":     tpgresource.ConvertStringArrToInterface(hostRule.Hosts),
+			"backend":   pathMatcher.DefaultService,
+			"path_rule": pathRules,
+		})
+	}
+	return transformed
+}
+
+func computeHttpsLoadBalancerWaiter(config *transport_tpg.Config, project, userAgent string, timeout time.Duration) computeHttpsLoadBalancerWait {
+	return func(op *compute.Operation, activity string) error {
+		return ComputeOperationWaitTime(config, op, project, activity, userAgent, timeout)
+	}
+}
+
+func resourceComputeHttpsLoadBalancerCreate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	project, err := tpgresource.GetProject(d, config)
+	if err != nil {
+		return err
+	}
+
+	client := config.NewComputeClient(userAgent)
+	parts := computeHttpsLoadBalancerParts(d, client, project)
+	if d.Get("http_redirect").(bool) {
+		parts = append(parts, computeHttpsLoadBalancerRedirectParts(d, client, project)...)
+	}
+
+	log.Printf("[DEBUG] Creating HTTPS load balancer %q", d.Get("name").(string))
+	leftover, err := createComputeHttpsLoadBalancerParts(parts, computeHttpsLoadBalancerWaiter(config, project, userAgent, d.Timeout(schema.TimeoutCreate)))
+	if leftover {
+		// Keep the load balancer in state, so it's tainted and whatever is
+		// left of it gets deleted on the next apply.
+		d.SetId(fmt.Sprintf("%s/%s", project, d.Get("name").(string)))
+	}
+	if err != nil {
+		return err
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s", project, d.Get("name").(string)))
+
+	return resourceComputeHttpsLoadBalancerRead(d, meta)
+}
+
+func resourceComputeHttpsLoadBalancerRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	project, err := tpgresource.GetProject(d, config)
+	if err != nil {
+		return err
+	}
+
+	client := config.NewComputeClient(userAgent)
+	name := d.Get("name").(string)
+
+	// The address is created first and deleted last, so the load balancer
+	// exists as long as it does.
+	address, err := client.GlobalAddresses.Get(project, name).Do()
+	if err != nil {
+		return transport_tpg.HandleNotFoundError(err, d, fmt.Sprintf("HTTPS load balancer %q", name))
+	}
+	if err := d.Set("ip_address", address.Address); err != nil {
+		return fmt.Errorf("Error setting ip_address: %s", err)
+	}
+
+	urlMap, err := client.UrlMaps.Get(project, name).Do()
+	if err != nil && !transport_tpg.IsGoogleApiErrorWithCode(err, 404) {
+		return fmt.Errorf("Error reading URL map %q: %s", name, err)
+	}
+	if urlMap != nil {
+		if err := d.Set("url_map", urlMap.SelfLink); err != nil {
+			return fmt.Errorf("Error setting url_map: %s", err)
+		}
+		if err := d.Set("default_backend", urlMap.DefaultService); err != nil {
+			return fmt.Errorf("Error setting default_backend: %s", err)
+		}
+		if err := d.Set("host_rule", flattenComputeHttpsLoadBalancerHostRules(urlMap)); err != nil {
+			return fmt.Errorf("Error setting host_rule: %s", err)
+		}
+	}
+
+	proxy, err := client.TargetHttpsProxies.Get(project, name).Do()
+	if err != nil && !transport_tpg.IsGoogleApiErrorWithCode(err, 404) {
+		return fmt.Errorf("Error reading target HTTPS proxy %q: %s", name, err)
+	}
+	if proxy != nil {
+		if err := d.Set("target_https_proxy", proxy.SelfLink); err != nil {
+			return fmt.Errorf("Error setting target_https_proxy: %s", err)
+		}
+		if err := d.Set("ssl_policy", proxy.SslPolicy); err != nil {
+			return fmt.Errorf("Error setting ssl_policy: %s", err)
+		}
+		retired := []string{}
+		if len(proxy.SslCertificates) > 0 {
+			if err := readComputeHttpsLoadBalancerCertificate(d, client, project, tpgresource.GetResourceNameFromSelfLink(proxy.SslCertificates[0])); err != nil {
+				return err
+			}
+			// Certificates after the first are the ones it replaced.
+			retired = append(retired, proxy.SslCertificates[1:]...)
+		}
+		if err := d.Set("retired_certificates", retired); err != nil {
+			return fmt.Errorf("Error setting retired_certificates: %s", err)
+		}
+	}
+
+	forwardingRule, err := client.GlobalForwardingRules.Get(project, name).Do()
+	if err != nil && !transport_tpg.IsGoogleApiErrorWithCode(err, 404) {
+		return fmt.Errorf("Error reading global forwarding rule %q: %s", name, err)
+	}
+	if forwardingRule != nil {
+		if err := d.Set("forwarding_rule", forwardingRule.SelfLink); err != nil {
+			return fmt.Errorf("Error setting forwarding_rule: %s", err)
+		}
+		if err := d.Set("load_balancing_scheme", forwardingRule.LoadBalancingScheme); err != nil {
+			return fmt.Errorf("Error setting load_balancing_scheme: %s", err)
+		}
+	}
+
+	redirect, err := client.GlobalForwardingRules.Get(project, computeHttpsLoadBalancerRedirectName(name)).Do()
+	if err != nil && !transport_tpg.IsGoogleApiErrorWithCode(err, 404) {
+		return fmt.Errorf("Error reading global forwarding rule %q: %s", computeHttpsLoadBalancerRedirectName(name), err)
+	}
+	redirectLink := ""
+	if redirect != nil {
+		redirectLink = redirect.SelfLink
+	}
+	if err := d.Set("http_redirect", redirect != nil); err != nil {
+		return fmt.Errorf("Error setting http_redirect: %s", err)
+	}
+	if err := d.Set("http_redirect_forwarding_rule", redirectLink); err != nil {
+		return fmt.Errorf("Error setting http_redirect_forwarding_rule: %s", err)
+	}
+
+	if err := d.Set("project", project); err != nil {
+		return fmt.Errorf("Error setting project: %s", err)
+	}
+
+	return nil
+}
+
+func readComputeHttpsLoadBalancerCertificate(d *schema.ResourceData, client *compute.Service, project, name string) error {
+	cert, err := client.SslCertificates.Get(project, name).Do()
+	if err != nil {
+		if transport_tpg.IsGoogleApiErrorWithCode(err, 404) {
+			return nil
+		}
+		return fmt.Errorf("Error reading SSL certificate %q: %s", name, err)
+	}
+
+	if err := d.Set("certificate", cert.SelfLink); err != nil {
+		return fmt.Errorf("Error setting certificate: %s", err)
+	}
+	if cert.Managed == nil {
+		return nil
+	}
+	domains := computeHttpsLoadBalancerDomains(tpgresource.ConvertStringArr(d.Get("domains").([]interface{})), cert.Managed.Domains)
+	if err := d.Set("domains", domains); err != nil {
+		return fmt.Errorf("Error setting domains: %s", err)
+	}
+	if err := d.Set("certificate_status", cert.Managed.Status); err != nil {
+		return fmt.Errorf("Error setting certificate_status: %s", err)
+	}
+	if err := d.Set("certificate_domain_status", cert.Managed.DomainStatus); err != nil {
+		return fmt.Errorf("Error setting certificate_domain_status: %s", err)
+	}
+	return nil
+}
+
+func resourceComputeHttpsLoadBalancerUpdate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	project, err := tpgresource.GetProject(d, config)
+	if err != nil {
+		return err
+	}
+
+	client := config.NewComputeClient(userAgent)
+	wait := computeHttpsLoadBalancerWaiter(config, project, userAgent, d.Timeout(schema.TimeoutUpdate))
+	name := d.Get("name").(string)
+
+	if d.HasChanges("default_backend", "host_rule") {
+		urlMap, err := client.UrlMaps.Get(project, name).Do()
+		if err != nil {
+			return fmt.Errorf("Error reading URL map %q: %s", name, err)
+		}
+		routing := expandComputeHttpsLoadBalancerUrlMap(d)
+		urlMap.DefaultService = routing.DefaultService
+		urlMap.HostRules = routing.HostRules
+		urlMap.PathMatchers = routing.PathMatchers
+
+		op, err := client.UrlMaps.Update(project, name, urlMap).Do()
+		if err == nil {
+			err = wait(op, fmt.Sprintf("Updating URL map %q", name))
+		}
+		if err != nil {
+			return fmt.Errorf("Error updating URL map %q: %s", name, err)
+		}
+	}
+
+	if d.HasChange("domains") {
+		certificate := computeHttpsLoadBalancerCertificateName(name, tpgresource.ConvertStringArr(d.Get("domains").([]interface{})))
+		old := computeHttpsLoadBalancerOldCertificate(d)
+		if certificate != old {
+			// A new managed certificate is PROVISIONING for up to an hour, so
+			// the ones it replaces stay attached after it, serving the old
+			// domains, until it's ACTIVE.
+			part := computeHttpsLoadBalancerCertificatePart(client, project, certificate, tpgresource.ConvertStringArr(d.Get("domains").([]interface{})))
+			if _, err := createComputeHttpsLoadBalancerParts([]*computeHttpsLoadBalancerPart{part}, wait); err != nil {
+				return err
+			}
+
+			certificates := []string{fmt.Sprintf("projects/%s/global/sslCertificates/%s", project, certificate)}
+			if old != "" {
+				certificates = append(certificates, fmt.Sprintf("projects/%s/global/sslCertificates/%s", project, old))
+			}
+			retired, _ := d.GetChange("retired_certificates")
+			certificates = append(certificates, tpgresource.ConvertStringArr(retired.([]interface{}))...)
+			if err := setComputeHttpsLoadBalancerCertificates(client, project, name, certificates, wait); err != nil {
+				if rbErr := deleteComputeHttpsLoadBalancerParts([]*computeHttpsLoadBalancerPart{part}, wait); rbErr != nil {
+					return fmt.Errorf("%s; rolling back failed: %s", err, rbErr)
+				}
+				return err
+			}
+		}
+	} else if d.HasChange("retired_certificates") {
+		// The plan only retires certificates once the current one is ACTIVE.
+		retired, _ := d.GetChange("retired_certificates")
+		if err := setComputeHttpsLoadBalancerCertificates(client, project, name, []string{d.Get("certificate").(string)}, wait); err != nil {
+			return err
+		}
+		if err := deleteComputeHttpsLoadBalancerParts(computeHttpsLoadBalancerRetiredCertificateParts(client, project, retired.([]interface{})), wait); err != nil {
+			return err
+		}
+	}
+
+	if d.HasChange("ssl_policy") {
+		op, err := client.TargetHttpsProxies.SetSslPolicy(project, name, &compute.SslPolicyReference{
+			SslPolicy:       d.Get("ssl_policy").(string),
+			ForceSendFields: []string{"SslPolicy"},
+		}).Do()
+		if err == nil {
+			err = wait(op, fmt.Sprintf("Updating target HTTPS proxy %q", name))
+		}
+		if err != nil {
+			return fmt.Errorf("Error updating the SSL policy of target HTTPS proxy %q: %s", name, err)
+		}
+	}
+
+	if d.HasChange("http_redirect") {
+		parts := computeHttpsLoadBalancerRedirectParts(d, client, project)
+		if d.Get("http_redirect").(bool) {
+			if _, err := createComputeHttpsLoadBalancerParts(parts, wait); err != nil {
+				return err
+			}
+		} else if err := deleteComputeHttpsLoadBalancerParts(parts, wait); err != nil {
+			return err
+		}
+	}
+
+	return resourceComputeHttpsLoadBalancerRead(d, meta)
+}
+
+func setComputeHttpsLoadBalancerCertificates(client *compute.Service, project, name string, certificates []string, wait computeHttpsLoadBalancerWait) error {
+	op, err := client.TargetHttpsProxies.SetSslCertificates(project, name, &compute.TargetHttpsProxiesSetSslCertificatesRequest{
+		SslCertificates: certificates,
+	}).Do()
+	if err == nil {
+		err = wait(op, fmt.Sprintf("Updating target HTTPS proxy %q", name))
+	}
+	if err != nil {
+		return fmt.Errorf("Error updating the certificates of target HTTPS proxy %q: %s", name, err)
+	}
+	return nil
+}
+
+func computeHttpsLoadBalancerRetiredCertificateParts(client *compute.Service, project string, retired []interface{}) []*computeHttpsLoadBalancerPart {
+	parts := make([]*computeHttpsLoadBalancerPart, 0, len(retired))
+	for _, certificate := range retired {
+		parts = append(parts, computeHttpsLoadBalancerCertificatePart(client, project, tpgresource.GetResourceNameFromSelfLink(certificate.(string)), nil))
+	}
+	return parts
+}
+
+func resourceComputeHttpsLoadBalancerDelete(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	project, err := tpgresource.GetProject(d, config)
+	if err != nil {
+		return err
+	}
+
+	client := config.NewComputeClient(userAgent)
+	parts := computeHttpsLoadBalancerParts(d, client, project)
+	if certificate := d.Get("certificate").(string); certificate != "" {
+		// The certificate in use may be named after older domains.
+		parts[1] = computeHttpsLoadBalancerCertificatePart(client, project, tpgresource.GetResourceNameFromSelfLink(certificate), nil)
+	}
+	// Retired certificates are deleted once the proxy is gone, like the current one.
+	retired := computeHttpsLoadBalancerRetiredCertificateParts(client, project, d.Get("retired_certificates").([]interface{}))
+	parts = append(parts[:2], append(retired, parts[2:]...)...)
+	// The redirect is always deleted, in case it's left over from a failed update.
+	parts = append(parts, computeHttpsLoadBalancerRedirectParts(d, client, project)...)
+
+	log.Printf("[DEBUG] Deleting HTTPS load balancer %q", d.Get("name").(string))
+	if err := deleteComputeHttpsLoadBalancerParts(parts, computeHttpsLoadBalancerWaiter(config, project, userAgent, d.Timeout(schema.TimeoutDelete))); err != nil {
+		return err
+	}
+
+	d.SetId("")
+	return nil
+}
+
+func resourceComputeHttpsLoadBalancerImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	config := meta.(*transport_tpg.Config)
+	if err := tpgresource.ParseImportId([]string{
+		"(?P<project>[^/]+)/(?P<name>[^/]+)",
+		"(?P<name>[^/]+)",
+	}, d, config); err != nil {
+		return nil, err
+	}
+
+	id, err := tpgresource.ReplaceVars(d, config, "{{project}}/{{name}}")
+	if err != nil {
+		return nil, fmt.Errorf("Error constructing id: %s", err)
+	}
+	d.SetId(id)
+
+	return []*schema.ResourceData{d}, nil
+}
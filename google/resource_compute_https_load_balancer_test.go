@@ -0,0 +1,322 @@
+package google
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/googleapi"
+
+	"github.com/hashicorp/terraform-provider-google/google/acctest"
+)
+
+// fakeComputeHttpsLoadBalancerParts returns parts recording their calls in
+// calls, where inserting the part named failInsert and deleting the one named
+// failDelete fail.
+func fakeComputeHttpsLoadBalancerParts(names []string, failInsert, failDelete string, calls *[]string) []*computeHttpsLoadBalancerPart {
+	parts := []*computeHttpsLoadBalancerPart{}
+	for _, name := range names {
+		name := name
+		parts = append(parts, &computeHttpsLoadBalancerPart{
+			kind: "fake",
+			name: name,
+			insert: func() (*compute.Operation, error) {
+				*calls = append(*calls, "insert "+name)
+				if name == failInsert {
+					return nil, fmt.Errorf("quota exceeded")
+				}
+				return &compute.Operation{}, nil
+			},
+			delete: func() (*compute.Operation, error) {
+				*calls = append(*calls, "delete "+name)
+				if name == failDelete {
+					return nil, fmt.Errorf("resource in use")
+				}
+				if name == "missing" {
+					return nil, &googleapi.Error{Code: 404}
+				}
+				return &compute.Operation{}, nil
+			},
+		})
+	}
+	return parts
+}
+
+func TestCreateComputeHttpsLoadBalancerParts(t *testing.T) {
+	t.Parallel()
+
+	wait := func(op *compute.Operation, activity string) error { return nil }
+	names := []string{"address", "certificate", "url-map", "proxy", "forwarding-rule"}
+
+	cases := map[string]struct {
+		FailInsert string
+		FailDelete string
+		Calls      []string
+		Leftover   bool
+		Error      string
+	}{
+		"success": {
+			Calls: []string{"insert address", "insert certificate", "insert url-map", "insert proxy", "insert forwarding-rule"},
+		},
+		"failure is rolled back in reverse order": {
+			FailInsert: "proxy",
+			Calls:      []string{"insert address", "insert certificate", "insert url-map", "insert proxy", "delete url-map", "delete certificate", "delete address"},
+			Error:      "quota exceeded",
+		},
+		"failed rollback leaves parts behind": {
+			FailInsert: "proxy",
+			FailDelete: "certificate",
+			Calls:      []string{"insert address", "insert certificate", "insert url-map", "insert proxy", "delete url-map", "delete certificate"},
+			Leftover:   true,
+			Error:      "rolling back failed",
+		},
+	}
+
+	for tn, tc := range cases {
+		calls := []string{}
+		parts := fakeComputeHttpsLoadBalancerParts(names, tc.FailInsert, tc.FailDelete, &calls)
+		leftover, err := createComputeHttpsLoadBalancerParts(parts, wait)
+		if !reflect.DeepEqual(calls, tc.Calls) {
+			t.Errorf("%s: got calls %v, expected %v", tn, calls, tc.Calls)
+		}
+		if leftover != tc.Leftover {
+			t.Errorf("%s: got leftover %t, expected %t", tn, leftover, tc.Leftover)
+		}
+		if tc.Error == "" && err != nil {
+			t.Errorf("%s: unexpected error: %s", tn, err)
+		}
+		if tc.Error != "" && (err == nil || !strings.Contains(err.Error(), tc.Error)) {
+			t.Errorf("%s: expected error containing %q, got %v", tn, tc.Error, err)
+		}
+	}
+}
+
+func TestDeleteComputeHttpsLoadBalancerParts_skipsMissing(t *testing.T) {
+	t.Parallel()
+
+	calls := []string{}
+	parts := fakeComputeHttpsLoadBalancerParts([]string{"address", "missing", "proxy"}, "", "", &calls)
+	if err := deleteComputeHttpsLoadBalancerParts(parts, func(op *compute.Operation, activity string) error { return nil }); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if expected := []string{"delete proxy", "delete missing", "delete address"}; !reflect.DeepEqual(calls, expected) {
+		t.Errorf("got calls %v, expected %v", calls, expected)
+	}
+}
+
+func TestComputeHttpsLoadBalancerCertificateName(t *testing.T) {
+	t.Parallel()
+
+	a := computeHttpsLoadBalancerCertificateName("lb", []string{"example.com", "www.example.com"})
+	b := computeHttpsLoadBalancerCertificateName("lb", []string{"example.com"})
+	if len(a) != len("lb")+9 || !strings.HasPrefix(a, "lb-") {
+		t.Errorf("unexpected certificate name %q", a)
+	}
+	if a == b {
+		t.Errorf("expected different domains to give different certificate names, got %q", a)
+	}
+}
+
+func TestComputeHttpsLoadBalancerOldCertificate(t *testing.T) {
+	t.Parallel()
+
+	r := ResourceComputeHttpsLoadBalancer()
+	backend := "projects/p/global/backendServices/web"
+	certificate := "https://www.googleapis.com/compute/v1/projects/p/global/sslCertificates/lb-12345678"
+	state := &terraform.InstanceState{
+		ID: "projects/p/global/httpsLoadBalancers/lb",
+		Attributes: map[string]string{
+			"id":              "projects/p/global/httpsLoadBalancers/lb",
+			"name":            "lb",
+			"project":         "p",
+			"domains.#":       "1",
+			"domains.0":       "example.com",
+			"default_backend": backend,
+			"certificate":     certificate,
+		},
+	}
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"name":            "lb",
+		"project":         "p",
+		"domains":         []interface{}{"example.com", "www.example.com"},
+		"default_backend": backend,
+	})
+
+	// Build the data seen by the update from the plan, where the certificate
+	// is unknown.
+	diff, err := r.SimpleDiff(context.Background(), state, config, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	d, err := schema.InternalMap(r.Schema).Data(state, diff)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got := d.Get("certificate").(string); got != "" {
+		t.Fatalf("expected the certificate to be unknown during the update, got %q", got)
+	}
+
+	if got, expected := computeHttpsLoadBalancerOldCertificate(d), "lb-12345678"; got != expected {
+		t.Errorf("got %q, expected %q", got, expected)
+	}
+}
+
+func TestComputeHttpsLoadBalancerRetireCertificates(t *testing.T) {
+	t.Parallel()
+
+	r := ResourceComputeHttpsLoadBalancer()
+	backend := "projects/p/global/backendServices/web"
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"name":            "lb",
+		"project":         "p",
+		"domains":         []interface{}{"example.com", "www.example.com"},
+		"default_backend": backend,
+	})
+
+	for _, status := range []string{"PROVISIONING", "ACTIVE"} {
+		state := &terraform.InstanceState{
+			ID: "projects/p/global/httpsLoadBalancers/lb",
+			Attributes: map[string]string{
+				"id":                     "projects/p/global/httpsLoadBalancers/lb",
+				"name":                   "lb",
+				"project":                "p",
+				"domains.#":              "2",
+				"domains.0":              "example.com",
+				"domains.1":              "www.example.com",
+				"default_backend":        backend,
+				"http_redirect":          "true",
+				"load_balancing_scheme":  "EXTERNAL_MANAGED",
+				"certificate":            "https://www.googleapis.com/compute/v1/projects/p/global/sslCertificates/lb-22222222",
+				"certificate_status":     status,
+				"retired_certificates.#": "1",
+				"retired_certificates.0": "https://www.googleapis.com/compute/v1/projects/p/global/sslCertificates/lb-11111111",
+			},
+		}
+
+		diff, err := r.SimpleDiff(context.Background(), state, config, nil)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", status, err)
+		}
+		retire := diff != nil && diff.Attributes["retired_certificates.#"] != nil && diff.Attributes["retired_certificates.#"].New == "0"
+		if expected := status == "ACTIVE"; retire != expected {
+			t.Errorf("%s: got retiring certificates %v, expected %v", status, retire, expected)
+		}
+	}
+}
+
+func TestComputeHttpsLoadBalancerDomains(t *testing.T) {
+	t.Parallel()
+
+	current := []string{"www.example.com", "example.com"}
+	if got := computeHttpsLoadBalancerDomains(current, []string{"example.com", "www.example.com"}); !reflect.DeepEqual(got, current) {
+		t.Errorf("got %v, expected the configured order %v", got, current)
+	}
+	returned := []string{"example.com", "api.example.com"}
+	if got := computeHttpsLoadBalancerDomains(current, returned); !reflect.DeepEqual(got, returned) {
+		t.Errorf("got %v, expected the returned domains %v", got, returned)
+	}
+}
+
+func TestAccComputeHttpsLoadBalancer_basic(t *testing.T) {
+	t.Parallel()
+
+	context := map[string]interface{}{
+		"random_suffix": RandString(t, 10),
+	}
+
+	VcrTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.AccTestPreCheck(t) },
+		ProtoV5ProviderFactories: ProtoV5ProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccComputeHttpsLoadBalancer_basic(context, "true"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("google_compute_https_load_balancer.default", "ip_address"),
+					resource.TestCheckResourceAttrSet("google_compute_https_load_balancer.default", "certificate_status"),
+					resource.TestCheckResourceAttrSet("google_compute_https_load_balancer.default", "http_redirect_forwarding_rule"),
+				),
+			},
+			{
+				ResourceName:      "google_compute_https_load_balancer.default",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config: testAccComputeHttpsLoadBalancer_update(context),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("google_compute_https_load_balancer.default", "http_redirect_forwarding_rule", ""),
+				),
+			},
+			{
+				ResourceName:      "google_compute_https_load_balancer.default",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccComputeHttpsLoadBalancer_backends(context map[string]interface{}) string {
+	return Nprintf(`
+resource "google_compute_backend_bucket" "static" {
+  name        = "tf-test-static-%{random_suffix}"
+  bucket_name = google_storage_bucket.static.name
+}
+
+resource "google_storage_bucket" "static" {
+  name     = "tf-test-static-%{random_suffix}"
+  location = "US"
+}
+
+resource "google_compute_backend_bucket" "images" {
+  name        = "tf-test-images-%{random_suffix}"
+  bucket_name = google_storage_bucket.images.name
+}
+
+resource "google_storage_bucket" "images" {
+  name     = "tf-test-images-%{random_suffix}"
+  location = "US"
+}
+`, context)
+}
+
+func testAccComputeHttpsLoadBalancer_basic(context map[string]interface{}, httpRedirect string) string {
+	context["http_redirect"] = httpRedirect
+	return testAccComputeHttpsLoadBalancer_backends(context) + Nprintf(`
+resource "google_compute_https_load_balancer" "default" {
+  name                  = "tf-test-lb-%{random_suffix}"
+  domains               = ["tf-test-%{random_suffix}.example.com"]
+  default_backend       = google_compute_backend_bucket.static.self_link
+  load_balancing_scheme = "EXTERNAL"
+  http_redirect         = %{http_redirect}
+}
+`, context)
+}
+
+func testAccComputeHttpsLoadBalancer_update(context map[string]interface{}) string {
+	return testAccComputeHttpsLoadBalancer_backends(context) + Nprintf(`
+resource "google_compute_https_load_balancer" "default" {
+  name                  = "tf-test-lb-%{random_suffix}"
+  domains               = ["tf-test-%{random_suffix}.example.com", "www.tf-test-%{random_suffix}.example.com"]
+  default_backend       = google_compute_backend_bucket.static.self_link
+  load_balancing_scheme = "EXTERNAL"
+  http_redirect         = false
+
+  host_rule {
+    hosts   = ["www.tf-test-%{random_suffix}.example.com"]
+    backend = google_compute_backend_bucket.static.self_link
+
+    path_rule {
+      paths   = ["/images/*"]
+      backend = google_compute_backend_bucket.images.self_link
+    }
+  }
+}
+`, context)
+}
@@ -0,0 +1,213 @@
+package google
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/hashicorp/terraform-provider-google/google/tpgresource"
+	transport_tpg "github.com/hashicorp/terraform-provider-google/google/transport"
+	"github.com/hashicorp/terraform-provider-google/google/verify"
+)
+
+// ResourceComputeImageLock records the concrete image each of a set of image
+// references (families, shorthands, self links) resolved to, in the manner
+// of a lockfile. Entries are only re-resolved when their reference changes or
+// when image_refresh_after has elapsed since the last resolution, so newly
+// published family images don't cause unplanned instance replacements.
+func ResourceComputeImageLock() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceComputeImageLockCreate,
+		Read:   resourceComputeImageLockRead,
+		Update: resourceComputeImageLockUpdate,
+		Delete: resourceComputeImageLockDelete,
+
+		CustomizeDiff: resourceComputeImageLockCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			"images": {
+				Type:        schema.TypeMap,
+				Required:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: `A map of arbitrary keys to image references. A reference may be anything accepted by boot_disk.initialize_params.image on google_compute_instance, such as debian-cloud/debian-11 or projects/debian-cloud/global/images/family/debian-11.`,
+			},
+			"image_refresh_after": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: verify.ValidateNonNegativeDuration(),
+				Description:  `A duration such as "720h". Once this much time has passed since the images were last resolved, the next plan re-resolves every reference. If unset, references are only re-resolved when they change.`,
+			},
+			"project": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: `The project used to resolve references that don't name one. If it is not provided, the provider project is used.`,
+			},
+			"resolved": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: `A map with the same keys as images, whose values are the self links of the concrete images the references resolved to.`,
+			},
+			"resolved_at": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The time every reference was last resolved, in RFC3339 format. Adding or changing a single reference does not reset it.`,
+			},
+		},
+		UseJSONNumber: true,
+	}
+}
+
+// resourceComputeImageLockCustomizeDiff is the only place the clock is read to
+// decide whether the lock has expired: a refresh is planned by marking both
+// resolved_at and resolved unknown, and Update acts on that plan.
+func resourceComputeImageLockCustomizeDiff(_ context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+	// Nothing has been resolved yet; everything is already unknown.
+	if diff.Id() == "" {
+		return nil
+	}
+
+	due, err := imageLockRefreshDue(diff.Get("resolved_at").(string), diff.Get("image_refresh_after").(string), time.Now())
+	if err != nil {
+		return err
+	}
+
+	if due {
+		if err := diff.SetNewComputed("resolved_at"); err != nil {
+			return err
+		}
+	}
+
+	if due || diff.HasChange("images") {
+		return diff.SetNewComputed("resolved")
+	}
+
+	return nil
+}
+
+// imageLockRefreshDue reports whether refreshAfter has elapsed between
+// resolvedAt and now. An empty refreshAfter means the lock never expires.
+func imageLockRefreshDue(resolvedAt, refreshAfter string, now time.Time) (bool, error) {
+	if refreshAfter == "" || resolvedAt == "" {
+		return false, nil
+	}
+
+	after, err := time.ParseDuration(refreshAfter)
+	if err != nil {
+		return false, fmt.Errorf("Error parsing image_refresh_after %q: %s", refreshAfter, err)
+	}
+
+	at, err := time.Parse(time.RFC3339, resolvedAt)
+	if err != nil {
+		return false, fmt.Errorf("Error parsing resolved_at %q: %s", resolvedAt, err)
+	}
+
+	return !now.Before(at.Add(after)), nil
+}
+
+func resourceComputeImageLockCreate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+
+	project, err := tpgresource.GetProject(d, config)
+	if err != nil {
+		return err
+	}
+	if err := d.Set("project", project); err != nil {
+		return fmt.Errorf("Error setting project: %s", err)
+	}
+
+	if err := resourceComputeImageLockResolve(d, config, project, nil); err != nil {
+		return err
+	}
+	if err := d.Set("resolved_at", time.Now().UTC().Format(time.RFC3339)); err != nil {
+		return fmt.Errorf("Error setting resolved_at: %s", err)
+	}
+
+	d.SetId(fmt.Sprintf("projects/%s/global/imageLocks/%s", project, resource.UniqueId()))
+
+	return resourceComputeImageLockRead(d, meta)
+}
+
+// The lock only lives in state, so there is nothing to refresh from the API.
+func resourceComputeImageLockRead(d *schema.ResourceData, meta interface{}) error {
+	return nil
+}
+
+func resourceComputeImageLockUpdate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+
+	project, err := tpgresource.GetProject(d, config)
+	if err != nil {
+		return err
+	}
+
+	// Keep existing pins for references that didn't change, unless the
+	// whole lock has expired. Whether it has is decided once, by the plan
+	// marking resolved_at unknown, so apply can't disagree with it.
+	due := d.HasChange("resolved_at")
+
+	keep := make(map[string]string)
+	if !due {
+		oldImages, _ := d.GetChange("images")
+		oldResolved, _ := d.GetChange("resolved")
+		newImages := d.Get("images").(map[string]interface{})
+		for k, ref := range oldImages.(map[string]interface{}) {
+			pinned, ok := oldResolved.(map[string]interface{})[k]
+			if ok && newImages[k] == ref {
+				keep[k] = pinned.(string)
+			}
+		}
+	}
+
+	if err := resourceComputeImageLockResolve(d, config, project, keep); err != nil {
+		return err
+	}
+	if due {
+		if err := d.Set("resolved_at", time.Now().UTC().Format(time.RFC3339)); err != nil {
+			return fmt.Errorf("Error setting resolved_at: %s", err)
+		}
+	}
+
+	return resourceComputeImageLockRead(d, meta)
+}
+
+// resourceComputeImageLockResolve resolves every entry of images that isn't
+// present in keep and stores the result in resolved.
+func resourceComputeImageLockResolve(d *schema.ResourceData, config *transport_tpg.Config, project string, keep map[string]string) error {
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	resolved := make(map[string]string)
+	for k, ref := range d.Get("images").(map[string]interface{}) {
+		if pinned, ok := keep[k]; ok {
+			resolved[k] = pinned
+			continue
+		}
+
+		log.Printf("[DEBUG] Resolving image reference %q for key %q", ref.(string), k)
+		image, err := resolveImageToImage(config, project, ref.(string), userAgent)
+		if err != nil {
+			return fmt.Errorf("Error resolving image %q for key %q: %s", ref.(string), k, err)
+		}
+		resolved[k] = image.SelfLink
+	}
+
+	if err := d.Set("resolved", resolved); err != nil {
+		return fmt.Errorf("Error setting resolved: %s", err)
+	}
+
+	return nil
+}
+
+func resourceComputeImageLockDelete(d *schema.ResourceData, meta interface{}) error {
+	d.SetId("")
+	return nil
+}
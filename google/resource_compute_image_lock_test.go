@@ -0,0 +1,173 @@
+package google
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/hashicorp/terraform-provider-google/google/acctest"
+)
+
+func TestImageLockRefreshDue(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	cases := map[string]struct {
+		ResolvedAt   string
+		RefreshAfter string
+		Expected     bool
+		ExpectError  bool
+	}{
+		"no refresh interval": {
+			ResolvedAt: "2020-01-01T00:00:00Z",
+			Expected:   false,
+		},
+		"never resolved": {
+			RefreshAfter: "24h",
+			Expected:     false,
+		},
+		"not yet expired": {
+			ResolvedAt:   "2023-06-01T00:00:00Z",
+			RefreshAfter: "24h",
+			Expected:     false,
+		},
+		"expired": {
+			ResolvedAt:   "2023-05-01T00:00:00Z",
+			RefreshAfter: "720h",
+			Expected:     true,
+		},
+		"expires exactly now": {
+			ResolvedAt:   "2023-06-01T11:00:00Z",
+			RefreshAfter: "1h",
+			Expected:     true,
+		},
+		"bad timestamp": {
+			ResolvedAt:   "yesterday",
+			RefreshAfter: "1h",
+			ExpectError:  true,
+		},
+	}
+
+	for tn, tc := range cases {
+		due, err := imageLockRefreshDue(tc.ResolvedAt, tc.RefreshAfter, now)
+		if tc.ExpectError {
+			if err == nil {
+				t.Errorf("%s: expected an error", tn)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tn, err)
+			continue
+		}
+		if due != tc.Expected {
+			t.Errorf("%s: expected %t, got %t", tn, tc.Expected, due)
+		}
+	}
+}
+
+func TestComputeImageLockPlannedRefresh(t *testing.T) {
+	t.Parallel()
+
+	r := ResourceComputeImageLock()
+	resolvedAt := time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339)
+	state := &terraform.InstanceState{
+		ID: "projects/p/global/imageLocks/lock",
+		Attributes: map[string]string{
+			"id":                  "projects/p/global/imageLocks/lock",
+			"project":             "p",
+			"images.%":            "1",
+			"images.debian":       "debian-cloud/debian-11",
+			"image_refresh_after": "1h",
+			"resolved.%":          "1",
+			"resolved.debian":     "projects/debian-cloud/global/images/debian-11-v1",
+			"resolved_at":         resolvedAt,
+		},
+	}
+
+	cases := map[string]struct {
+		RefreshAfter string
+		Images       map[string]interface{}
+		Due          bool
+		Resolved     bool
+	}{
+		"expired": {
+			RefreshAfter: "1h",
+			Images:       map[string]interface{}{"debian": "debian-cloud/debian-11"},
+			Due:          true,
+			Resolved:     true,
+		},
+		"not expired": {
+			RefreshAfter: "3h",
+			Images:       map[string]interface{}{"debian": "debian-cloud/debian-11"},
+		},
+		"reference changed": {
+			RefreshAfter: "3h",
+			Images:       map[string]interface{}{"debian": "debian-cloud/debian-12"},
+			Resolved:     true,
+		},
+	}
+
+	for tn, tc := range cases {
+		config := terraform.NewResourceConfigRaw(map[string]interface{}{
+			"project":             "p",
+			"images":              tc.Images,
+			"image_refresh_after": tc.RefreshAfter,
+		})
+		diff, err := r.SimpleDiff(context.Background(), state, config, nil)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", tn, err)
+		}
+		// Build the data seen by the update from the plan.
+		d, err := schema.InternalMap(r.Schema).Data(state, diff)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", tn, err)
+		}
+		if got := d.HasChange("resolved_at"); got != tc.Due {
+			t.Errorf("%s: got resolved_at change %v, expected %v", tn, got, tc.Due)
+		}
+		if got := diff != nil && diff.Attributes["resolved.%"] != nil && diff.Attributes["resolved.%"].NewComputed; got != tc.Resolved {
+			t.Errorf("%s: got resolved unknown %v, expected %v", tn, got, tc.Resolved)
+		}
+	}
+}
+
+func TestAccComputeImageLock_basic(t *testing.T) {
+	t.Parallel()
+
+	VcrTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.AccTestPreCheck(t) },
+		ProtoV5ProviderFactories: ProtoV5ProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccComputeImageLock_basic("debian-cloud/debian-11"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("google_compute_image_lock.lock", "resolved.debian"),
+					resource.TestCheckResourceAttrSet("google_compute_image_lock.lock", "resolved_at"),
+				),
+			},
+			{
+				Config: testAccComputeImageLock_basic("debian-cloud/debian-12"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr("google_compute_image_lock.lock", "resolved.debian", regexp.MustCompile("debian-12")),
+				),
+			},
+		},
+	})
+}
+
+func testAccComputeImageLock_basic(ref string) string {
+	return fmt.Sprintf(`
+resource "google_compute_image_lock" "lock" {
+  images = {
+    debian = "%s"
+  }
+  image_refresh_after = "720h"
+}
+`, ref)
+}
@@ -787,6 +787,8 @@ func ResourceComputeInstance() *schema.Resource {
 				ValidateFunc: validation.StringInSlice([]string{"RUNNING", "TERMINATED"}, false),
 				Description:  `Desired status of the instance. Either "RUNNING" or "TERMINATED".`,
 			},
+			"wait_for": computeInstanceWaitForSchema(),
+
 			"current_status": {
 				Type:     schema.TypeString,
 				Computed: true,
@@ -1166,6 +1168,10 @@ func resourceComputeInstanceCreate(d *schema.ResourceData, meta interface{}) err
 		return fmt.Errorf("Error waiting for status: %s", err)
 	}
 
+	if err := waitForInstanceReadiness(d, config, project, zone.Name, instance.Name); err != nil {
+		return err
+	}
+
 	return resourceComputeInstanceRead(d, meta)
 }
 
@@ -2097,6 +2103,14 @@ func resourceComputeInstanceUpdate(d *schema.ResourceData, meta interface{}) err
 		}
 	}
 
+	// Adding or changing wait_for waits for the running instance again, so
+	// that its outcome is recorded for the current configuration.
+	if d.HasChange("wait_for") {
+		if err := waitForInstanceReadiness(d, config, project, zone, instance.Name); err != nil {
+			return err
+		}
+	}
+
 	// We made it, disable partial mode
 	d.Partial(false)
 
@@ -226,6 +226,10 @@ func resourceComputeInstanceFromTemplateCreate(d *schema.ResourceData, meta inte
 		return waitErr
 	}
 
+	if err := waitForInstanceReadiness(d, config, project, zone.Name, instance.Name); err != nil {
+		return err
+	}
+
 	return resourceComputeInstanceRead(d, meta)
 }
 
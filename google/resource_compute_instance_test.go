@@ -2459,6 +2459,87 @@ func TestAccComputeInstance_metadataStartupScript_update(t *testing.T) {
 	})
 }
 
+func TestAccComputeInstance_waitForSerialPortOutput(t *testing.T) {
+	t.Parallel()
+
+	var instance compute.Instance
+	var instanceName = fmt.Sprintf("tf-test-%s", RandString(t, 10))
+
+	VcrTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.AccTestPreCheck(t) },
+		ProtoV5ProviderFactories: ProtoV5ProviderFactories(t),
+		CheckDestroy:             testAccCheckComputeInstanceDestroyProducer(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccComputeInstance_waitForSerialPortOutput(instanceName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckComputeInstanceExists(
+						t, "google_compute_instance.foobar", &instance),
+					resource.TestCheckResourceAttr("google_compute_instance.foobar", "wait_for.0.ready", "true"),
+					resource.TestCheckResourceAttr("google_compute_instance.foobar", "wait_for.0.matched_value", "tf-startup-done"),
+					resource.TestCheckResourceAttrSet("google_compute_instance.foobar", "wait_for.0.ready_time"),
+				),
+			},
+			computeInstanceImportStep("us-central1-a", instanceName, []string{"wait_for"}),
+		},
+	})
+}
+
+func TestAccComputeInstance_waitForAddedOnUpdate(t *testing.T) {
+	t.Parallel()
+
+	var instance compute.Instance
+	var instanceName = fmt.Sprintf("tf-test-%s", RandString(t, 10))
+
+	VcrTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.AccTestPreCheck(t) },
+		ProtoV5ProviderFactories: ProtoV5ProviderFactories(t),
+		CheckDestroy:             testAccCheckComputeInstanceDestroyProducer(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccComputeInstance_noWaitForSerialPortOutput(instanceName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckComputeInstanceExists(
+						t, "google_compute_instance.foobar", &instance),
+					resource.TestCheckNoResourceAttr("google_compute_instance.foobar", "wait_for.0.ready"),
+				),
+			},
+			{
+				Config: testAccComputeInstance_waitForSerialPortOutput(instanceName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("google_compute_instance.foobar", "wait_for.0.ready", "true"),
+					resource.TestCheckResourceAttr("google_compute_instance.foobar", "wait_for.0.matched_value", "tf-startup-done"),
+					resource.TestCheckResourceAttrSet("google_compute_instance.foobar", "wait_for.0.ready_time"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccComputeInstance_waitForGuestAttribute(t *testing.T) {
+	t.Parallel()
+
+	var instance compute.Instance
+	var instanceName = fmt.Sprintf("tf-test-%s", RandString(t, 10))
+
+	VcrTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.AccTestPreCheck(t) },
+		ProtoV5ProviderFactories: ProtoV5ProviderFactories(t),
+		CheckDestroy:             testAccCheckComputeInstanceDestroyProducer(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccComputeInstance_waitForGuestAttribute(instanceName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckComputeInstanceExists(
+						t, "google_compute_instance.foobar", &instance),
+					resource.TestCheckResourceAttr("google_compute_instance.foobar", "wait_for.0.ready", "true"),
+					resource.TestCheckResourceAttr("google_compute_instance.foobar", "wait_for.0.matched_value", "ready"),
+				),
+			},
+		},
+	})
+}
+
 func testAccCheckComputeInstanceUpdateMachineType(t *testing.T, n string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[n]
@@ -6460,3 +6541,106 @@ resource "google_compute_instance" "foobar" {
 }
 `, instance, machineType, metadata)
 }
+
+func testAccComputeInstance_waitForSerialPortOutput(instance string) string {
+	return fmt.Sprintf(`
+data "google_compute_image" "my_image" {
+  family  = "debian-11"
+  project = "debian-cloud"
+}
+
+resource "google_compute_instance" "foobar" {
+  name         = "%s"
+  machine_type = "e2-medium"
+  zone         = "us-central1-a"
+
+  boot_disk {
+    initialize_params {
+      image = data.google_compute_image.my_image.self_link
+    }
+  }
+
+  network_interface {
+    network = "default"
+  }
+
+  metadata_startup_script = "echo tf-startup-done > /dev/ttyS0"
+
+  wait_for {
+    serial_port_output {
+      regex = "tf-startup-done"
+    }
+    timeout = "15m"
+  }
+}
+`, instance)
+}
+
+func testAccComputeInstance_noWaitForSerialPortOutput(instance string) string {
+	return fmt.Sprintf(`
+data "google_compute_image" "my_image" {
+  family  = "debian-11"
+  project = "debian-cloud"
+}
+
+resource "google_compute_instance" "foobar" {
+  name         = "%s"
+  machine_type = "e2-medium"
+  zone         = "us-central1-a"
+
+  boot_disk {
+    initialize_params {
+      image = data.google_compute_image.my_image.self_link
+    }
+  }
+
+  network_interface {
+    network = "default"
+  }
+
+  metadata_startup_script = "echo tf-startup-done > /dev/ttyS0"
+}
+`, instance)
+}
+
+func testAccComputeInstance_waitForGuestAttribute(instance string) string {
+	return fmt.Sprintf(`
+data "google_compute_image" "my_image" {
+  family  = "debian-11"
+  project = "debian-cloud"
+}
+
+resource "google_compute_instance" "foobar" {
+  name         = "%s"
+  machine_type = "e2-medium"
+  zone         = "us-central1-a"
+
+  boot_disk {
+    initialize_params {
+      image = data.google_compute_image.my_image.self_link
+    }
+  }
+
+  network_interface {
+    network = "default"
+  }
+
+  metadata = {
+    enable-guest-attributes = "TRUE"
+  }
+
+  metadata_startup_script = <<-EOT
+    curl -s -X PUT --data "ready" -H "Metadata-Flavor: Google" \
+      http://metadata.google.internal/computeMetadata/v1/instance/guest-attributes/startup/status
+  EOT
+
+  wait_for {
+    guest_attribute {
+      namespace   = "startup"
+      key         = "status"
+      value_regex = "^ready$"
+    }
+  }
+}
+`, instance)
+}
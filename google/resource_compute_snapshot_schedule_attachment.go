@@ -0,0 +1,572 @@
+package google
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/hashicorp/terraform-provider-google/google/tpgresource"
+	transport_tpg "github.com/hashicorp/terraform-provider-google/google/transport"
+
+	"google.golang.org/api/compute/v1"
+)
+
+// ResourceComputeSnapshotScheduleAttachment attaches a snapshot schedule
+// resource policy to every disk of a set of instances, chosen either by name
+// or by label. The selection is re-evaluated on every refresh, so disks
+// attached to the instances later, or instances that start matching the
+// labels, are picked up by the next apply. Only the disks the resource
+// attached the policy to are tracked in disks, and the policy is only ever
+// detached from those.
+func ResourceComputeSnapshotScheduleAttachment() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceComputeSnapshotScheduleAttachmentCreate,
+		Read:   resourceComputeSnapshotScheduleAttachmentRead,
+		Update: resourceComputeSnapshotScheduleAttachmentUpdate,
+		Delete: resourceComputeSnapshotScheduleAttachmentDelete,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(20 * time.Minute),
+			Update: schema.DefaultTimeout(20 * time.Minute),
+			Delete: schema.DefaultTimeout(20 * time.Minute),
+		},
+
+		CustomizeDiff: resourceComputeSnapshotScheduleAttachmentCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			"resource_policy": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				DiffSuppressFunc: tpgresource.CompareSelfLinkOrResourceName,
+				Description:      `The name or self link of the snapshot schedule resource policy to attach. Only disks in the policy's region can be selected.`,
+			},
+			"instances": {
+				Type:         schema.TypeSet,
+				Optional:     true,
+				Elem:         &schema.Schema{Type: schema.TypeString},
+				Set:          tpgresource.SelfLinkRelativePathHash,
+				ExactlyOneOf: []string{"instances", "instance_labels"},
+				Description:  `The self links of the instances whose disks the schedule is attached to.`,
+			},
+			"instance_labels": {
+				Type:         schema.TypeMap,
+				Optional:     true,
+				Elem:         &schema.Schema{Type: schema.TypeString},
+				ExactlyOneOf: []string{"instances", "instance_labels"},
+				Description:  `Select the instances in the policy's region that have all of these labels.`,
+			},
+			"include_boot_disks": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: `Whether to attach the schedule to boot disks.`,
+			},
+			"include_data_disks": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: `Whether to attach the schedule to non-boot persistent disks.`,
+			},
+			"region": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: `The region of the resource policy. If it is not provided, it is taken from resource_policy, or the provider region.`,
+			},
+			"project": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+			"disks": {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: `The self links of the disks this resource attached the schedule to.`,
+			},
+			"deselected_disks": {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: `The self links of disks in disks that are no longer selected. The schedule is detached from them on the next apply.`,
+			},
+			"unattached_disks": {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: `The self links of selected disks that don't have the schedule attached yet. They are attached on the next apply.`,
+			},
+			"latest_snapshots": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: `The most recent snapshot of each disk in disks.`,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"disk": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"snapshot": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"creation_timestamp": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"status": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+		UseJSONNumber: true,
+	}
+}
+
+// Disks that are selected but not attached yet show up in unattached_disks
+// after a refresh, and managed disks that are no longer selected in
+// deselected_disks. Planning a change to disks makes sure they get attached or
+// detached.
+func resourceComputeSnapshotScheduleAttachmentCustomizeDiff(_ context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+	if diff.Id() == "" {
+		return nil
+	}
+	if diff.Get("unattached_disks").(*schema.Set).Len() > 0 || diff.Get("deselected_disks").(*schema.Set).Len() > 0 || diff.HasChanges("instances", "instance_labels", "include_boot_disks", "include_data_disks") {
+		if err := diff.SetNewComputed("disks"); err != nil {
+			return err
+		}
+		if err := diff.SetNewComputed("unattached_disks"); err != nil {
+			return err
+		}
+		if err := diff.SetNewComputed("deselected_disks"); err != nil {
+			return err
+		}
+		return diff.SetNewComputed("latest_snapshots")
+	}
+	return nil
+}
+
+func parseSnapshotScheduleAttachmentPolicy(d tpgresource.TerraformResourceData, config *transport_tpg.Config) (*tpgresource.RegionalFieldValue, error) {
+	return tpgresource.ParseRegionalFieldValue("resourcePolicies", d.Get("resource_policy").(string), "project", "region", "zone", d, config, false)
+}
+
+func resourceComputeSnapshotScheduleAttachmentCreate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+
+	policy, err := parseSnapshotScheduleAttachmentPolicy(d, config)
+	if err != nil {
+		return err
+	}
+
+	// The ID is kept if the sync fails, so that the disks attached so far
+	// are tracked and get detached again.
+	d.SetId(policy.RelativeLink())
+	if err := resourceComputeSnapshotScheduleAttachmentSync(d, config, d.Timeout(schema.TimeoutCreate)); err != nil {
+		return err
+	}
+
+	return resourceComputeSnapshotScheduleAttachmentRead(d, meta)
+}
+
+func resourceComputeSnapshotScheduleAttachmentRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	policy, err := parseSnapshotScheduleAttachmentPolicy(d, config)
+	if err != nil {
+		return err
+	}
+
+	client := config.NewComputeClient(userAgent)
+	if _, err := client.ResourcePolicies.Get(policy.Project, policy.Region, policy.Name).Do(); err != nil {
+		return transport_tpg.HandleNotFoundError(err, d, fmt.Sprintf("ResourcePolicy %q", policy.Name))
+	}
+
+	selected, err := selectSnapshotScheduleDisks(d, client, policy)
+	if err != nil {
+		return err
+	}
+
+	// Only the disks this resource attached the policy to are checked for
+	// it, along with the selected ones that may need it.
+	managed := tpgresource.ConvertStringSet(d.Get("disks").(*schema.Set))
+	hasPolicy := make(map[string]bool)
+	for _, diskUrl := range append(append([]string{}, managed...), selected...) {
+		if _, ok := hasPolicy[diskUrl]; ok {
+			continue
+		}
+		disk, err := getSnapshotScheduleDisk(client, diskUrl)
+		if err != nil {
+			if transport_tpg.IsGoogleApiErrorWithCode(err, 404) {
+				continue
+			}
+			return err
+		}
+		hasPolicy[diskUrl] = diskHasResourcePolicy(disk.ResourcePolicies, policy)
+	}
+	attached, unattached, deselected := snapshotScheduleDiskStates(managed, selected, hasPolicy)
+
+	latest := make([]map[string]interface{}, 0, len(attached))
+	for _, diskUrl := range attached {
+		snapshot, err := latestSnapshotForDisk(client, policy.Project, diskUrl)
+		if err != nil {
+			return err
+		}
+		if snapshot == nil {
+			continue
+		}
+		latest = append(latest, map[string]interface{}{
+			"disk":               diskUrl,
+			"snapshot":           snapshot.SelfLink,
+			"creation_timestamp": snapshot.CreationTimestamp,
+			"status":             snapshot.Status,
+		})
+	}
+
+	if err := d.Set("disks", attached); err != nil {
+		return fmt.Errorf("Error setting disks: %s", err)
+	}
+	if err := d.Set("unattached_disks", unattached); err != nil {
+		return fmt.Errorf("Error setting unattached_disks: %s", err)
+	}
+	if err := d.Set("deselected_disks", deselected); err != nil {
+		return fmt.Errorf("Error setting deselected_disks: %s", err)
+	}
+	if err := d.Set("latest_snapshots", latest); err != nil {
+		return fmt.Errorf("Error setting latest_snapshots: %s", err)
+	}
+	if err := d.Set("region", policy.Region); err != nil {
+		return fmt.Errorf("Error setting region: %s", err)
+	}
+	if err := d.Set("project", policy.Project); err != nil {
+		return fmt.Errorf("Error setting project: %s", err)
+	}
+
+	return nil
+}
+
+func resourceComputeSnapshotScheduleAttachmentUpdate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+
+	if err := resourceComputeSnapshotScheduleAttachmentSync(d, config, d.Timeout(schema.TimeoutUpdate)); err != nil {
+		return err
+	}
+
+	return resourceComputeSnapshotScheduleAttachmentRead(d, meta)
+}
+
+func resourceComputeSnapshotScheduleAttachmentDelete(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	policy, err := parseSnapshotScheduleAttachmentPolicy(d, config)
+	if err != nil {
+		return err
+	}
+
+	client := config.NewComputeClient(userAgent)
+	for _, diskUrl := range tpgresource.ConvertStringSet(d.Get("disks").(*schema.Set)) {
+		if err := detachSnapshotSchedule(client, config, diskUrl, policy, userAgent, d.Timeout(schema.TimeoutDelete)); err != nil {
+			return err
+		}
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// resourceComputeSnapshotScheduleAttachmentSync attaches the policy to every
+// selected disk that doesn't have it yet, and detaches it from disks that
+// were previously managed by this resource but are no longer selected. The
+// disks it attached the policy to are recorded in disks, even if it fails.
+func resourceComputeSnapshotScheduleAttachmentSync(d *schema.ResourceData, config *transport_tpg.Config, timeout time.Duration) error {
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	policy, err := parseSnapshotScheduleAttachmentPolicy(d, config)
+	if err != nil {
+		return err
+	}
+
+	client := config.NewComputeClient(userAgent)
+	selected, err := selectSnapshotScheduleDisks(d, client, policy)
+	if err != nil {
+		return err
+	}
+
+	// disks is unknown in the plan, so the managed disks are taken from the
+	// prior state.
+	old, _ := d.GetChange("disks")
+	previous := tpgresource.ConvertStringSet(old.(*schema.Set))
+	managed := make(map[string]bool)
+	for _, diskUrl := range previous {
+		managed[diskUrl] = true
+	}
+	err = syncSnapshotScheduleDisks(client, config, policy, userAgent, timeout, previous, selected, managed)
+
+	disks := make([]string, 0, len(managed))
+	for diskUrl := range managed {
+		disks = append(disks, diskUrl)
+	}
+	if setErr := d.Set("disks", disks); setErr != nil && err == nil {
+		err = fmt.Errorf("Error setting disks: %s", setErr)
+	}
+	return err
+}
+
+// syncSnapshotScheduleDisks attaches and detaches the policy, updating
+// managed as it goes.
+func syncSnapshotScheduleDisks(client *compute.Service, config *transport_tpg.Config, policy *tpgresource.RegionalFieldValue, userAgent string, timeout time.Duration, previous, selected []string, managed map[string]bool) error {
+	toAttach, toDetach := diffSnapshotScheduleDisks(previous, selected)
+
+	for _, diskUrl := range toAttach {
+		disk, err := getSnapshotScheduleDisk(client, diskUrl)
+		if err != nil {
+			return fmt.Errorf("Error reading disk %s: %s", diskUrl, err)
+		}
+		if diskHasResourcePolicy(disk.ResourcePolicies, policy) {
+			// The disk already has the policy, either from this resource
+			// or from something else that keeps managing it.
+			continue
+		}
+		if err := attachSnapshotSchedule(client, config, diskUrl, policy, userAgent, timeout); err != nil {
+			return err
+		}
+		managed[diskUrl] = true
+	}
+
+	for _, diskUrl := range toDetach {
+		if err := detachSnapshotSchedule(client, config, diskUrl, policy, userAgent, timeout); err != nil {
+			return err
+		}
+		delete(managed, diskUrl)
+	}
+
+	return nil
+}
+
+// selectSnapshotScheduleDisks returns the self links of the disks attached to
+// the selected instances.
+func selectSnapshotScheduleDisks(d *schema.ResourceData, client *compute.Service, policy *tpgresource.RegionalFieldValue) ([]string, error) {
+	includeBoot := d.Get("include_boot_disks").(bool)
+	includeData := d.Get("include_data_disks").(bool)
+
+	var instances []*compute.Instance
+	if v, ok := d.GetOk("instances"); ok {
+		for _, raw := range v.(*schema.Set).List() {
+			project, zone, name, err := tpgresource.GetLocationalResourcePropertiesFromSelfLinkString(raw.(string))
+			if err != nil {
+				return nil, fmt.Errorf("Error parsing instance %q, it must be a self link: %s", raw.(string), err)
+			}
+			instance, err := client.Instances.Get(project, zone, name).Do()
+			if err != nil {
+				if transport_tpg.IsGoogleApiErrorWithCode(err, 404) {
+					log.Printf("[WARN] Instance %s selected for snapshot schedule %s no longer exists", raw.(string), policy.Name)
+					continue
+				}
+				return nil, fmt.Errorf("Error reading instance %s: %s", raw.(string), err)
+			}
+			instances = append(instances, instance)
+		}
+	} else {
+		var filters []string
+		for k, v := range d.Get("instance_labels").(map[string]interface{}) {
+			filters = append(filters, fmt.Sprintf("(labels.%s = %q)", k, v.(string)))
+		}
+		sort.Strings(filters)
+
+		err := client.Instances.AggregatedList(policy.Project).Filter(strings.Join(filters, " ")).Pages(context.Background(), func(page *compute.InstanceAggregatedList) error {
+			for scope, items := range page.Items {
+				// Scopes are of the form zones/us-central1-a.
+				if !strings.HasPrefix(strings.TrimPrefix(scope, "zones/"), policy.Region+"-") {
+					continue
+				}
+				instances = append(instances, items.Instances...)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("Error listing instances: %s", err)
+		}
+	}
+
+	var disks []string
+	for _, instance := range instances {
+		disks = append(disks, snapshotScheduleDisksFromInstance(instance, includeBoot, includeData)...)
+	}
+	sort.Strings(disks)
+	return disks, nil
+}
+
+// snapshotScheduleDisksFromInstance returns the persistent disks of an
+// instance that should have the snapshot schedule attached.
+func snapshotScheduleDisksFromInstance(instance *compute.Instance, includeBoot, includeData bool) []string {
+	var disks []string
+	for _, disk := range instance.Disks {
+		if disk.Type == "SCRATCH" || disk.Source == "" {
+			continue
+		}
+		if disk.Boot && !includeBoot || !disk.Boot && !includeData {
+			continue
+		}
+		disks = append(disks, tpgresource.ConvertSelfLinkToV1(disk.Source))
+	}
+	return disks
+}
+
+// diffSnapshotScheduleDisks returns the selected disks the policy may need to
+// be attached to, and the previously managed disks it has to be detached from.
+func diffSnapshotScheduleDisks(previous, selected []string) ([]string, []string) {
+	selectedSet := make(map[string]bool)
+	for _, disk := range selected {
+		selectedSet[disk] = true
+	}
+
+	var toDetach []string
+	for _, disk := range previous {
+		if !selectedSet[disk] {
+			toDetach = append(toDetach, disk)
+		}
+	}
+	return selected, toDetach
+}
+
+// snapshotScheduleDiskStates sorts the disks into the managed disks that still
+// have the policy, the selected disks without it, and the managed disks that
+// are no longer selected. hasPolicy holds whether each existing disk has the
+// policy; deleted disks are missing from it.
+func snapshotScheduleDiskStates(managed, selected []string, hasPolicy map[string]bool) ([]string, []string, []string) {
+	selectedSet := make(map[string]bool)
+	for _, disk := range selected {
+		selectedSet[disk] = true
+	}
+
+	var attached, unattached, deselected []string
+	for _, disk := range managed {
+		// A managed disk that lost the policy is attached again if it's
+		// still selected.
+		if !hasPolicy[disk] {
+			continue
+		}
+		attached = append(attached, disk)
+		if !selectedSet[disk] {
+			deselected = append(deselected, disk)
+		}
+	}
+	for _, disk := range selected {
+		if has, ok := hasPolicy[disk]; ok && !has {
+			unattached = append(unattached, disk)
+		}
+	}
+	return attached, unattached, deselected
+}
+
+func diskHasResourcePolicy(policies []string, policy *tpgresource.RegionalFieldValue) bool {
+	for _, p := range policies {
+		if strings.HasSuffix(p, policy.RelativeLink()) {
+			return true
+		}
+	}
+	return false
+}
+
+func getSnapshotScheduleDisk(client *compute.Service, diskUrl string) (*compute.Disk, error) {
+	project, location, name, err := tpgresource.GetLocationalResourcePropertiesFromSelfLinkString(diskUrl)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(diskUrl, "/regions/") {
+		return client.RegionDisks.Get(project, location, name).Do()
+	}
+	return client.Disks.Get(project, location, name).Do()
+}
+
+func attachSnapshotSchedule(client *compute.Service, config *transport_tpg.Config, diskUrl string, policy *tpgresource.RegionalFieldValue, userAgent string, timeout time.Duration) error {
+	project, location, name, err := tpgresource.GetLocationalResourcePropertiesFromSelfLinkString(diskUrl)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] Attaching resource policy %s to disk %s", policy.Name, diskUrl)
+	var op *compute.Operation
+	if strings.Contains(diskUrl, "/regions/") {
+		op, err = client.RegionDisks.AddResourcePolicies(project, location, name, &compute.RegionDisksAddResourcePoliciesRequest{
+			ResourcePolicies: []string{policy.RelativeLink()},
+		}).Do()
+	} else {
+		op, err = client.Disks.AddResourcePolicies(project, location, name, &compute.DisksAddResourcePoliciesRequest{
+			ResourcePolicies: []string{policy.RelativeLink()},
+		}).Do()
+	}
+	if err != nil {
+		return fmt.Errorf("Error attaching resource policy %s to disk %s: %s", policy.Name, diskUrl, err)
+	}
+
+	return ComputeOperationWaitTime(config, op, project, "Attaching snapshot schedule", userAgent, timeout)
+}
+
+func detachSnapshotSchedule(client *compute.Service, config *transport_tpg.Config, diskUrl string, policy *tpgresource.RegionalFieldValue, userAgent string, timeout time.Duration) error {
+	project, location, name, err := tpgresource.GetLocationalResourcePropertiesFromSelfLinkString(diskUrl)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] Detaching resource policy %s from disk %s", policy.Name, diskUrl)
+	var op *compute.Operation
+	if strings.Contains(diskUrl, "/regions/") {
+		op, err = client.RegionDisks.RemoveResourcePolicies(project, location, name, &compute.RegionDisksRemoveResourcePoliciesRequest{
+			ResourcePolicies: []string{policy.RelativeLink()},
+		}).Do()
+	} else {
+		op, err = client.Disks.RemoveResourcePolicies(project, location, name, &compute.DisksRemoveResourcePoliciesRequest{
+			ResourcePolicies: []string{policy.RelativeLink()},
+		}).Do()
+	}
+	if err != nil {
+		// The disk may have been deleted along with its instance.
+		if transport_tpg.IsGoogleApiErrorWithCode(err, 404) {
+			return nil
+		}
+		return fmt.Errorf("Error detaching resource policy %s from disk %s: %s", policy.Name, diskUrl, err)
+	}
+
+	return ComputeOperationWaitTime(config, op, project, "Detaching snapshot schedule", userAgent, timeout)
+}
+
+// latestSnapshotForDisk returns the most recently created snapshot of a disk,
+// or nil if it has none.
+func latestSnapshotForDisk(client *compute.Service, project, diskUrl string) (*compute.Snapshot, error) {
+	var latest *compute.Snapshot
+	err := client.Snapshots.List(project).Filter(fmt.Sprintf("sourceDisk = %q", diskUrl)).Pages(context.Background(), func(page *compute.SnapshotList) error {
+		for _, snapshot := range page.Items {
+			// RFC3339 timestamps in the same zone sort lexically.
+			if latest == nil || snapshot.CreationTimestamp > latest.CreationTimestamp {
+				latest = snapshot
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error listing snapshots of disk %s: %s", diskUrl, err)
+	}
+	return latest, nil
+}
@@ -0,0 +1,200 @@
+package google
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-provider-google/google/acctest"
+
+	"google.golang.org/api/compute/v1"
+)
+
+func TestSnapshotScheduleDisksFromInstance(t *testing.T) {
+	t.Parallel()
+
+	instance := &compute.Instance{
+		Disks: []*compute.AttachedDisk{
+			{
+				Boot:   true,
+				Type:   "PERSISTENT",
+				Source: "https://www.googleapis.com/compute/beta/projects/p/zones/us-central1-a/disks/boot",
+			},
+			{
+				Type:   "PERSISTENT",
+				Source: "https://www.googleapis.com/compute/v1/projects/p/zones/us-central1-a/disks/data",
+			},
+			{
+				Type: "SCRATCH",
+			},
+		},
+	}
+
+	cases := map[string]struct {
+		IncludeBoot bool
+		IncludeData bool
+		Expected    []string
+	}{
+		"all": {
+			IncludeBoot: true,
+			IncludeData: true,
+			Expected: []string{
+				"https://www.googleapis.com/compute/v1/projects/p/zones/us-central1-a/disks/boot",
+				"https://www.googleapis.com/compute/v1/projects/p/zones/us-central1-a/disks/data",
+			},
+		},
+		"boot only": {
+			IncludeBoot: true,
+			Expected: []string{
+				"https://www.googleapis.com/compute/v1/projects/p/zones/us-central1-a/disks/boot",
+			},
+		},
+		"data only": {
+			IncludeData: true,
+			Expected: []string{
+				"https://www.googleapis.com/compute/v1/projects/p/zones/us-central1-a/disks/data",
+			},
+		},
+		"none": {},
+	}
+
+	for tn, tc := range cases {
+		got := snapshotScheduleDisksFromInstance(instance, tc.IncludeBoot, tc.IncludeData)
+		if !reflect.DeepEqual(got, tc.Expected) {
+			t.Errorf("%s: expected %v, got %v", tn, tc.Expected, got)
+		}
+	}
+}
+
+func TestDiffSnapshotScheduleDisks(t *testing.T) {
+	t.Parallel()
+
+	toAttach, toDetach := diffSnapshotScheduleDisks([]string{"a", "b"}, []string{"b", "c"})
+	if !reflect.DeepEqual(toAttach, []string{"b", "c"}) {
+		t.Errorf("expected to attach [b c], got %v", toAttach)
+	}
+	if !reflect.DeepEqual(toDetach, []string{"a"}) {
+		t.Errorf("expected to detach [a], got %v", toDetach)
+	}
+}
+
+func TestSnapshotScheduleDiskStates(t *testing.T) {
+	t.Parallel()
+
+	managed := []string{"kept", "deselected", "lost", "deleted"}
+	selected := []string{"kept", "lost", "other", "new"}
+	hasPolicy := map[string]bool{
+		"kept":       true,
+		"deselected": true,
+		"lost":       false,
+		"other":      true,
+		"new":        false,
+	}
+
+	attached, unattached, deselected := snapshotScheduleDiskStates(managed, selected, hasPolicy)
+	// Disks with the policy attached by something else are left alone.
+	if expected := []string{"kept", "deselected"}; !reflect.DeepEqual(attached, expected) {
+		t.Errorf("expected attached %v, got %v", expected, attached)
+	}
+	if expected := []string{"lost", "new"}; !reflect.DeepEqual(unattached, expected) {
+		t.Errorf("expected unattached %v, got %v", expected, unattached)
+	}
+	if expected := []string{"deselected"}; !reflect.DeepEqual(deselected, expected) {
+		t.Errorf("expected deselected %v, got %v", expected, deselected)
+	}
+}
+
+func TestAccComputeSnapshotScheduleAttachment_labels(t *testing.T) {
+	t.Parallel()
+
+	suffix := RandString(t, 10)
+
+	VcrTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.AccTestPreCheck(t) },
+		ProtoV5ProviderFactories: ProtoV5ProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccComputeSnapshotScheduleAttachment_labels(suffix, false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("google_compute_snapshot_schedule_attachment.foobar", "disks.#", "1"),
+					resource.TestCheckResourceAttr("google_compute_snapshot_schedule_attachment.foobar", "unattached_disks.#", "0"),
+				),
+			},
+			{
+				// The new data disk is only seen by the refresh after this apply.
+				Config:             testAccComputeSnapshotScheduleAttachment_labels(suffix, true),
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				// The next apply brings it under the schedule.
+				Config: testAccComputeSnapshotScheduleAttachment_labels(suffix, true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("google_compute_snapshot_schedule_attachment.foobar", "disks.#", "2"),
+					resource.TestCheckResourceAttr("google_compute_snapshot_schedule_attachment.foobar", "unattached_disks.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func testAccComputeSnapshotScheduleAttachment_labels(suffix string, withDataDisk bool) string {
+	attachedDisk := ""
+	if withDataDisk {
+		attachedDisk = `
+  attached_disk {
+    source = google_compute_disk.data.self_link
+  }
+`
+	}
+
+	return fmt.Sprintf(`
+resource "google_compute_resource_policy" "foobar" {
+  name   = "tf-test-policy-%s"
+  region = "us-central1"
+  snapshot_schedule_policy {
+    schedule {
+      daily_schedule {
+        days_in_cycle = 1
+        start_time    = "04:00"
+      }
+    }
+  }
+}
+
+resource "google_compute_disk" "data" {
+  name = "tf-test-data-%s"
+  size = 10
+  zone = "us-central1-a"
+}
+
+resource "google_compute_instance" "foobar" {
+  name         = "tf-test-%s"
+  machine_type = "e2-medium"
+  zone         = "us-central1-a"
+
+  labels = {
+    backup = "tf-test-%s"
+  }
+
+  boot_disk {
+    initialize_params {
+      image = "debian-cloud/debian-11"
+    }
+  }
+%s
+  network_interface {
+    network = "default"
+  }
+}
+
+resource "google_compute_snapshot_schedule_attachment" "foobar" {
+  resource_policy = google_compute_resource_policy.foobar.self_link
+  instance_labels = {
+    backup = "tf-test-%s"
+  }
+
+  depends_on = [google_compute_instance.foobar]
+}
+`, suffix, suffix, suffix, suffix, attachedDisk, suffix)
+}
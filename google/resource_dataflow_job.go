@@ -2,6 +2,7 @@ package google
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"strings"
@@ -34,6 +35,13 @@ var dataflowTerminalStatesMap = map[string]struct{}{
 	"JOB_STATE_DRAINED":   {},
 }
 
+// Fields that only change the provider's behaviour, and never need to be
+// sent to the API.
+var dataflowJobVirtualFields = map[string]struct{}{
+	"on_delete":       {},
+	"update_strategy": {},
+}
+
 func resourceDataflowJobLabelDiffSuppress(k, old, new string, d *schema.ResourceData) bool {
 	// Example Diff: "labels.goog-dataflow-provided-template-version": "word_count" => ""
 	if strings.HasPrefix(k, resourceDataflowJobGoogleProvidedLabelPrefix) && new == "" {
@@ -138,6 +146,14 @@ func ResourceDataflowJob() *schema.Resource {
 				Description:  `One of "drain" or "cancel". Specifies behavior of deletion during terraform destroy.`,
 			},
 
+			"update_strategy": {
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringInSlice([]string{"update", "drain_and_replace", "cancel_and_replace"}, false),
+				Optional:     true,
+				Default:      "update",
+				Description:  `One of "update", "drain_and_replace" or "cancel_and_replace". Specifies behavior when a streaming job is updated and the new job graph is not compatible with the running job. With "update" the update fails; otherwise the running job is drained or cancelled, and a new job is started once it has terminated.`,
+			},
+
 			"project": {
 				Type:     schema.TypeString,
 				Optional: true,
@@ -234,7 +250,7 @@ func resourceDataflowJobTypeCustomizeDiff(_ context.Context, d *schema.ResourceD
 	if d.Get("type") == "JOB_TYPE_BATCH" {
 		resourceSchema := ResourceDataflowJob().Schema
 		for field := range resourceSchema {
-			if field == "on_delete" {
+			if _, ok := dataflowJobVirtualFields[field]; ok {
 				continue
 			}
 			// Labels map will likely have suppressed changes, so we check each key instead of the parent field
@@ -418,14 +434,23 @@ func resourceDataflowJobUpdateByReplacement(d *schema.ResourceData, meta interfa
 		Environment:          &env,
 		Update:               true,
 	}
+	gcsPath := d.Get("template_gcs_path").(string)
 
+	// Dataflow checks the compatibility of the job graph when launching the
+	// update, and keeps the running job if it fails, so that an incompatible
+	// update can fall back to a replacement.
 	var response *dataflow.LaunchTemplateResponse
 	err = transport_tpg.RetryTimeDuration(func() (updateErr error) {
-		response, updateErr = resourceDataflowJobLaunchTemplate(config, project, region, userAgent, d.Get("template_gcs_path").(string), &request)
+		response, updateErr = resourceDataflowJobLaunchTemplate(config, project, region, userAgent, gcsPath, &request)
 		return updateErr
 	}, time.Minute*time.Duration(5), transport_tpg.IsDataflowJobUpdateRetryableError)
 	if err != nil {
-		return err
+		strategy := d.Get("update_strategy").(string)
+		if strategy == "update" || !isDataflowJobGraphIncompatibleError(err) {
+			return fmt.Errorf("Error updating job with job ID %q: %v", d.Id(), err)
+		}
+		log.Printf("[DEBUG] Update of Dataflow job %q is not compatible, replacing it per update_strategy %q: %v", d.Id(), strategy, err)
+		return resourceDataflowJobReplace(d, meta, strategy)
 	}
 
 	if err := waitForDataflowJobToBeUpdated(d, config, response.Job.Id, userAgent, d.Timeout(schema.TimeoutUpdate)); err != nil {
@@ -461,8 +486,37 @@ func resourceDataflowJobDelete(d *schema.ResourceData, meta interface{}) error {
 		return err
 	}
 
-	// Retry updating the state while the job is not ready to be canceled/drained.
-	err = resource.Retry(time.Minute*time.Duration(15), func() *resource.RetryError {
+	if err := resourceDataflowJobRequestState(config, project, region, userAgent, id, requestedState); err != nil {
+		return err
+	}
+
+	// Wait for state to reach terminal state (canceled/drained/done plus cancelling/draining if skipWait)
+	skipWait := d.Get("skip_wait_on_job_termination").(bool)
+	ok := shouldStopDataflowJobDeleteQuery(d.Get("state").(string), skipWait)
+	for !ok {
+		log.Printf("[DEBUG] Waiting for job with job state %q to terminate...", d.Get("state").(string))
+		time.Sleep(5 * time.Second)
+
+		err = resourceDataflowJobRead(d, meta)
+		if err != nil {
+			return fmt.Errorf("Error while reading job to see if it was properly terminated: %v", err)
+		}
+		ok = shouldStopDataflowJobDeleteQuery(d.Get("state").(string), skipWait)
+	}
+
+	// Only remove the job from state if it's actually successfully hit a final state.
+	if ok = shouldStopDataflowJobDeleteQuery(d.Get("state").(string), skipWait); ok {
+		log.Printf("[DEBUG] Removing dataflow job with final state %q", d.Get("state").(string))
+		d.SetId("")
+		return nil
+	}
+	return fmt.Errorf("Unable to cancel the dataflow job '%s' - final state was %q.", d.Id(), d.Get("state").(string))
+}
+
+// resourceDataflowJobRequestState asks for a job to be cancelled or drained,
+// retrying while the job is not yet ready to be terminated.
+func resourceDataflowJobRequestState(config *transport_tpg.Config, project, region, userAgent, id, requestedState string) error {
+	return resource.Retry(time.Minute*time.Duration(15), func() *resource.RetryError {
 		// To terminate a dataflow job, we update the job with a requested
 		// terminal state.
 		job := &dataflow.Job{
@@ -492,31 +546,88 @@ func resourceDataflowJobDelete(d *schema.ResourceData, meta interface{}) error {
 
 		return nil
 	})
+}
+
+// resourceDataflowJobReplace terminates the running job according to the
+// update strategy, waits for it to reach a terminal state so that its name
+// is free again, and then starts a new job from the current configuration.
+func resourceDataflowJobReplace(d *schema.ResourceData, meta interface{}, strategy string) error {
+	config := meta.(*transport_tpg.Config)
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
 	if err != nil {
 		return err
 	}
 
-	// Wait for state to reach terminal state (canceled/drained/done plus cancelling/draining if skipWait)
-	skipWait := d.Get("skip_wait_on_job_termination").(bool)
-	ok := shouldStopDataflowJobDeleteQuery(d.Get("state").(string), skipWait)
-	for !ok {
-		log.Printf("[DEBUG] Waiting for job with job state %q to terminate...", d.Get("state").(string))
-		time.Sleep(5 * time.Second)
+	project, err := tpgresource.GetProject(d, config)
+	if err != nil {
+		return err
+	}
 
-		err = resourceDataflowJobRead(d, meta)
-		if err != nil {
-			return fmt.Errorf("Error while reading job to see if it was properly terminated: %v", err)
-		}
-		ok = shouldStopDataflowJobDeleteQuery(d.Get("state").(string), skipWait)
+	region, err := tpgresource.GetRegion(d, config)
+	if err != nil {
+		return err
 	}
 
-	// Only remove the job from state if it's actually successfully hit a final state.
-	if ok = shouldStopDataflowJobDeleteQuery(d.Get("state").(string), skipWait); ok {
-		log.Printf("[DEBUG] Removing dataflow job with final state %q", d.Get("state").(string))
-		d.SetId("")
-		return nil
+	requestedState := "JOB_STATE_DRAINING"
+	if strategy == "cancel_and_replace" {
+		requestedState = "JOB_STATE_CANCELLED"
 	}
-	return fmt.Errorf("Unable to cancel the dataflow job '%s' - final state was %q.", d.Id(), d.Get("state").(string))
+
+	oldJobID := d.Id()
+	if err := resourceDataflowJobRequestState(config, project, region, userAgent, oldJobID, requestedState); err != nil {
+		return fmt.Errorf("Error terminating job with job ID %q before replacing it: %v", oldJobID, err)
+	}
+	if err := waitForDataflowJobToTerminate(config, project, region, userAgent, oldJobID, d.Timeout(schema.TimeoutUpdate)); err != nil {
+		return fmt.Errorf("Error waiting for job with job ID %q to terminate before replacing it: %v", oldJobID, err)
+	}
+
+	env, err := resourceDataflowJobSetupEnv(d, config)
+	if err != nil {
+		return err
+	}
+
+	request := dataflow.CreateJobFromTemplateRequest{
+		JobName:     d.Get("name").(string),
+		GcsPath:     d.Get("template_gcs_path").(string),
+		Parameters:  tpgresource.ExpandStringMap(d, "parameters"),
+		Environment: &env,
+	}
+
+	job, err := resourceDataflowJobCreateJob(config, project, region, userAgent, &request)
+	if err != nil {
+		return fmt.Errorf("Error creating replacement for job with job ID %q: %v", oldJobID, err)
+	}
+	log.Printf("[DEBUG] Replaced Dataflow job %q with %q", oldJobID, job.Id)
+	d.SetId(job.Id)
+
+	return resourceDataflowJobRead(d, meta)
+}
+
+// isDataflowJobGraphIncompatibleError returns whether an update launch failed
+// the compatibility check against the running job, which Dataflow reports as
+// a FAILED_PRECONDITION error, e.g. "The new job is not compatible with
+// 2023-05-01_01_02_03-123. The original job has not been aborted.". Other
+// errors, such as invalid parameters (INVALID_ARGUMENT), would fail the
+// replacement job too, so they must not terminate the running job.
+func isDataflowJobGraphIncompatibleError(err error) bool {
+	gerr, ok := err.(*googleapi.Error)
+	if !ok || gerr.Code != 400 {
+		return false
+	}
+	for _, e := range gerr.Errors {
+		if e.Reason == "failedPrecondition" {
+			return true
+		}
+	}
+	var body struct {
+		Error struct {
+			Status string `json:"status"`
+		} `json:"error"`
+	}
+	if jsonErr := json.Unmarshal([]byte(gerr.Body), &body); jsonErr != nil {
+		return false
+	}
+	return body.Error.Status == "FAILED_PRECONDITION"
 }
 
 func resourceDataflowJobMapRequestedState(policy string) (string, error) {
@@ -609,10 +720,9 @@ func resourceDataflowJobIterateMapHasChange(mapKey string, d *schema.ResourceDat
 }
 
 func resourceDataflowJobIsVirtualUpdate(d *schema.ResourceData, resourceSchema map[string]*schema.Schema) bool {
-	// on_delete is the only virtual field
-	if d.HasChange("on_delete") {
+	if d.HasChange("on_delete") || d.HasChange("update_strategy") {
 		for field := range resourceSchema {
-			if field == "on_delete" {
+			if _, ok := dataflowJobVirtualFields[field]; ok {
 				continue
 			}
 			// Labels map will likely have suppressed changes, so we check each key instead of the parent field
@@ -621,7 +731,7 @@ func resourceDataflowJobIsVirtualUpdate(d *schema.ResourceData, resourceSchema m
 				return false
 			}
 		}
-		// on_delete is changing, but nothing else
+		// virtual fields are changing, but nothing else
 		return true
 	}
 
@@ -660,3 +770,23 @@ func waitForDataflowJobToBeUpdated(d *schema.ResourceData, config *transport_tpg
 		}
 	})
 }
+
+// waitForDataflowJobToTerminate waits for a job that was asked to drain or
+// cancel to reach a terminal state.
+func waitForDataflowJobToTerminate(config *transport_tpg.Config, project, region, userAgent, id string, timeout time.Duration) error {
+	return resource.Retry(timeout, func() *resource.RetryError {
+		job, err := resourceDataflowJobGetJob(config, project, region, userAgent, id)
+		if err != nil {
+			if transport_tpg.IsRetryableError(err) {
+				return resource.RetryableError(err)
+			}
+			return resource.NonRetryableError(err)
+		}
+
+		if ok := shouldStopDataflowJobDeleteQuery(job.CurrentState, false); !ok {
+			log.Printf("[DEBUG] Waiting for job with job ID %q and state %q to terminate...", id, job.CurrentState)
+			return resource.RetryableError(fmt.Errorf("the job with ID %q has state %q.", id, job.CurrentState))
+		}
+		return nil
+	})
+}
@@ -2,6 +2,8 @@ package google
 
 import (
 	"fmt"
+	"io"
+	"net/http"
 	"strconv"
 	"strings"
 	"testing"
@@ -14,6 +16,7 @@ import (
 	"github.com/hashicorp/terraform-provider-google/google/tpgresource"
 
 	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/googleapi"
 )
 
 const (
@@ -22,6 +25,89 @@ const (
 	testDataflowJobTemplateTextToPubsub = "gs://dataflow-templates/latest/Stream_GCS_Text_to_Cloud_PubSub"
 )
 
+// dataflowJobLaunchError builds the error of a recorded template launch
+// response, the way the client returns it.
+func dataflowJobLaunchError(code int, body string) error {
+	return googleapi.CheckResponse(&http.Response{
+		StatusCode: code,
+		Body:       io.NopCloser(strings.NewReader(body)),
+	})
+}
+
+func TestIsDataflowJobGraphIncompatibleError(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		err          error
+		incompatible bool
+	}{
+		"incompatible graph": {
+			err: dataflowJobLaunchError(400, `{
+  "error": {
+    "code": 400,
+    "message": "The new job is not compatible with 2023-05-01_01_02_03-123. The original job has not been aborted.",
+    "errors": [
+      {
+        "message": "The new job is not compatible with 2023-05-01_01_02_03-123. The original job has not been aborted.",
+        "domain": "global",
+        "reason": "failedPrecondition"
+      }
+    ],
+    "status": "FAILED_PRECONDITION"
+  }
+}`),
+			incompatible: true,
+		},
+		"incompatible graph without errors": {
+			err: dataflowJobLaunchError(400, `{
+  "error": {
+    "code": 400,
+    "message": "Could not update the job: the new job graph is incompatible.",
+    "status": "FAILED_PRECONDITION"
+  }
+}`),
+			incompatible: true,
+		},
+		"invalid parameter": {
+			err: dataflowJobLaunchError(400, `{
+  "error": {
+    "code": 400,
+    "message": "The template parameters are invalid. Details: inputFile: Unrecognized parameter",
+    "errors": [
+      {
+        "message": "The template parameters are invalid. Details: inputFile: Unrecognized parameter",
+        "domain": "global",
+        "reason": "badRequest"
+      }
+    ],
+    "status": "INVALID_ARGUMENT"
+  }
+}`),
+		},
+		"job not running": {
+			err: dataflowJobLaunchError(404, `{
+  "error": {
+    "code": 404,
+    "message": "(8e1fa1c7e5b4c3a2): Could not find job 2023-05-01_01_02_03-123 in RUNNING OR DRAINING state.",
+    "status": "NOT_FOUND"
+  }
+}`),
+		},
+		"not json": {
+			err: dataflowJobLaunchError(400, "Bad Request"),
+		},
+		"other error": {
+			err: fmt.Errorf("The new job is not compatible with 2023-05-01_01_02_03-123."),
+		},
+	}
+
+	for tn, tc := range cases {
+		if got := isDataflowJobGraphIncompatibleError(tc.err); got != tc.incompatible {
+			t.Errorf("%s: expected %v, got %v", tn, tc.incompatible, got)
+		}
+	}
+}
+
 func TestAccDataflowJob_basic(t *testing.T) {
 	// Dataflow responses include serialized java classes and bash commands
 	// This makes body comparison infeasible
@@ -48,7 +134,7 @@ func TestAccDataflowJob_basic(t *testing.T) {
 				ResourceName:            "google_dataflow_job.big_data",
 				ImportState:             true,
 				ImportStateVerify:       true,
-				ImportStateVerifyIgnore: []string{"on_delete", "parameters", "skip_wait_on_job_termination", "zone", "state"},
+				ImportStateVerifyIgnore: []string{"on_delete", "update_strategy", "parameters", "skip_wait_on_job_termination", "zone", "state"},
 			},
 		},
 	})
@@ -80,7 +166,7 @@ func TestAccDataflowJobSkipWait_basic(t *testing.T) {
 				ResourceName:            "google_dataflow_job.big_data",
 				ImportState:             true,
 				ImportStateVerify:       true,
-				ImportStateVerifyIgnore: []string{"on_delete", "parameters", "skip_wait_on_job_termination", "zone", "state"},
+				ImportStateVerifyIgnore: []string{"on_delete", "update_strategy", "parameters", "skip_wait_on_job_termination", "zone", "state"},
 			},
 		},
 	})
@@ -111,7 +197,7 @@ func TestAccDataflowJob_withRegion(t *testing.T) {
 				ResourceName:            "google_dataflow_job.big_data",
 				ImportState:             true,
 				ImportStateVerify:       true,
-				ImportStateVerifyIgnore: []string{"on_delete", "parameters", "skip_wait_on_job_termination", "region", "state"},
+				ImportStateVerifyIgnore: []string{"on_delete", "update_strategy", "parameters", "skip_wait_on_job_termination", "region", "state"},
 			},
 		},
 	})
@@ -144,7 +230,7 @@ func TestAccDataflowJob_withServiceAccount(t *testing.T) {
 				ResourceName:            "google_dataflow_job.big_data",
 				ImportState:             true,
 				ImportStateVerify:       true,
-				ImportStateVerifyIgnore: []string{"on_delete", "parameters", "skip_wait_on_job_termination", "state"},
+				ImportStateVerifyIgnore: []string{"on_delete", "update_strategy", "parameters", "skip_wait_on_job_termination", "state"},
 			},
 		},
 	})
@@ -177,7 +263,7 @@ func TestAccDataflowJob_withNetwork(t *testing.T) {
 				ResourceName:            "google_dataflow_job.big_data",
 				ImportState:             true,
 				ImportStateVerify:       true,
-				ImportStateVerifyIgnore: []string{"on_delete", "parameters", "skip_wait_on_job_termination", "state"},
+				ImportStateVerifyIgnore: []string{"on_delete", "update_strategy", "parameters", "skip_wait_on_job_termination", "state"},
 			},
 		},
 	})
@@ -211,7 +297,7 @@ func TestAccDataflowJob_withSubnetwork(t *testing.T) {
 				ResourceName:            "google_dataflow_job.big_data",
 				ImportState:             true,
 				ImportStateVerify:       true,
-				ImportStateVerifyIgnore: []string{"on_delete", "parameters", "skip_wait_on_job_termination", "subnetwork", "state"},
+				ImportStateVerifyIgnore: []string{"on_delete", "update_strategy", "parameters", "skip_wait_on_job_termination", "subnetwork", "state"},
 			},
 		},
 	})
@@ -245,7 +331,7 @@ func TestAccDataflowJob_withLabels(t *testing.T) {
 				ResourceName:            "google_dataflow_job.with_labels",
 				ImportState:             true,
 				ImportStateVerify:       true,
-				ImportStateVerifyIgnore: []string{"on_delete", "parameters", "skip_wait_on_job_termination", "state"},
+				ImportStateVerifyIgnore: []string{"on_delete", "update_strategy", "parameters", "skip_wait_on_job_termination", "state"},
 			},
 		},
 	})
@@ -276,7 +362,7 @@ func TestAccDataflowJob_withIpConfig(t *testing.T) {
 				ResourceName:            "google_dataflow_job.big_data",
 				ImportState:             true,
 				ImportStateVerify:       true,
-				ImportStateVerifyIgnore: []string{"on_delete", "parameters", "skip_wait_on_job_termination", "ip_configuration", "state"},
+				ImportStateVerifyIgnore: []string{"on_delete", "update_strategy", "parameters", "skip_wait_on_job_termination", "ip_configuration", "state"},
 			},
 		},
 	})
@@ -314,7 +400,7 @@ func TestAccDataflowJob_withKmsKey(t *testing.T) {
 				ResourceName:            "google_dataflow_job.big_data",
 				ImportState:             true,
 				ImportStateVerify:       true,
-				ImportStateVerifyIgnore: []string{"on_delete", "parameters", "skip_wait_on_job_termination", "zone", "state"},
+				ImportStateVerifyIgnore: []string{"on_delete", "update_strategy", "parameters", "skip_wait_on_job_termination", "zone", "state"},
 			},
 		},
 	})
@@ -346,7 +432,7 @@ func TestAccDataflowJobWithAdditionalExperiments(t *testing.T) {
 				ResourceName:            "google_dataflow_job.with_additional_experiments",
 				ImportState:             true,
 				ImportStateVerify:       true,
-				ImportStateVerifyIgnore: []string{"on_delete", "parameters", "skip_wait_on_job_termination", "state", "additional_experiments"},
+				ImportStateVerifyIgnore: []string{"on_delete", "update_strategy", "parameters", "skip_wait_on_job_termination", "state", "additional_experiments"},
 			},
 		},
 	})
@@ -380,7 +466,7 @@ func TestAccDataflowJob_streamUpdate(t *testing.T) {
 				ResourceName:            "google_dataflow_job.pubsub_stream",
 				ImportState:             true,
 				ImportStateVerify:       true,
-				ImportStateVerifyIgnore: []string{"on_delete", "parameters", "skip_wait_on_job_termination", "transform_name_mapping", "state"},
+				ImportStateVerifyIgnore: []string{"on_delete", "update_strategy", "parameters", "skip_wait_on_job_termination", "transform_name_mapping", "state"},
 			},
 		},
 	})
@@ -419,7 +505,50 @@ func TestAccDataflowJob_virtualUpdate(t *testing.T) {
 				ResourceName:            "google_dataflow_job.pubsub_stream",
 				ImportState:             true,
 				ImportStateVerify:       true,
-				ImportStateVerifyIgnore: []string{"on_delete", "parameters", "skip_wait_on_job_termination", "state"},
+				ImportStateVerifyIgnore: []string{"on_delete", "update_strategy", "parameters", "skip_wait_on_job_termination", "state"},
+			},
+		},
+	})
+}
+
+func TestAccDataflowJob_streamUpdateReplace(t *testing.T) {
+	// Dataflow responses include serialized java classes and bash commands
+	// This makes body comparison infeasible
+	acctest.SkipIfVcr(t)
+	t.Parallel()
+
+	suffix := RandString(t, 10)
+
+	// The second step maps a transform that doesn't exist in the running job,
+	// which fails the compatibility check, so the job should be replaced.
+	var id string
+	VcrTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.AccTestPreCheck(t) },
+		ProtoV5ProviderFactories: ProtoV5ProviderFactories(t),
+		CheckDestroy:             testAccCheckDataflowJobDestroyProducer(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataflowJob_updateStrategy(suffix, "cancel_and_replace", ""),
+				Check: resource.ComposeTestCheckFunc(
+					testAccDataflowJobExists(t, "google_dataflow_job.pubsub_stream"),
+					testAccDataflowSetId(t, "google_dataflow_job.pubsub_stream", &id),
+				),
+			},
+			{
+				Config: testAccDataflowJob_updateStrategy(suffix, "cancel_and_replace", `
+	transform_name_mapping = {
+		does_not_exist = "other"
+	}`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccDataflowJobExists(t, "google_dataflow_job.pubsub_stream"),
+					testAccDataflowCheckIdChanged(t, "google_dataflow_job.pubsub_stream", &id),
+				),
+			},
+			{
+				ResourceName:            "google_dataflow_job.pubsub_stream",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"on_delete", "update_strategy", "parameters", "skip_wait_on_job_termination", "transform_name_mapping", "state"},
 			},
 		},
 	})
@@ -532,6 +661,20 @@ func testAccDataflowCheckId(t *testing.T, resource string, id *string) resource.
 	}
 }
 
+func testAccDataflowCheckIdChanged(t *testing.T, resource string, id *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resource]
+		if !ok {
+			return fmt.Errorf("resource %q not in state", resource)
+		}
+
+		if rs.Primary.ID == *id {
+			return fmt.Errorf("ID did not change, job %s was not replaced", *id)
+		}
+		return nil
+	}
+}
+
 func testAccDataflowJobHasNetwork(t *testing.T, res, expected string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		instanceTmpl, err := testAccDataflowJobGetGeneratedInstanceTemplate(t, s, res)
@@ -1093,3 +1236,27 @@ resource "google_dataflow_job" "pubsub_stream" {
 }
   `, suffix, suffix, suffix, testDataflowJobTemplateTextToPubsub, onDelete)
 }
+
+func testAccDataflowJob_updateStrategy(suffix, updateStrategy, transformNameMapping string) string {
+	return fmt.Sprintf(`
+resource "google_pubsub_topic" "topic" {
+	name     = "tf-test-dataflow-job-%s"
+}
+resource "google_storage_bucket" "bucket" {
+	name          = "tf-test-bucket-%s"
+	location      = "US"
+	force_destroy = true
+}
+resource "google_dataflow_job" "pubsub_stream" {
+	name = "tf-test-dataflow-job-%s"
+	template_gcs_path = "%s"
+	temp_gcs_location = google_storage_bucket.bucket.url
+	parameters = {
+	  inputFilePattern = "${google_storage_bucket.bucket.url}/*.json"
+	  outputTopic    = google_pubsub_topic.topic.id
+	}%s
+	on_delete = "cancel"
+	update_strategy = "%s"
+}
+  `, suffix, suffix, suffix, testDataflowJobTemplateTextToPubsub, transformNameMapping, updateStrategy)
+}
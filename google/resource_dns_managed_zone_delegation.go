@@ -0,0 +1,615 @@
+package google
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-provider-google/google/tpgresource"
+	transport_tpg "github.com/hashicorp/terraform-provider-google/google/transport"
+	"github.com/hashicorp/terraform-provider-google/google/verify"
+	"google.golang.org/api/dns/v1"
+)
+
+func ResourceDnsManagedZoneDelegation() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceDnsManagedZoneDelegationCreate,
+		Read:   resourceDnsManagedZoneDelegationRead,
+		Update: resourceDnsManagedZoneDelegationUpdate,
+		Delete: resourceDnsManagedZoneDelegationDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceDnsManagedZoneDelegationImportState,
+		},
+
+		CustomizeDiff: resourceDnsManagedZoneDelegationCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			"parent_managed_zone": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				DiffSuppressFunc: tpgresource.CompareSelfLinkOrResourceName,
+				Description:      `The name of the zone holding the delegation.`,
+			},
+
+			"child_managed_zone": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				DiffSuppressFunc: tpgresource.CompareSelfLinkOrResourceName,
+				Description:      `The name of the delegated zone.`,
+			},
+
+			"parent_project": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: `The project of the parent zone. Defaults to the provider project.`,
+			},
+
+			"child_project": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: `The project of the child zone. Defaults to the provider project.`,
+			},
+
+			"ttl": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     300,
+				Description: `The time-to-live in seconds of the NS and DS record sets in the parent zone.`,
+			},
+
+			"digest_type": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "sha256",
+				ValidateFunc: verify.ValidateEnum([]string{"sha1", "sha256", "sha384"}),
+				Description:  `The digest type of the DS records published for the child's key-signing keys.`,
+			},
+
+			"name": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The DNS name of the child zone, where the NS and DS records are published in the parent zone.`,
+			},
+
+			"name_servers": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: `The NS records for the child zone in the parent zone.`,
+			},
+
+			"ds_records": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: `The DS records for the child zone in the parent zone.`,
+			},
+
+			"ds_withdraw_after": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The time after which DS records of retired key-signing keys are withdrawn from the parent zone, one DS record set TTL after DS records were last published.`,
+			},
+		},
+	}
+}
+
+// dnsDelegation is the NS and DS records a parent zone should hold for a
+// child zone.
+type dnsDelegation struct {
+	Name        string
+	NameServers []string
+	DsRecords   []string
+}
+
+// normalizeDnsDsRecord formats a DS record's rdata with single spaces and a
+// lowercase digest, so records returned by Cloud DNS compare equal to ours.
+func normalizeDnsDsRecord(rrdata string) string {
+	return strings.ToLower(strings.Join(strings.Fields(rrdata), " "))
+}
+
+// dnsKeyDsRecord builds the DS record of a key-signing key for digestType.
+func dnsKeyDsRecord(key *dns.DnsKey, digestType string) (string, error) {
+	algoNum, found := dnssecAlgoNums[key.Algorithm]
+	if !found {
+		return "", fmt.Errorf("DNSSEC Algorithm number for %s not found", key.Algorithm)
+	}
+	for _, digest := range key.Digests {
+		if digest.Type == digestType {
+			return normalizeDnsDsRecord(fmt.Sprintf("%d %d %d %s", key.KeyTag, algoNum, dnssecDigestType[digestType], digest.Digest)), nil
+		}
+	}
+	return "", fmt.Errorf("key-signing key %d has no %s digest", key.KeyTag, digestType)
+}
+
+// dnsDelegationProject returns the project in key, or the provider project.
+func dnsDelegationProject(d interface{ Get(string) interface{} }, key string, config *transport_tpg.Config) (string, error) {
+	if v := d.Get(key).(string); v != "" {
+		return v, nil
+	}
+	if config.Project != "" {
+		return config.Project, nil
+	}
+	return "", fmt.Errorf("%s: required field is not set", key)
+}
+
+// expandDnsDelegation reads the child zone and returns the delegation the
+// parent zone should hold for it: the child's name servers, and DS records
+// for its active key-signing keys while DNSSEC is enabled.
+func expandDnsDelegation(config *transport_tpg.Config, userAgent, project, zone, digestType string) (*dnsDelegation, error) {
+	client := config.NewDnsClient(userAgent)
+	mz, err := client.ManagedZones.Get(project, zone).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	delegation := &dnsDelegation{Name: strings.ToLower(mz.DnsName)}
+	for _, ns := range mz.NameServers {
+		delegation.NameServers = append(delegation.NameServers, strings.ToLower(qualifyDnsName(ns, ".")))
+	}
+	if len(delegation.NameServers) == 0 {
+		return nil, fmt.Errorf("%q has no name servers to delegate to", zone)
+	}
+	sort.Strings(delegation.NameServers)
+
+	if mz.DnssecConfig == nil || mz.DnssecConfig.State == "" || mz.DnssecConfig.State == "off" {
+		return delegation, nil
+	}
+
+	err = client.DnsKeys.List(project, zone).Pages(config.Context, func(res *dns.DnsKeysListResponse) error {
+		for _, key := range res.DnsKeys {
+			if key.Type != "keySigning" || !key.IsActive {
+				continue
+			}
+			ds, err := dnsKeyDsRecord(key, digestType)
+			if err != nil {
+				return err
+			}
+			delegation.DsRecords = append(delegation.DsRecords, ds)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error retrieving DNS keys of %q: %s", zone, err)
+	}
+	if len(delegation.DsRecords) == 0 {
+		// Removing every DS record of a signed zone would be safe, but it's
+		// almost certainly a rollover caught halfway, so don't guess.
+		return nil, fmt.Errorf("DNSSEC is %s for %q but it has no active key-signing keys", mz.DnssecConfig.State, zone)
+	}
+	sort.Strings(delegation.DsRecords)
+	return delegation, nil
+}
+
+// dnsDelegationChanges returns the changes that make the parent zone's live NS
+// and DS record sets for the child match desired. New DS records are published
+// in the first change, together with the NS records, and DS records of retired
+// keys are removed in the second one. The second change must only be applied
+// once resolvers can no longer hold a DS record set without the new records,
+// see dnsDelegationPlanDsRecords. Either change may be nil.
+func dnsDelegationChanges(liveNs, liveDs *dns.ResourceRecordSet, desired *dnsDelegation, ttl int64) (publish, withdraw *dns.Change) {
+	publish, withdraw = &dns.Change{}, &dns.Change{}
+
+	ns := &dns.ResourceRecordSet{Name: desired.Name, Type: "NS", Ttl: ttl, Rrdatas: desired.NameServers}
+	if liveNs == nil || !dnsRecordSetsEqual(liveNs, ns) {
+		if liveNs != nil {
+			publish.Deletions = append(publish.Deletions, liveNs)
+		}
+		publish.Additions = append(publish.Additions, ns)
+	}
+
+	var live []string
+	if liveDs != nil {
+		live = dnsDelegationRecordUnion(liveDs.Rrdatas, nil)
+	}
+	union := dnsDelegationRecordUnion(live, desired.DsRecords)
+
+	// The published set always includes desired, so comparing sizes is enough.
+	published, publishedCount := liveDs, len(live)
+	if len(desired.DsRecords) > 0 && (liveDs == nil || len(union) != len(live) || liveDs.Ttl != ttl) {
+		if liveDs != nil {
+			publish.Deletions = append(publish.Deletions, liveDs)
+		}
+		published, publishedCount = &dns.ResourceRecordSet{Name: desired.Name, Type: "DS", Ttl: ttl, Rrdatas: union}, len(union)
+		publish.Additions = append(publish.Additions, published)
+	}
+
+	if published != nil && publishedCount != len(desired.DsRecords) {
+		withdraw.Deletions = append(withdraw.Deletions, published)
+		if len(desired.DsRecords) > 0 {
+			withdraw.Additions = append(withdraw.Additions, &dns.ResourceRecordSet{Name: desired.Name, Type: "DS", Ttl: ttl, Rrdatas: desired.DsRecords})
+		}
+	}
+
+	if len(publish.Additions) == 0 && len(publish.Deletions) == 0 {
+		publish = nil
+	}
+	if len(withdraw.Additions) == 0 && len(withdraw.Deletions) == 0 {
+		withdraw = nil
+	}
+	return publish, withdraw
+}
+
+// dnsDelegationPlanDsRecords returns the DS records the parent zone holds
+// after the next apply, given the live ones and those of the child's active
+// key-signing keys. New records are published right away. Retired ones are
+// only withdrawn once nothing new is to be published and withdrawAfter has
+// passed, so that resolvers have had a whole TTL to pick up the records of the
+// keys replacing them; publishing first alone doesn't guarantee that, as
+// resolvers may hold the previous DS record set until it expires. Once DNSSEC
+// is turned off, every record is withdrawn right away.
+// resetWithdrawAfter is true if the apply sets a new withdrawAfter.
+func dnsDelegationPlanDsRecords(live, desired []string, withdrawAfter string, now time.Time) (planned []string, resetWithdrawAfter bool, err error) {
+	live = dnsDelegationRecordUnion(live, nil)
+	union := dnsDelegationRecordUnion(live, desired)
+	publishing := len(union) != len(live)
+
+	if len(union) == len(desired) {
+		return union, publishing, nil
+	}
+	if len(desired) == 0 {
+		// Withdrawing every DS record makes the child zone unsigned from the
+		// parent's point of view, which is always safe.
+		return []string{}, false, nil
+	}
+	if publishing {
+		return union, true, nil
+	}
+	if withdrawAfter == "" {
+		// Imported, or the records were published before the time was tracked.
+		return live, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, withdrawAfter)
+	if err != nil {
+		return nil, false, fmt.Errorf("Error parsing ds_withdraw_after %q: %s", withdrawAfter, err)
+	}
+	if now.Before(t) {
+		return live, false, nil
+	}
+	return dnsDelegationRecordUnion(desired, nil), false, nil
+}
+
+// dnsDelegationPlannedDsRecords returns the DS records in the plan being
+// applied, if they were known when planning.
+func dnsDelegationPlannedDsRecords(d *schema.ResourceData) ([]interface{}, bool) {
+	plan := d.GetRawPlan()
+	if plan.IsNull() || !plan.GetAttr("ds_records").IsWhollyKnown() {
+		return nil, false
+	}
+	return d.Get("ds_records").([]interface{}), true
+}
+
+// dnsDelegationRecordUnion returns the sorted union of two sets of DS records.
+func dnsDelegationRecordUnion(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	union := []string{}
+	for _, rrdata := range append(append([]string{}, a...), b...) {
+		rrdata = normalizeDnsDsRecord(rrdata)
+		if !seen[rrdata] {
+			seen[rrdata] = true
+			union = append(union, rrdata)
+		}
+	}
+	sort.Strings(union)
+	return union
+}
+
+// getDnsDelegationRecordSets returns the live NS and DS record sets for name
+// in a zone, either of which may be nil.
+func getDnsDelegationRecordSets(config *transport_tpg.Config, userAgent, project, zone, name string) (ns, ds *dns.ResourceRecordSet, err error) {
+	err = transport_tpg.Retry(func() error {
+		ns, ds = nil, nil
+		return config.NewDnsClient(userAgent).ResourceRecordSets.List(project, zone).Name(name).Pages(config.Context, func(res *dns.ResourceRecordSetsListResponse) error {
+			for _, set := range res.Rrsets {
+				switch set.Type {
+				case "NS":
+					ns = set
+				case "DS":
+					ds = set
+				}
+			}
+			return nil
+		})
+	})
+	return ns, ds, err
+}
+
+func applyDnsDelegationChange(config *transport_tpg.Config, userAgent, project, zone string, chg *dns.Change) error {
+	log.Printf("[DEBUG] DNS delegation change request for %q: %d additions, %d deletions", zone, len(chg.Additions), len(chg.Deletions))
+	chg, err := config.NewDnsClient(userAgent).Changes.Create(project, zone, chg).Do()
+	if err != nil {
+		return fmt.Errorf("Error changing delegation records of zone %q: %s", zone, err)
+	}
+
+	w := &DnsChangeWaiter{
+		Service:     config.NewDnsClient(userAgent),
+		Change:      chg,
+		Project:     project,
+		ManagedZone: zone,
+	}
+	if _, err = w.Conf().WaitForState(); err != nil {
+		return fmt.Errorf("Error waiting for Google DNS change: %s", err)
+	}
+	return nil
+}
+
+func resourceDnsManagedZoneDelegationCustomizeDiff(_ context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+	if !diff.NewValueKnown("child_managed_zone") || !diff.NewValueKnown("child_project") {
+		return dnsDelegationSetNewComputed(diff)
+	}
+
+	config := meta.(*transport_tpg.Config)
+	project, err := dnsDelegationProject(diff, "child_project", config)
+	if err != nil {
+		return err
+	}
+	zone := tpgresource.GetResourceNameFromSelfLink(diff.Get("child_managed_zone").(string))
+	desired, err := expandDnsDelegation(config, config.UserAgent, project, zone, diff.Get("digest_type").(string))
+	if err != nil {
+		if transport_tpg.IsGoogleApiErrorWithCode(err, 404) {
+			// The child zone is created in the same apply.
+			return dnsDelegationSetNewComputed(diff)
+		}
+		return err
+	}
+
+	if diff.Get("name").(string) != desired.Name {
+		if err := diff.SetNew("name", desired.Name); err != nil {
+			return err
+		}
+	}
+	if !dnsDelegationRecordsEqual(diff.Get("name_servers").([]interface{}), desired.NameServers) {
+		if err := diff.SetNew("name_servers", desired.NameServers); err != nil {
+			return err
+		}
+	}
+	live := tpgresource.ConvertStringArr(diff.Get("ds_records").([]interface{}))
+	planned, resetWithdrawAfter, err := dnsDelegationPlanDsRecords(live, desired.DsRecords, diff.Get("ds_withdraw_after").(string), time.Now())
+	if err != nil {
+		return err
+	}
+	if !dnsDelegationRecordsEqual(diff.Get("ds_records").([]interface{}), planned) {
+		if err := diff.SetNew("ds_records", planned); err != nil {
+			return err
+		}
+	}
+	if resetWithdrawAfter {
+		return diff.SetNewComputed("ds_withdraw_after")
+	}
+	return nil
+}
+
+func dnsDelegationSetNewComputed(diff *schema.ResourceDiff) error {
+	for _, k := range []string{"name", "name_servers", "ds_records", "ds_withdraw_after"} {
+		if err := diff.SetNewComputed(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func dnsDelegationRecordsEqual(current []interface{}, desired []string) bool {
+	if len(current) != len(desired) {
+		return false
+	}
+	for i, v := range tpgresource.ConvertStringArr(current) {
+		if v != desired[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func resourceDnsManagedZoneDelegationApply(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	parentProject, err := dnsDelegationProject(d, "parent_project", config)
+	if err != nil {
+		return err
+	}
+	childProject, err := dnsDelegationProject(d, "child_project", config)
+	if err != nil {
+		return err
+	}
+	parentZone := tpgresource.GetResourceNameFromSelfLink(d.Get("parent_managed_zone").(string))
+	childZone := tpgresource.GetResourceNameFromSelfLink(d.Get("child_managed_zone").(string))
+
+	parent, err := config.NewDnsClient(userAgent).ManagedZones.Get(parentProject, parentZone).Do()
+	if err != nil {
+		return fmt.Errorf("Error retrieving managed zone %q from %q: %s", parentZone, parentProject, err)
+	}
+	desired, err := expandDnsDelegation(config, userAgent, childProject, childZone, d.Get("digest_type").(string))
+	if err != nil {
+		return fmt.Errorf("Error retrieving managed zone %q from %q: %s", childZone, childProject, err)
+	}
+	if !strings.HasSuffix(desired.Name, "."+strings.ToLower(parent.DnsName)) {
+		return fmt.Errorf("%q (%s) isn't a subdomain of %q (%s)", childZone, desired.Name, parentZone, parent.DnsName)
+	}
+
+	liveNs, liveDs, err := getDnsDelegationRecordSets(config, userAgent, parentProject, parentZone, desired.Name)
+	if err != nil {
+		return fmt.Errorf("Error retrieving record sets for %q: %s", parentZone, err)
+	}
+	if liveDs != nil && len(desired.DsRecords) > 0 && len(dnsDelegationRecordUnion(liveDs.Rrdatas, desired.DsRecords)) == len(liveDs.Rrdatas)+len(desired.DsRecords) {
+		log.Printf("[WARN] None of the DS records of %q in %q match an active key-signing key of %q; resolvers may fail to validate it until the new DS records propagate", desired.Name, parentZone, childZone)
+	}
+
+	ttl := int64(d.Get("ttl").(int))
+	var live []string
+	if liveDs != nil {
+		live = liveDs.Rrdatas
+		if liveDs.Ttl > ttl {
+			// Resolvers may hold the live DS record set for its own TTL.
+			ttl = liveDs.Ttl
+		}
+	}
+	publishing := len(dnsDelegationRecordUnion(live, desired.DsRecords)) != len(dnsDelegationRecordUnion(live, nil))
+
+	publish, withdraw := dnsDelegationChanges(liveNs, liveDs, desired, int64(d.Get("ttl").(int)))
+	if publish != nil {
+		if err := applyDnsDelegationChange(config, userAgent, parentProject, parentZone, publish); err != nil {
+			return err
+		}
+	}
+	withdrawAfter := d.Get("ds_withdraw_after").(string)
+	if publishing {
+		withdrawAfter = time.Now().Add(time.Duration(ttl) * time.Second).UTC().Format(time.RFC3339)
+	}
+	if withdraw != nil {
+		// Whether retired DS records are withdrawn is decided by the plan.
+		planned, known := dnsDelegationPlannedDsRecords(d)
+		if known && dnsDelegationRecordsEqual(planned, desired.DsRecords) {
+			if err := applyDnsDelegationChange(config, userAgent, parentProject, parentZone, withdraw); err != nil {
+				return err
+			}
+		} else {
+			if withdrawAfter == "" {
+				withdrawAfter = time.Now().Add(time.Duration(ttl) * time.Second).UTC().Format(time.RFC3339)
+			}
+			log.Printf("[DEBUG] Keeping retired DS records of %q in %q until %s", desired.Name, parentZone, withdrawAfter)
+		}
+	}
+	if err := d.Set("ds_withdraw_after", withdrawAfter); err != nil {
+		return fmt.Errorf("Error setting ds_withdraw_after: %s", err)
+	}
+
+	if err := d.Set("parent_project", parentProject); err != nil {
+		return fmt.Errorf("Error setting parent_project: %s", err)
+	}
+	if err := d.Set("child_project", childProject); err != nil {
+		return fmt.Errorf("Error setting child_project: %s", err)
+	}
+	if err := d.Set("name", desired.Name); err != nil {
+		return fmt.Errorf("Error setting name: %s", err)
+	}
+	d.SetId(fmt.Sprintf("%s/%s/%s/%s", parentProject, parentZone, childProject, childZone))
+
+	return resourceDnsManagedZoneDelegationRead(d, meta)
+}
+
+func resourceDnsManagedZoneDelegationCreate(d *schema.ResourceData, meta interface{}) error {
+	return resourceDnsManagedZoneDelegationApply(d, meta)
+}
+
+func resourceDnsManagedZoneDelegationUpdate(d *schema.ResourceData, meta interface{}) error {
+	return resourceDnsManagedZoneDelegationApply(d, meta)
+}
+
+func resourceDnsManagedZoneDelegationRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	parentProject := d.Get("parent_project").(string)
+	parentZone := tpgresource.GetResourceNameFromSelfLink(d.Get("parent_managed_zone").(string))
+	name := d.Get("name").(string)
+	if name == "" {
+		// Imported: the name comes from the child zone.
+		childZone := tpgresource.GetResourceNameFromSelfLink(d.Get("child_managed_zone").(string))
+		mz, err := config.NewDnsClient(userAgent).ManagedZones.Get(d.Get("child_project").(string), childZone).Do()
+		if err != nil {
+			return transport_tpg.HandleNotFoundError(err, d, fmt.Sprintf("DNS Managed Zone %q", childZone))
+		}
+		name = strings.ToLower(mz.DnsName)
+	}
+
+	ns, ds, err := getDnsDelegationRecordSets(config, userAgent, parentProject, parentZone, name)
+	if err != nil {
+		return transport_tpg.HandleNotFoundError(err, d, fmt.Sprintf("DNS Managed Zone %q", parentZone))
+	}
+
+	nameServers, dsRecords := []string{}, []string{}
+	if ns != nil {
+		for _, rrdata := range ns.Rrdatas {
+			nameServers = append(nameServers, strings.ToLower(rrdata))
+		}
+		sort.Strings(nameServers)
+	}
+	if ds != nil {
+		dsRecords = dnsDelegationRecordUnion(ds.Rrdatas, nil)
+	}
+
+	if err := d.Set("name", name); err != nil {
+		return fmt.Errorf("Error setting name: %s", err)
+	}
+	if err := d.Set("name_servers", nameServers); err != nil {
+		return fmt.Errorf("Error setting name_servers: %s", err)
+	}
+	if err := d.Set("ds_records", dsRecords); err != nil {
+		return fmt.Errorf("Error setting ds_records: %s", err)
+	}
+	if ns != nil {
+		if err := d.Set("ttl", ns.Ttl); err != nil {
+			return fmt.Errorf("Error setting ttl: %s", err)
+		}
+	}
+
+	return nil
+}
+
+func resourceDnsManagedZoneDelegationDelete(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	parentProject := d.Get("parent_project").(string)
+	parentZone := tpgresource.GetResourceNameFromSelfLink(d.Get("parent_managed_zone").(string))
+
+	ns, ds, err := getDnsDelegationRecordSets(config, userAgent, parentProject, parentZone, d.Get("name").(string))
+	if err != nil {
+		return transport_tpg.HandleNotFoundError(err, d, fmt.Sprintf("DNS Managed Zone %q", parentZone))
+	}
+
+	// Removing the DS records together with the NS records makes the child
+	// zone unsigned from the parent's point of view, which is always safe.
+	chg := &dns.Change{}
+	for _, set := range []*dns.ResourceRecordSet{ds, ns} {
+		if set != nil {
+			chg.Deletions = append(chg.Deletions, set)
+		}
+	}
+	if len(chg.Deletions) > 0 {
+		if err := applyDnsDelegationChange(config, userAgent, parentProject, parentZone, chg); err != nil {
+			return err
+		}
+	}
+
+	d.SetId("")
+	return nil
+}
+
+func resourceDnsManagedZoneDelegationImportState(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	config := meta.(*transport_tpg.Config)
+	if err := tpgresource.ParseImportId([]string{
+		"(?P<parent_project>[^/]+)/(?P<parent_managed_zone>[^/]+)/(?P<child_project>[^/]+)/(?P<child_managed_zone>[^/]+)",
+	}, d, config); err != nil {
+		return nil, err
+	}
+
+	if err := d.Set("ttl", 300); err != nil {
+		return nil, fmt.Errorf("Error setting ttl: %s", err)
+	}
+	if err := d.Set("digest_type", "sha256"); err != nil {
+		return nil, fmt.Errorf("Error setting digest_type: %s", err)
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,251 @@
+package google
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"google.golang.org/api/dns/v1"
+
+	"github.com/hashicorp/terraform-provider-google/google/acctest"
+)
+
+func TestDnsKeyDsRecord(t *testing.T) {
+	t.Parallel()
+
+	key := &dns.DnsKey{
+		Algorithm: "rsasha256",
+		KeyTag:    2371,
+		Digests: []*dns.DnsKeyDigest{
+			{Type: "sha1", Digest: "AAAA"},
+			{Type: "sha256", Digest: "BBBB"},
+		},
+	}
+
+	ds, err := dnsKeyDsRecord(key, "sha256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ds != "2371 8 2 bbbb" {
+		t.Errorf("got %q, expected %q", ds, "2371 8 2 bbbb")
+	}
+
+	if _, err := dnsKeyDsRecord(key, "sha384"); err == nil {
+		t.Errorf("expected an error for a missing digest")
+	}
+}
+
+func TestDnsDelegationChanges(t *testing.T) {
+	t.Parallel()
+
+	name := "child.example.com."
+	nameServers := []string{"ns-a.example.net.", "ns-b.example.net."}
+	liveNs := &dns.ResourceRecordSet{Name: name, Type: "NS", Ttl: 300, Rrdatas: nameServers}
+	ds := func(rrdatas ...string) *dns.ResourceRecordSet {
+		return &dns.ResourceRecordSet{Name: name, Type: "DS", Ttl: 300, Rrdatas: rrdatas}
+	}
+	summarize := func(chg *dns.Change) []string {
+		if chg == nil {
+			return nil
+		}
+		summary := []string{}
+		for _, set := range chg.Deletions {
+			summary = append(summary, fmt.Sprintf("-%s %v", set.Type, set.Rrdatas))
+		}
+		for _, set := range chg.Additions {
+			summary = append(summary, fmt.Sprintf("+%s %v", set.Type, set.Rrdatas))
+		}
+		return summary
+	}
+
+	cases := map[string]struct {
+		LiveNs    *dns.ResourceRecordSet
+		LiveDs    *dns.ResourceRecordSet
+		DsRecords []string
+		Publish   []string
+		Withdraw  []string
+	}{
+		"new delegation": {
+			DsRecords: []string{"1 8 2 aa"},
+			Publish:   []string{"+NS [ns-a.example.net. ns-b.example.net.]", "+DS [1 8 2 aa]"},
+		},
+		"unsigned delegation": {
+			Publish: []string{"+NS [ns-a.example.net. ns-b.example.net.]"},
+		},
+		"up to date": {
+			LiveNs:    liveNs,
+			LiveDs:    ds("1 8 2 AA"),
+			DsRecords: []string{"1 8 2 aa"},
+		},
+		"new key is published": {
+			LiveNs:    liveNs,
+			LiveDs:    ds("1 8 2 aa"),
+			DsRecords: []string{"1 8 2 aa", "2 8 2 bb"},
+			Publish:   []string{"-DS [1 8 2 aa]", "+DS [1 8 2 aa 2 8 2 bb]"},
+		},
+		"retired key is withdrawn": {
+			LiveNs:    liveNs,
+			LiveDs:    ds("1 8 2 aa", "2 8 2 bb"),
+			DsRecords: []string{"2 8 2 bb"},
+			Withdraw:  []string{"-DS [1 8 2 aa 2 8 2 bb]", "+DS [2 8 2 bb]"},
+		},
+		"rolled over key is published before the old one is withdrawn": {
+			LiveNs:    liveNs,
+			LiveDs:    ds("1 8 2 aa"),
+			DsRecords: []string{"2 8 2 bb"},
+			Publish:   []string{"-DS [1 8 2 aa]", "+DS [1 8 2 aa 2 8 2 bb]"},
+			Withdraw:  []string{"-DS [1 8 2 aa 2 8 2 bb]", "+DS [2 8 2 bb]"},
+		},
+		"dnssec turned off": {
+			LiveNs:   liveNs,
+			LiveDs:   ds("1 8 2 aa"),
+			Withdraw: []string{"-DS [1 8 2 aa]"},
+		},
+		"name servers changed": {
+			LiveNs:    &dns.ResourceRecordSet{Name: name, Type: "NS", Ttl: 300, Rrdatas: []string{"ns-old.example.net."}},
+			LiveDs:    ds("1 8 2 aa"),
+			DsRecords: []string{"1 8 2 aa"},
+			Publish:   []string{"-NS [ns-old.example.net.]", "+NS [ns-a.example.net. ns-b.example.net.]"},
+		},
+	}
+
+	for tn, tc := range cases {
+		desired := &dnsDelegation{Name: name, NameServers: nameServers, DsRecords: tc.DsRecords}
+		publish, withdraw := dnsDelegationChanges(tc.LiveNs, tc.LiveDs, desired, 300)
+		if got := summarize(publish); !reflect.DeepEqual(got, tc.Publish) {
+			t.Errorf("%s: got published %v, expected %v", tn, got, tc.Publish)
+		}
+		if got := summarize(withdraw); !reflect.DeepEqual(got, tc.Withdraw) {
+			t.Errorf("%s: got withdrawn %v, expected %v", tn, got, tc.Withdraw)
+		}
+	}
+}
+
+func TestDnsDelegationPlanDsRecords(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	cases := map[string]struct {
+		Live               []string
+		Desired            []string
+		WithdrawAfter      string
+		Planned            []string
+		ResetWithdrawAfter bool
+	}{
+		"up to date": {
+			Live:          []string{"1 8 2 aa"},
+			Desired:       []string{"1 8 2 aa"},
+			WithdrawAfter: "2023-05-01T11:00:00Z",
+			Planned:       []string{"1 8 2 aa"},
+		},
+		"new key is published": {
+			Live:               []string{"1 8 2 aa"},
+			Desired:            []string{"1 8 2 aa", "2 8 2 bb"},
+			WithdrawAfter:      "2023-05-01T11:00:00Z",
+			Planned:            []string{"1 8 2 aa", "2 8 2 bb"},
+			ResetWithdrawAfter: true,
+		},
+		"rolled over key is published, the old one is kept": {
+			Live:               []string{"1 8 2 aa"},
+			Desired:            []string{"2 8 2 bb"},
+			WithdrawAfter:      "2023-05-01T11:00:00Z",
+			Planned:            []string{"1 8 2 aa", "2 8 2 bb"},
+			ResetWithdrawAfter: true,
+		},
+		"retired key is kept until the ttl has passed": {
+			Live:          []string{"1 8 2 aa", "2 8 2 bb"},
+			Desired:       []string{"2 8 2 bb"},
+			WithdrawAfter: "2023-05-01T12:05:00Z",
+			Planned:       []string{"1 8 2 aa", "2 8 2 bb"},
+		},
+		"retired key is withdrawn once the ttl has passed": {
+			Live:          []string{"1 8 2 aa", "2 8 2 bb"},
+			Desired:       []string{"2 8 2 bb"},
+			WithdrawAfter: "2023-05-01T12:00:00Z",
+			Planned:       []string{"2 8 2 bb"},
+		},
+		"retired key is kept when the publish time is unknown": {
+			Live:               []string{"1 8 2 aa", "2 8 2 bb"},
+			Desired:            []string{"2 8 2 bb"},
+			Planned:            []string{"1 8 2 aa", "2 8 2 bb"},
+			ResetWithdrawAfter: true,
+		},
+		"dnssec turned off": {
+			Live:          []string{"1 8 2 aa"},
+			WithdrawAfter: "2023-05-01T12:05:00Z",
+			Planned:       []string{},
+		},
+	}
+
+	for tn, tc := range cases {
+		planned, reset, err := dnsDelegationPlanDsRecords(tc.Live, tc.Desired, tc.WithdrawAfter, now)
+		if err != nil {
+			t.Errorf("%s: %s", tn, err)
+			continue
+		}
+		if !reflect.DeepEqual(planned, tc.Planned) {
+			t.Errorf("%s: got planned %v, expected %v", tn, planned, tc.Planned)
+		}
+		if reset != tc.ResetWithdrawAfter {
+			t.Errorf("%s: got resetWithdrawAfter %v, expected %v", tn, reset, tc.ResetWithdrawAfter)
+		}
+	}
+}
+
+func TestAccDnsManagedZoneDelegation_basic(t *testing.T) {
+	t.Parallel()
+
+	suffix := RandString(t, 10)
+	VcrTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.AccTestPreCheck(t) },
+		ProtoV5ProviderFactories: ProtoV5ProviderFactories(t),
+		CheckDestroy:             testAccCheckDNSManagedZoneDestroyProducer(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDnsManagedZoneDelegation_basic(suffix, "on"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("google_dns_managed_zone_delegation.delegation", "name_servers.#", "4"),
+					resource.TestCheckResourceAttr("google_dns_managed_zone_delegation.delegation", "ds_records.#", "1"),
+				),
+			},
+			{
+				ResourceName:            "google_dns_managed_zone_delegation.delegation",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"ds_withdraw_after"},
+			},
+			{
+				Config: testAccDnsManagedZoneDelegation_basic(suffix, "off"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("google_dns_managed_zone_delegation.delegation", "name_servers.#", "4"),
+					resource.TestCheckResourceAttr("google_dns_managed_zone_delegation.delegation", "ds_records.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDnsManagedZoneDelegation_basic(suffix, dnssecState string) string {
+	return fmt.Sprintf(`
+resource "google_dns_managed_zone" "parent" {
+  name     = "mzone-parent-%s"
+  dns_name = "tf-acctest-%s.hashicorptest.com."
+}
+
+resource "google_dns_managed_zone" "child" {
+  name     = "mzone-child-%s"
+  dns_name = "child.tf-acctest-%s.hashicorptest.com."
+
+  dnssec_config {
+    state = "%s"
+  }
+}
+
+resource "google_dns_managed_zone_delegation" "delegation" {
+  parent_managed_zone = google_dns_managed_zone.parent.name
+  child_managed_zone  = google_dns_managed_zone.child.name
+}
+`, suffix, suffix, suffix, suffix, dnssecState)
+}
@@ -0,0 +1,425 @@
+package google
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-provider-google/google/tpgresource"
+	transport_tpg "github.com/hashicorp/terraform-provider-google/google/transport"
+	"google.golang.org/api/dns/v1"
+)
+
+func ResourceDnsManagedZoneRecords() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceDnsManagedZoneRecordsCreate,
+		Read:   resourceDnsManagedZoneRecordsRead,
+		Update: resourceDnsManagedZoneRecordsUpdate,
+		Delete: resourceDnsManagedZoneRecordsDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceDnsManagedZoneRecordsImportState,
+		},
+
+		CustomizeDiff: resourceDnsManagedZoneRecordsCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			"managed_zone": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				DiffSuppressFunc: tpgresource.CompareSelfLinkOrResourceName,
+				Description:      `The name of the zone whose records are managed.`,
+			},
+
+			"zone_file": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"zone_file", "record"},
+				Description:  `The contents of a BIND zone file. Relative names are qualified with the DNS name of the zone unless the file sets $ORIGIN.`,
+			},
+
+			"record": {
+				Type:         schema.TypeList,
+				Optional:     true,
+				ExactlyOneOf: []string{"zone_file", "record"},
+				Description:  `A record set of the zone, as an alternative to zone_file.`,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: `The DNS name of the record set. Relative names are qualified with the DNS name of the zone, and "@" is the zone itself.`,
+						},
+						"type": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: `The DNS record type.`,
+						},
+						"ttl": {
+							Type:        schema.TypeInt,
+							Optional:    true,
+							Description: `The time-to-live of the record set in seconds. Defaults to default_ttl.`,
+						},
+						"rrdatas": {
+							Type:        schema.TypeList,
+							Required:    true,
+							MinItems:    1,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: `The string data for the records in this record set.`,
+						},
+					},
+				},
+			},
+
+			"default_ttl": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     300,
+				Description: `The TTL of records that don't set one, unless the zone file sets $TTL.`,
+			},
+
+			"manage_apex_soa_ns": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: `Whether the SOA and NS record sets at the apex of the zone are managed. When false, apex SOA and NS records in zone_file or record are ignored and the ones created by Cloud DNS are left untouched.`,
+			},
+
+			"project": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"record_sets": {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Description: `The record sets of the zone managed by this resource.`,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"ttl": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"rrdatas": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// expandDnsManagedZoneRecords returns the record sets configured for a zone
+// named dnsName, leaving out the apex SOA and NS sets unless they're managed.
+func expandDnsManagedZoneRecords(d interface{ Get(string) interface{} }, dnsName string) ([]*dns.ResourceRecordSet, error) {
+	defaultTtl := int64(d.Get("default_ttl").(int))
+
+	var sets []*dns.ResourceRecordSet
+	if zoneFile := d.Get("zone_file").(string); zoneFile != "" {
+		parsed, err := parseDnsZoneFile(zoneFile, dnsName, defaultTtl)
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing zone_file: %s", err)
+		}
+		sets = parsed
+	} else {
+		builder := newDnsRecordSetBuilder()
+		for _, raw := range d.Get("record").([]interface{}) {
+			record := raw.(map[string]interface{})
+			ttl := defaultTtl
+			if v := record["ttl"].(int); v > 0 {
+				ttl = int64(v)
+			}
+			name := strings.ToLower(qualifyDnsName(record["name"].(string), dnsName))
+			builder.add(name, strings.ToUpper(record["type"].(string)), ttl, tpgresource.ConvertStringArr(record["rrdatas"].([]interface{}))...)
+		}
+		sets = builder.recordSets()
+	}
+
+	if d.Get("manage_apex_soa_ns").(bool) {
+		return sets, nil
+	}
+	filtered := make([]*dns.ResourceRecordSet, 0, len(sets))
+	for _, set := range sets {
+		if !isDnsApexSoaOrNs(set, dnsName) {
+			filtered = append(filtered, set)
+		}
+	}
+	return filtered, nil
+}
+
+// filterDnsManagedZoneRecords returns the live record sets managed by the
+// resource: sets with a routing policy are managed by google_dns_record_set
+// and are skipped, as are the apex SOA and NS sets unless they're managed.
+func filterDnsManagedZoneRecords(live []*dns.ResourceRecordSet, dnsName string, manageApex bool) []*dns.ResourceRecordSet {
+	managed := make([]*dns.ResourceRecordSet, 0, len(live))
+	for _, set := range live {
+		if set.RoutingPolicy != nil || (!manageApex && isDnsApexSoaOrNs(set, dnsName)) {
+			continue
+		}
+		managed = append(managed, set)
+	}
+	sortDnsRecordSets(managed)
+	return managed
+}
+
+func flattenDnsManagedZoneRecordSets(sets []*dns.ResourceRecordSet) []interface{} {
+	transformed := make([]interface{}, 0, len(sets))
+	for _, set := range sets {
+		transformed = append(transformed, map[string]interface{}{
+			"name":    strings.ToLower(set.Name),
+			"type":    set.Type,
+			"ttl":     int(set.Ttl),
+			"rrdatas": tpgresource.ConvertStringArrToInterface(set.Rrdatas),
+		})
+	}
+	return transformed
+}
+
+func expandDnsManagedZoneRecordSets(v []interface{}) []*dns.ResourceRecordSet {
+	sets := make([]*dns.ResourceRecordSet, 0, len(v))
+	for _, raw := range v {
+		set := raw.(map[string]interface{})
+		sets = append(sets, &dns.ResourceRecordSet{
+			Name:    set["name"].(string),
+			Type:    set["type"].(string),
+			Ttl:     int64(set["ttl"].(int)),
+			Rrdatas: tpgresource.ConvertStringArr(set["rrdatas"].([]interface{})),
+		})
+	}
+	return sets
+}
+
+// dnsManagedZoneRecordSetsEqual reports whether the record sets in state are
+// the desired ones, comparing records in canonical form, as the spelling in
+// state is the one Cloud DNS returned.
+func dnsManagedZoneRecordSetsEqual(current, desired []*dns.ResourceRecordSet) bool {
+	if len(current) != len(desired) {
+		return false
+	}
+	currentByKey := make(map[string]*dns.ResourceRecordSet, len(current))
+	for _, set := range current {
+		currentByKey[dnsRecordSetKey(set.Name, set.Type)] = set
+	}
+	for _, set := range desired {
+		existing, ok := currentByKey[dnsRecordSetKey(set.Name, set.Type)]
+		if !ok || !dnsRecordSetsEqual(existing, set) {
+			return false
+		}
+	}
+	return true
+}
+
+func resourceDnsManagedZoneRecordsCustomizeDiff(_ context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+	if !diff.NewValueKnown("zone_file") || !diff.NewValueKnown("record") || !diff.NewValueKnown("managed_zone") {
+		return diff.SetNewComputed("record_sets")
+	}
+
+	config := meta.(*transport_tpg.Config)
+	project, err := tpgresource.GetProjectFromDiff(diff, config)
+	if err != nil {
+		return err
+	}
+	zone := tpgresource.GetResourceNameFromSelfLink(diff.Get("managed_zone").(string))
+	mz, err := config.NewDnsClient(config.UserAgent).ManagedZones.Get(project, zone).Do()
+	if err != nil {
+		if transport_tpg.IsGoogleApiErrorWithCode(err, 404) {
+			// The zone is created in the same apply.
+			return diff.SetNewComputed("record_sets")
+		}
+		return fmt.Errorf("Error retrieving managed zone %q: %s", zone, err)
+	}
+
+	desired, err := expandDnsManagedZoneRecords(diff, mz.DnsName)
+	if err != nil {
+		return err
+	}
+
+	// The records are only known in the form Cloud DNS returns them in once
+	// they're applied.
+	current := expandDnsManagedZoneRecordSets(diff.Get("record_sets").(*schema.Set).List())
+	if !dnsManagedZoneRecordSetsEqual(current, desired) {
+		return diff.SetNewComputed("record_sets")
+	}
+	return nil
+}
+
+func listDnsManagedZoneRecordSets(config *transport_tpg.Config, userAgent, project, zone string) ([]*dns.ResourceRecordSet, error) {
+	var sets []*dns.ResourceRecordSet
+	err := transport_tpg.Retry(func() error {
+		sets = nil
+		return config.NewDnsClient(userAgent).ResourceRecordSets.List(project, zone).Pages(config.Context, func(res *dns.ResourceRecordSetsListResponse) error {
+			sets = append(sets, res.Rrsets...)
+			return nil
+		})
+	})
+	return sets, err
+}
+
+// applyDnsManagedZoneRecords makes the zone's records match the configuration
+// in a single Cloud DNS change.
+func applyDnsManagedZoneRecords(config *transport_tpg.Config, userAgent, project, zone string, desired []*dns.ResourceRecordSet, dnsName string) error {
+	live, err := listDnsManagedZoneRecordSets(config, userAgent, project, zone)
+	if err != nil {
+		return fmt.Errorf("Error retrieving record sets for %q: %s", zone, err)
+	}
+
+	chg := dnsZoneRecordsChange(live, desired, dnsName)
+	if len(chg.Additions) == 0 && len(chg.Deletions) == 0 {
+		log.Printf("[DEBUG] Records of zone %q are up to date", zone)
+		return nil
+	}
+
+	log.Printf("[DEBUG] DNS zone records change request for %q: %d additions, %d deletions", zone, len(chg.Additions), len(chg.Deletions))
+	chg, err = config.NewDnsClient(userAgent).Changes.Create(project, zone, chg).Do()
+	if err != nil {
+		return fmt.Errorf("Error changing records of zone %q: %s", zone, err)
+	}
+
+	w := &DnsChangeWaiter{
+		Service:     config.NewDnsClient(userAgent),
+		Change:      chg,
+		Project:     project,
+		ManagedZone: zone,
+	}
+	if _, err = w.Conf().WaitForState(); err != nil {
+		return fmt.Errorf("Error waiting for Google DNS change: %s", err)
+	}
+	return nil
+}
+
+func resourceDnsManagedZoneRecordsApply(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	project, err := tpgresource.GetProject(d, config)
+	if err != nil {
+		return err
+	}
+
+	zone := tpgresource.GetResourceNameFromSelfLink(d.Get("managed_zone").(string))
+	mz, err := config.NewDnsClient(userAgent).ManagedZones.Get(project, zone).Do()
+	if err != nil {
+		return fmt.Errorf("Error retrieving managed zone %q from %q: %s", zone, project, err)
+	}
+
+	desired, err := expandDnsManagedZoneRecords(d, mz.DnsName)
+	if err != nil {
+		return err
+	}
+
+	if err := applyDnsManagedZoneRecords(config, userAgent, project, zone, desired, mz.DnsName); err != nil {
+		return err
+	}
+
+	d.SetId(fmt.Sprintf("projects/%s/managedZones/%s/rrsets", project, zone))
+
+	return resourceDnsManagedZoneRecordsRead(d, meta)
+}
+
+func resourceDnsManagedZoneRecordsCreate(d *schema.ResourceData, meta interface{}) error {
+	return resourceDnsManagedZoneRecordsApply(d, meta)
+}
+
+func resourceDnsManagedZoneRecordsUpdate(d *schema.ResourceData, meta interface{}) error {
+	return resourceDnsManagedZoneRecordsApply(d, meta)
+}
+
+func resourceDnsManagedZoneRecordsRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	project, err := tpgresource.GetProject(d, config)
+	if err != nil {
+		return err
+	}
+
+	zone := tpgresource.GetResourceNameFromSelfLink(d.Get("managed_zone").(string))
+	mz, err := config.NewDnsClient(userAgent).ManagedZones.Get(project, zone).Do()
+	if err != nil {
+		return transport_tpg.HandleNotFoundError(err, d, fmt.Sprintf("DNS Managed Zone %q", zone))
+	}
+
+	live, err := listDnsManagedZoneRecordSets(config, userAgent, project, zone)
+	if err != nil {
+		return transport_tpg.HandleNotFoundError(err, d, fmt.Sprintf("DNS Managed Zone %q", zone))
+	}
+
+	managed := filterDnsManagedZoneRecords(live, mz.DnsName, d.Get("manage_apex_soa_ns").(bool))
+	if err := d.Set("record_sets", flattenDnsManagedZoneRecordSets(managed)); err != nil {
+		return fmt.Errorf("Error setting record_sets: %s", err)
+	}
+	if err := d.Set("project", project); err != nil {
+		return fmt.Errorf("Error setting project: %s", err)
+	}
+
+	return nil
+}
+
+func resourceDnsManagedZoneRecordsDelete(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	project, err := tpgresource.GetProject(d, config)
+	if err != nil {
+		return err
+	}
+
+	zone := tpgresource.GetResourceNameFromSelfLink(d.Get("managed_zone").(string))
+	mz, err := config.NewDnsClient(userAgent).ManagedZones.Get(project, zone).Do()
+	if err != nil {
+		return transport_tpg.HandleNotFoundError(err, d, fmt.Sprintf("DNS Managed Zone %q", zone))
+	}
+
+	// Deleting leaves only the apex SOA and NS sets, which can't be deleted.
+	if err := applyDnsManagedZoneRecords(config, userAgent, project, zone, nil, mz.DnsName); err != nil {
+		return err
+	}
+
+	d.SetId("")
+	return nil
+}
+
+func resourceDnsManagedZoneRecordsImportState(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	config := meta.(*transport_tpg.Config)
+	if err := tpgresource.ParseImportId([]string{
+		"projects/(?P<project>[^/]+)/managedZones/(?P<managed_zone>[^/]+)/rrsets",
+		"projects/(?P<project>[^/]+)/managedZones/(?P<managed_zone>[^/]+)",
+		"(?P<project>[^/]+)/(?P<managed_zone>[^/]+)",
+		"(?P<managed_zone>[^/]+)",
+	}, d, config); err != nil {
+		return nil, err
+	}
+
+	// Replace import id for the resource id
+	id, err := tpgresource.ReplaceVars(d, config, "projects/{{project}}/managedZones/{{managed_zone}}/rrsets")
+	if err != nil {
+		return nil, fmt.Errorf("Error constructing id: %s", err)
+	}
+	d.SetId(id)
+
+	return []*schema.ResourceData{d}, nil
+}
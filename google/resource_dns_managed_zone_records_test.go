@@ -0,0 +1,88 @@
+package google
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+
+	"github.com/hashicorp/terraform-provider-google/google/acctest"
+)
+
+func TestAccDnsManagedZoneRecords_zoneFile(t *testing.T) {
+	t.Parallel()
+
+	zoneName := fmt.Sprintf("dnszone-test-%s", RandString(t, 10))
+	VcrTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.AccTestPreCheck(t) },
+		ProtoV5ProviderFactories: ProtoV5ProviderFactories(t),
+		CheckDestroy:             testAccCheckDNSManagedZoneDestroyProducer(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDnsManagedZoneRecords_zoneFile(zoneName, "192.0.2.2"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("google_dns_managed_zone_records.records", "record_sets.#", "3"),
+				),
+			},
+			{
+				ResourceName:            "google_dns_managed_zone_records.records",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"zone_file", "default_ttl", "manage_apex_soa_ns"},
+			},
+			{
+				Config: testAccDnsManagedZoneRecords_zoneFile(zoneName, "192.0.2.3"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("google_dns_managed_zone_records.records", "record_sets.#", "3"),
+				),
+			},
+			{
+				Config: testAccDnsManagedZoneRecords_record(zoneName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("google_dns_managed_zone_records.records", "record_sets.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDnsManagedZoneRecords_zoneFile(zoneName, addr2 string) string {
+	return fmt.Sprintf(`
+resource "google_dns_managed_zone" "zone" {
+  name        = "%s"
+  dns_name    = "%s.hashicorptest.com."
+  description = "Test Description"
+}
+
+resource "google_dns_managed_zone_records" "records" {
+  managed_zone = google_dns_managed_zone.zone.name
+  zone_file    = <<-EOT
+    $TTL 1h
+    www     IN  A     192.0.2.1
+            IN  A     %s
+    mail        MX    10 mx.example.com.
+    txt     300 TXT   "v=spf1 -all"
+  EOT
+}
+`, zoneName, zoneName, addr2)
+}
+
+func testAccDnsManagedZoneRecords_record(zoneName string) string {
+	return fmt.Sprintf(`
+resource "google_dns_managed_zone" "zone" {
+  name        = "%s"
+  dns_name    = "%s.hashicorptest.com."
+  description = "Test Description"
+}
+
+resource "google_dns_managed_zone_records" "records" {
+  managed_zone = google_dns_managed_zone.zone.name
+
+  record {
+    name    = "www"
+    type    = "A"
+    rrdatas = ["192.0.2.1"]
+  }
+}
+`, zoneName, zoneName)
+}
@@ -17,6 +17,7 @@ func ResourceGoogleServiceAccountKey() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceGoogleServiceAccountKeyCreate,
 		Read:   resourceGoogleServiceAccountKeyRead,
+		Update: resourceGoogleServiceAccountKeyUpdate,
 		Delete: resourceGoogleServiceAccountKeyDelete,
 		Schema: map[string]*schema.Schema{
 			// Required
@@ -91,6 +92,7 @@ func ResourceGoogleServiceAccountKey() *schema.Resource {
 				Computed:    true,
 				Description: `The key can be used before this timestamp. A timestamp in RFC3339 UTC "Zulu" format, accurate to nanoseconds. Example: "2014-10-02T15:01:23.045123456Z".`,
 			},
+			"secret_state_mode": tpgresource.SecretStateModeSchema(),
 		},
 		UseJSONNumber: true,
 	}
@@ -183,9 +185,19 @@ func resourceGoogleServiceAccountKeyRead(d *schema.ResourceData, meta interface{
 	if err := d.Set("public_key", sak.PublicKeyData); err != nil {
 		return fmt.Errorf("Error setting public_key: %s", err)
 	}
+	// The private key is only returned on create, so the value already in
+	// state is kept, hashed if needed.
+	if err := tpgresource.SetSecretState(d, config, "private_key", d.Get("private_key").(string)); err != nil {
+		return fmt.Errorf("Error setting private_key: %s", err)
+	}
 	return nil
 }
 
+// Only secret_state_mode can be updated, which is applied on read.
+func resourceGoogleServiceAccountKeyUpdate(d *schema.ResourceData, meta interface{}) error {
+	return resourceGoogleServiceAccountKeyRead(d, meta)
+}
+
 func resourceGoogleServiceAccountKeyDelete(d *schema.ResourceData, meta interface{}) error {
 	config := meta.(*transport_tpg.Config)
 	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
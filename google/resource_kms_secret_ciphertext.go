@@ -35,6 +35,7 @@ func ResourceKMSSecretCiphertext() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceKMSSecretCiphertextCreate,
 		Read:   resourceKMSSecretCiphertextRead,
+		Update: resourceKMSSecretCiphertextUpdate,
 		Delete: resourceKMSSecretCiphertextDelete,
 
 		Timeouts: &schema.ResourceTimeout{
@@ -51,11 +52,12 @@ func ResourceKMSSecretCiphertext() *schema.Resource {
 Format: ''projects/{{project}}/locations/{{location}}/keyRings/{{keyRing}}/cryptoKeys/{{cryptoKey}}''`,
 			},
 			"plaintext": {
-				Type:        schema.TypeString,
-				Required:    true,
-				ForceNew:    true,
-				Description: `The plaintext to be encrypted.`,
-				Sensitive:   true,
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				DiffSuppressFunc: tpgresource.SecretStateDiffSuppress,
+				Description:      `The plaintext to be encrypted.`,
+				Sensitive:        true,
 			},
 			"additional_authenticated_data": {
 				Type:        schema.TypeString,
@@ -69,6 +71,7 @@ Format: ''projects/{{project}}/locations/{{location}}/keyRings/{{keyRing}}/crypt
 				Computed:    true,
 				Description: `Contains the result of encrypting the provided plaintext, encoded in base64.`,
 			},
+			"secret_state_mode": tpgresource.SecretStateModeSchema(),
 		},
 		UseJSONNumber: true,
 	}
@@ -198,9 +201,20 @@ func resourceKMSSecretCiphertextRead(d *schema.ResourceData, meta interface{}) e
 		return nil
 	}
 
+	// The plaintext can't be read back, so the value already in state is kept,
+	// hashed if needed.
+	if err := tpgresource.SetSecretState(d, config, "plaintext", d.Get("plaintext").(string)); err != nil {
+		return fmt.Errorf("Error reading SecretCiphertext: %s", err)
+	}
+
 	return nil
 }
 
+// Only secret_state_mode can be updated, which is applied on read.
+func resourceKMSSecretCiphertextUpdate(d *schema.ResourceData, meta interface{}) error {
+	return resourceKMSSecretCiphertextRead(d, meta)
+}
+
 func resourceKMSSecretCiphertextDelete(d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[WARNING] KMS SecretCiphertext resources"+
 		" cannot be deleted from Google Cloud. The resource %s will be removed from Terraform"+
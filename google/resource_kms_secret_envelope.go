@@ -0,0 +1,152 @@
+package google
+
+import (
+	"encoding/base64"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/hashicorp/terraform-provider-google/google/tpgresource"
+	transport_tpg "github.com/hashicorp/terraform-provider-google/google/transport"
+)
+
+func ResourceKMSSecretEnvelope() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceKMSSecretEnvelopeCreate,
+		Read:   resourceKMSSecretEnvelopeRead,
+		Update: resourceKMSSecretEnvelopeUpdate,
+		Delete: resourceKMSSecretEnvelopeDelete,
+
+		Schema: map[string]*schema.Schema{
+			"crypto_key": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{"crypto_key", "crypto_key_version"},
+				Description: `The symmetric CryptoKey that will be used to wrap the data encryption key.
+Format: 'projects/{{project}}/locations/{{location}}/keyRings/{{keyRing}}/cryptoKeys/{{cryptoKey}}'`,
+			},
+			"crypto_key_version": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{"crypto_key", "crypto_key_version"},
+				Description: `The asymmetric decryption CryptoKeyVersion whose public key will be used to wrap the data
+encryption key. Format: 'projects/{{project}}/locations/{{location}}/keyRings/{{keyRing}}/cryptoKeys/{{cryptoKey}}/cryptoKeyVersions/{{version}}'`,
+			},
+			"plaintext": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ForceNew:         true,
+				Sensitive:        true,
+				ExactlyOneOf:     []string{"plaintext", "plaintext_base64"},
+				DiffSuppressFunc: tpgresource.SecretStateDiffSuppress,
+				Description:      `The plaintext to be encrypted.`,
+			},
+			"plaintext_base64": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ForceNew:         true,
+				Sensitive:        true,
+				ExactlyOneOf:     []string{"plaintext", "plaintext_base64"},
+				DiffSuppressFunc: tpgresource.SecretStateDiffSuppress,
+				Description:      `The base64-encoded plaintext to be encrypted, for binary data.`,
+			},
+			"additional_authenticated_data": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Sensitive:   true,
+				Description: `The additional authenticated data used for integrity checks during encryption and decryption.`,
+			},
+			"envelope": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The envelope containing the encrypted plaintext and the wrapped data encryption key, encoded in base64.`,
+			},
+			"secret_state_mode": tpgresource.SecretStateModeSchema(),
+		},
+		UseJSONNumber: true,
+	}
+}
+
+func resourceKMSSecretEnvelopeCreate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	plaintext := []byte(d.Get("plaintext").(string))
+	if v, ok := d.GetOk("plaintext_base64"); ok {
+		plaintext, err = base64.StdEncoding.DecodeString(v.(string))
+		if err != nil {
+			return fmt.Errorf("Error decoding plaintext_base64: %s", err)
+		}
+	}
+	aad := []byte(d.Get("additional_authenticated_data").(string))
+
+	var wrap kmsEnvelopeWrapFunc
+	if v, ok := d.GetOk("crypto_key"); ok {
+		cryptoKeyId, err := ParseKmsCryptoKeyId(v.(string), config)
+		if err != nil {
+			return err
+		}
+		wrap = kmsSymmetricEnvelopeWrapper(config, userAgent, cryptoKeyId, aad)
+	} else {
+		cryptoKeyVersionId, err := parseKmsCryptoKeyVersionId(d.Get("crypto_key_version").(string), config)
+		if err != nil {
+			return err
+		}
+		wrap = kmsAsymmetricEnvelopeWrapper(config, userAgent, cryptoKeyVersionId)
+	}
+
+	envelope, err := sealKmsEnvelope(plaintext, aad, wrap)
+	if err != nil {
+		return fmt.Errorf("Error creating SecretEnvelope: %s", err)
+	}
+	parsed, err := parseKmsEnvelope(envelope)
+	if err != nil {
+		return err
+	}
+
+	if err := d.Set("envelope", envelope); err != nil {
+		return fmt.Errorf("Error setting envelope: %s", err)
+	}
+	d.SetId(kmsEnvelopeId(parsed.Key, envelope))
+
+	log.Printf("[DEBUG] Finished creating SecretEnvelope %q", d.Id())
+
+	return resourceKMSSecretEnvelopeRead(d, meta)
+}
+
+// The envelope is only ever written on create, so reading applies the
+// secret state mode to the plaintext and nothing else.
+func resourceKMSSecretEnvelopeRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+
+	for _, field := range []string{"plaintext", "plaintext_base64"} {
+		v := d.Get(field).(string)
+		if v == "" {
+			continue
+		}
+		if err := tpgresource.SetSecretState(d, config, field, v); err != nil {
+			return fmt.Errorf("Error setting %s: %s", field, err)
+		}
+	}
+
+	return nil
+}
+
+// Only secret_state_mode can be updated, which is applied on read.
+func resourceKMSSecretEnvelopeUpdate(d *schema.ResourceData, meta interface{}) error {
+	return resourceKMSSecretEnvelopeRead(d, meta)
+}
+
+func resourceKMSSecretEnvelopeDelete(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] Removing SecretEnvelope %q from state, it only exists in Terraform.", d.Id())
+	d.SetId("")
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package google
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-provider-google/google/acctest"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccKmsSecretEnvelope_basic(t *testing.T) {
+	t.Parallel()
+
+	symKey := BootstrapKMSKey(t)
+	asymDecrKey := BootstrapKMSKeyWithPurpose(t, "ASYMMETRIC_DECRYPT")
+
+	plaintext := fmt.Sprintf("secret-%s", RandString(t, 10))
+
+	VcrTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.AccTestPreCheck(t) },
+		ProtoV5ProviderFactories: ProtoV5ProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testGoogleKmsSecretEnvelope_symmetric(symKey.CryptoKey.Name, plaintext),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.google_kms_secret_envelope.acceptance", "plaintext", plaintext),
+					resource.TestCheckResourceAttr("data.google_kms_secret_envelope.acceptance", "crypto_key", symKey.CryptoKey.Name),
+				),
+			},
+			{
+				Config: testGoogleKmsSecretEnvelope_asymmetric(asymDecrKey.CryptoKey.Name, plaintext),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.google_kms_secret_envelope.acceptance", "plaintext", plaintext),
+				),
+			},
+		},
+	})
+}
+
+func testGoogleKmsSecretEnvelope_symmetric(cryptoKey, plaintext string) string {
+	return fmt.Sprintf(`
+resource "google_kms_secret_envelope" "acceptance" {
+  crypto_key                    = "%s"
+  plaintext                     = "%s"
+  additional_authenticated_data = "plainaad"
+}
+
+data "google_kms_secret_envelope" "acceptance" {
+  envelope                      = google_kms_secret_envelope.acceptance.envelope
+  additional_authenticated_data = "plainaad"
+}
+`, cryptoKey, plaintext)
+}
+
+func testGoogleKmsSecretEnvelope_asymmetric(cryptoKey, plaintext string) string {
+	return fmt.Sprintf(`
+data "google_kms_crypto_key_version" "version" {
+  crypto_key = "%s"
+}
+
+resource "google_kms_secret_envelope" "acceptance" {
+  crypto_key_version = data.google_kms_crypto_key_version.version.name
+  plaintext_base64   = base64encode("%s")
+}
+
+data "google_kms_secret_envelope" "acceptance" {
+  envelope = google_kms_secret_envelope.acceptance.envelope
+}
+`, cryptoKey, plaintext)
+}
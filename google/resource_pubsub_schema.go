@@ -23,6 +23,7 @@ import (
 	"reflect"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 
 	"github.com/hashicorp/terraform-provider-google/google/tpgresource"
@@ -34,6 +35,7 @@ func ResourcePubsubSchema() *schema.Resource {
 	return &schema.Resource{
 		Create: resourcePubsubSchemaCreate,
 		Read:   resourcePubsubSchemaRead,
+		Update: resourcePubsubSchemaUpdate,
 		Delete: resourcePubsubSchemaDelete,
 
 		Importer: &schema.ResourceImporter{
@@ -42,9 +44,15 @@ func ResourcePubsubSchema() *schema.Resource {
 
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(20 * time.Minute),
+			Update: schema.DefaultTimeout(20 * time.Minute),
 			Delete: schema.DefaultTimeout(20 * time.Minute),
 		},
 
+		CustomizeDiff: customdiff.All(
+			customdiff.ComputedIf("revision_id", pubsubSchemaDefinitionChanged),
+			customdiff.ComputedIf("revision_create_time", pubsubSchemaDefinitionChanged),
+		),
+
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:             schema.TypeString,
@@ -56,10 +64,12 @@ func ResourcePubsubSchema() *schema.Resource {
 			"definition": {
 				Type:     schema.TypeString,
 				Optional: true,
-				ForceNew: true,
 				Description: `The definition of the schema.
 This should contain a string representing the full definition of the schema
-that is a valid schema definition of the type specified in type.`,
+that is a valid schema definition of the type specified in type.
+Changing the definition commits a new revision of the schema. If the new
+definition is identical to that of an existing revision, the schema is
+rolled back to that revision instead.`,
 			},
 			"type": {
 				Type:         schema.TypeString,
@@ -69,6 +79,41 @@ that is a valid schema definition of the type specified in type.`,
 				Description:  `The type of the schema definition Default value: "TYPE_UNSPECIFIED" Possible values: ["TYPE_UNSPECIFIED", "PROTOCOL_BUFFER", "AVRO"]`,
 				Default:      "TYPE_UNSPECIFIED",
 			},
+			"sample_messages": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Description: `Messages that must be valid against the schema. Before a definition is
+created or committed, each sample message is validated against it, and
+against every existing revision of the schema, and the change is refused if
+any of them fails validation.`,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"data": {
+							Type:     schema.TypeString,
+							Required: true,
+							Description: `The message. With JSON encoding this is the JSON text of the message,
+with BINARY encoding the base64-encoded message.`,
+						},
+						"encoding": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: verify.ValidateEnum([]string{"JSON", "BINARY", ""}),
+							Description:  `The encoding of the message. Default value: "JSON" Possible values: ["JSON", "BINARY"]`,
+							Default:      "JSON",
+						},
+					},
+				},
+			},
+			"revision_create_time": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The timestamp that the current revision was created.`,
+			},
+			"revision_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The ID of the current revision of the schema.`,
+			},
 			"project": {
 				Type:     schema.TypeString,
 				Optional: true,
@@ -126,6 +171,10 @@ func resourcePubsubSchemaCreate(d *schema.ResourceData, meta interface{}) error
 		billingProject = bp
 	}
 
+	if err := validatePubsubSchemaDefinition(d, config, billingProject, userAgent, false); err != nil {
+		return err
+	}
+
 	res, err := transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
 		Config:    config,
 		Method:    "POST",
@@ -199,7 +248,7 @@ func resourcePubsubSchemaRead(d *schema.ResourceData, meta interface{}) error {
 		return err
 	}
 
-	url, err := tpgresource.ReplaceVars(d, config, "{{PubsubBasePath}}projects/{{project}}/schemas/{{name}}")
+	url, err := tpgresource.ReplaceVars(d, config, "{{PubsubBasePath}}projects/{{project}}/schemas/{{name}}?view=FULL")
 	if err != nil {
 		return err
 	}
@@ -238,10 +287,52 @@ func resourcePubsubSchemaRead(d *schema.ResourceData, meta interface{}) error {
 	if err := d.Set("name", flattenPubsubSchemaName(res["name"], d, config)); err != nil {
 		return fmt.Errorf("Error reading Schema: %s", err)
 	}
+	if err := d.Set("revision_id", flattenPubsubSchemaRevisionId(res["revisionId"], d, config)); err != nil {
+		return fmt.Errorf("Error reading Schema: %s", err)
+	}
+	if err := d.Set("revision_create_time", flattenPubsubSchemaRevisionCreateTime(res["revisionCreateTime"], d, config)); err != nil {
+		return fmt.Errorf("Error reading Schema: %s", err)
+	}
 
 	return nil
 }
 
+func resourcePubsubSchemaUpdate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	billingProject := ""
+
+	project, err := tpgresource.GetProject(d, config)
+	if err != nil {
+		return fmt.Errorf("Error fetching project for Schema: %s", err)
+	}
+	billingProject = project
+
+	// err == nil indicates that the billing_project value was found
+	if bp, err := tpgresource.GetBillingProject(d, config); err == nil {
+		billingProject = bp
+	}
+
+	if d.HasChange("definition") {
+		if err := validatePubsubSchemaDefinition(d, config, billingProject, userAgent, true); err != nil {
+			return err
+		}
+
+		log.Printf("[DEBUG] Committing new revision of Schema %q", d.Id())
+		res, err := commitPubsubSchemaRevision(d, config, billingProject, userAgent)
+		if err != nil {
+			return fmt.Errorf("Error updating Schema %q: %s", d.Id(), err)
+		}
+		log.Printf("[DEBUG] Finished updating Schema %q: %#v", d.Id(), res)
+	}
+
+	return resourcePubsubSchemaRead(d, meta)
+}
+
 func resourcePubsubSchemaDelete(d *schema.ResourceData, meta interface{}) error {
 	config := meta.(*transport_tpg.Config)
 	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
@@ -323,6 +414,14 @@ func flattenPubsubSchemaName(v interface{}, d *schema.ResourceData, config *tran
 	return tpgresource.NameFromSelfLinkStateFunc(v)
 }
 
+func flattenPubsubSchemaRevisionId(v interface{}, d *schema.ResourceData, config *transport_tpg.Config) interface{} {
+	return v
+}
+
+func flattenPubsubSchemaRevisionCreateTime(v interface{}, d *schema.ResourceData, config *transport_tpg.Config) interface{} {
+	return v
+}
+
 func expandPubsubSchemaType(v interface{}, d tpgresource.TerraformResourceData, config *transport_tpg.Config) (interface{}, error) {
 	return v, nil
 }
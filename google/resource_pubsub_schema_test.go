@@ -0,0 +1,145 @@
+package google
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-provider-google/google/acctest"
+)
+
+func TestPubsubSchemaRevisionWithDefinition(t *testing.T) {
+	t.Parallel()
+
+	revisions := []interface{}{
+		map[string]interface{}{
+			"revisionId": "c",
+			"type":       "AVRO",
+			"definition": "{\"type\":\"record\",\"name\":\"A\",\"fields\":[]}\n",
+		},
+		map[string]interface{}{
+			"revisionId": "b",
+			"type":       "PROTOCOL_BUFFER",
+			"definition": "message A {}",
+		},
+		map[string]interface{}{
+			"revisionId": "a",
+			"type":       "AVRO",
+			"definition": "{\"type\":\"record\",\"name\":\"A\",\"fields\":[]}",
+		},
+	}
+
+	cases := map[string]struct {
+		schemaType, definition, expected string
+	}{
+		"newest match wins": {
+			schemaType: "AVRO",
+			definition: "{\"type\":\"record\",\"name\":\"A\",\"fields\":[]}",
+			expected:   "c",
+		},
+		"type must match": {
+			schemaType: "AVRO",
+			definition: "message A {}",
+			expected:   "",
+		},
+		"match ignoring surrounding whitespace": {
+			schemaType: "PROTOCOL_BUFFER",
+			definition: "\n  message A {}\n",
+			expected:   "b",
+		},
+		"no match": {
+			schemaType: "PROTOCOL_BUFFER",
+			definition: "message B {}",
+			expected:   "",
+		},
+	}
+
+	for tn, tc := range cases {
+		if got := pubsubSchemaRevisionWithDefinition(revisions, tc.schemaType, tc.definition); got != tc.expected {
+			t.Errorf("%s: expected revision %q, got %q", tn, tc.expected, got)
+		}
+	}
+}
+
+func TestAccPubsubSchema_revisions(t *testing.T) {
+	t.Parallel()
+
+	schema := fmt.Sprintf("tf-test-schema-%s", RandString(t, 10))
+	topic := fmt.Sprintf("tf-test-topic-%s", RandString(t, 10))
+
+	VcrTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.AccTestPreCheck(t) },
+		ProtoV5ProviderFactories: ProtoV5ProviderFactories(t),
+		CheckDestroy:             testAccCheckPubsubSchemaDestroyProducer(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPubsubSchema_revisions(schema, topic, ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("google_pubsub_schema.foo", "revision_id"),
+					resource.TestCheckResourceAttr("data.google_pubsub_schema_revisions.foo", "revisions.#", "1"),
+				),
+			},
+			{
+				ResourceName:            "google_pubsub_schema.foo",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"sample_messages"},
+			},
+			{
+				Config: testAccPubsubSchema_revisions(schema, topic, `
+      {
+        name    = "IntField"
+        type    = "int"
+        default = 0
+      },`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.google_pubsub_schema_revisions.foo", "revisions.#", "2"),
+				),
+			},
+			{
+				ResourceName:      "google_pubsub_topic.foo",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccPubsubSchema_revisions(schema, topic, extraFields string) string {
+	return fmt.Sprintf(`
+resource "google_pubsub_schema" "foo" {
+  name       = "%s"
+  type       = "AVRO"
+  definition = jsonencode({
+    type = "record"
+    name = "Avro"
+    fields = [
+      {
+        name = "StringField"
+        type = "string"
+      },%s
+    ]
+  })
+
+  sample_messages {
+    data = jsonencode({ StringField = "foo" })
+  }
+}
+
+resource "google_pubsub_topic" "foo" {
+  name = "%s"
+
+  schema_settings {
+    schema            = google_pubsub_schema.foo.id
+    encoding          = "JSON"
+    first_revision_id = google_pubsub_schema.foo.revision_id
+  }
+}
+
+data "google_pubsub_schema_revisions" "foo" {
+  schema = google_pubsub_schema.foo.id
+
+  depends_on = [google_pubsub_topic.foo]
+}
+`, schema, extraFields, topic)
+}
@@ -64,11 +64,12 @@ func ResourceSecretManagerSecretVersion() *schema.Resource {
 
 		Schema: map[string]*schema.Schema{
 			"secret_data": {
-				Type:        schema.TypeString,
-				Required:    true,
-				ForceNew:    true,
-				Description: `The secret data. Must be no larger than 64KiB.`,
-				Sensitive:   true,
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				DiffSuppressFunc: tpgresource.SecretStateDiffSuppress,
+				Description:      `The secret data. Must be no larger than 64KiB.`,
+				Sensitive:        true,
 			},
 
 			"secret": {
@@ -105,6 +106,7 @@ func ResourceSecretManagerSecretVersion() *schema.Resource {
 				Computed:    true,
 				Description: `The version of the Secret.`,
 			},
+			"secret_state_mode": tpgresource.SecretStateModeSchema(),
 		},
 		UseJSONNumber: true,
 	}
@@ -253,6 +255,12 @@ func resourceSecretManagerSecretVersionRead(d *schema.ResourceData, meta interfa
 		casted := flattenedProp.([]interface{})[0]
 		if casted != nil {
 			for k, v := range casted.(map[string]interface{}) {
+				if k == "secret_data" {
+					if err := tpgresource.SetSecretState(d, config, k, v.(string)); err != nil {
+						return fmt.Errorf("Error setting %s: %s", k, err)
+					}
+					continue
+				}
 				if err := d.Set(k, v); err != nil {
 					return fmt.Errorf("Error setting %s: %s", k, err)
 				}
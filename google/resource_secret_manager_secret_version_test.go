@@ -1,6 +1,7 @@
 package google
 
 import (
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
@@ -50,6 +51,41 @@ func TestAccSecretManagerSecretVersion_update(t *testing.T) {
 	})
 }
 
+func TestAccSecretManagerSecretVersion_hashedState(t *testing.T) {
+	t.Parallel()
+
+	context := map[string]interface{}{
+		"random_suffix": RandString(t, 10),
+		"secret_data":   "my-tf-test-secret",
+	}
+	updated := map[string]interface{}{
+		"random_suffix": context["random_suffix"],
+		"secret_data":   "my-updated-tf-test-secret",
+	}
+
+	VcrTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.AccTestPreCheck(t) },
+		ProtoV5ProviderFactories: ProtoV5ProviderFactories(t),
+		CheckDestroy:             testAccCheckSecretManagerSecretVersionDestroyProducer(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSecretManagerSecretVersion_hashedState(context),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr("google_secret_manager_secret_version.secret-version-basic", "secret_data", regexp.MustCompile(`^\$sha256\$`)),
+					resource.TestCheckResourceAttr("google_secret_manager_secret_version.secret-version-basic", "version", "1"),
+				),
+			},
+			{
+				Config: testAccSecretManagerSecretVersion_hashedState(updated),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr("google_secret_manager_secret_version.secret-version-basic", "secret_data", regexp.MustCompile(`^\$sha256\$`)),
+					resource.TestCheckResourceAttr("google_secret_manager_secret_version.secret-version-basic", "version", "2"),
+				),
+			},
+		},
+	})
+}
+
 func testAccSecretManagerSecretVersion_basic(context map[string]interface{}) string {
 	return Nprintf(`
 resource "google_secret_manager_secret" "secret-basic" {
@@ -95,3 +131,22 @@ resource "google_secret_manager_secret_version" "secret-version-basic" {
 }
 `, context)
 }
+
+func testAccSecretManagerSecretVersion_hashedState(context map[string]interface{}) string {
+	return Nprintf(`
+resource "google_secret_manager_secret" "secret-basic" {
+  secret_id = "tf-test-secret-version-%{random_suffix}"
+
+  replication {
+    automatic = true
+  }
+}
+
+resource "google_secret_manager_secret_version" "secret-version-basic" {
+  secret = google_secret_manager_secret.secret-basic.name
+
+  secret_data       = "%{secret_data}"
+  secret_state_mode = "hash"
+}
+`, context)
+}
@@ -0,0 +1,309 @@
+package google
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/hashicorp/terraform-provider-google/google/tpgresource"
+	transport_tpg "github.com/hashicorp/terraform-provider-google/google/transport"
+)
+
+// ResourceSpannerDatabaseSchema manages the schema of a Spanner database
+// declaratively. Unlike the append-only ddl list of google_spanner_database,
+// ddl holds the full desired schema, and the statements needed to get there
+// from the database's current schema are computed at plan time.
+func ResourceSpannerDatabaseSchema() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceSpannerDatabaseSchemaCreate,
+		Read:   resourceSpannerDatabaseSchemaRead,
+		Update: resourceSpannerDatabaseSchemaUpdate,
+		Delete: resourceSpannerDatabaseSchemaDelete,
+
+		Importer: &schema.ResourceImporter{
+			State: resourceSpannerDatabaseSchemaImport,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(20 * time.Minute),
+			Update: schema.DefaultTimeout(20 * time.Minute),
+		},
+
+		CustomizeDiff: resourceSpannerDatabaseSchemaCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			"instance": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				DiffSuppressFunc: tpgresource.CompareSelfLinkOrResourceName,
+				Description:      `The instance of the database.`,
+			},
+			"database": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: `The name of the database whose schema is managed.`,
+			},
+			"ddl": {
+				Type:        schema.TypeList,
+				Required:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: `The complete desired schema of the database, as GoogleSQL DDL statements. Tables, indexes, views and change streams that aren't listed are dropped.`,
+			},
+			"allow_drop": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: `Whether statements that drop tables or columns, and so delete their data, may be run. If false, planning such a change fails.`,
+			},
+			"project": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+			"current_ddl": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: `The schema of the database as returned by GetDatabaseDdl.`,
+			},
+			"migration_ddl": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: `The statements that take the database from current_ddl to ddl. While planning, these are the statements that will run; afterwards, the ones the last apply ran.`,
+			},
+		},
+		UseJSONNumber: true,
+	}
+}
+
+func resourceSpannerDatabaseSchemaCustomizeDiff(_ context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+	// The current schema isn't known until the resource has been read.
+	if diff.Id() == "" {
+		return nil
+	}
+	if !diff.NewValueKnown("ddl") {
+		if err := diff.SetNewComputed("migration_ddl"); err != nil {
+			return err
+		}
+		return diff.SetNewComputed("current_ddl")
+	}
+
+	current := tpgresource.ConvertStringArr(diff.Get("current_ddl").([]interface{}))
+	desired := tpgresource.ConvertStringArr(diff.Get("ddl").([]interface{}))
+	statements, err := planSpannerDatabaseSchema(current, desired, diff.Get("allow_drop").(bool))
+	if err != nil {
+		return err
+	}
+	if len(statements) == 0 {
+		// A ddl change that only reformats the schema runs nothing, which
+		// apply records as an empty migration_ddl.
+		if diff.HasChange("ddl") {
+			return diff.SetNew("migration_ddl", []string{})
+		}
+		return nil
+	}
+
+	if err := diff.SetNew("migration_ddl", statements); err != nil {
+		return err
+	}
+	return diff.SetNewComputed("current_ddl")
+}
+
+// planSpannerDatabaseSchema returns the statements that migrate the current
+// schema to the desired one, refusing to delete data unless allowDrop is set.
+func planSpannerDatabaseSchema(current, desired []string, allowDrop bool) ([]string, error) {
+	statements, err := diffSpannerSchema(current, desired)
+	if err != nil {
+		return nil, err
+	}
+	if allowDrop {
+		return statements, nil
+	}
+
+	var drops []string
+	for _, stmt := range statements {
+		upper := strings.ToUpper(stmt)
+		if strings.HasPrefix(upper, "DROP TABLE ") || (strings.HasPrefix(upper, "ALTER TABLE ") && strings.Contains(upper, " DROP COLUMN ")) {
+			drops = append(drops, stmt)
+		}
+	}
+	if len(drops) > 0 {
+		return nil, fmt.Errorf("the schema change would delete data by running %q. Set allow_drop to true to allow this", drops)
+	}
+	return statements, nil
+}
+
+func resourceSpannerDatabaseSchemaCreate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+
+	id, err := tpgresource.ReplaceVars(d, config, "projects/{{project}}/instances/{{instance}}/databases/{{database}}")
+	if err != nil {
+		return fmt.Errorf("Error constructing id: %s", err)
+	}
+	d.SetId(id)
+
+	if err := resourceSpannerDatabaseSchemaApply(d, config, d.Timeout(schema.TimeoutCreate)); err != nil {
+		d.SetId("")
+		return err
+	}
+
+	return resourceSpannerDatabaseSchemaRead(d, meta)
+}
+
+func resourceSpannerDatabaseSchemaRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	project, err := tpgresource.GetProject(d, config)
+	if err != nil {
+		return err
+	}
+
+	statements, err := getSpannerDatabaseDdl(d, config, project, userAgent)
+	if err != nil {
+		return transport_tpg.HandleNotFoundError(err, d, fmt.Sprintf("SpannerDatabaseSchema %q", d.Id()))
+	}
+
+	if err := d.Set("current_ddl", statements); err != nil {
+		return fmt.Errorf("Error setting current_ddl: %s", err)
+	}
+	if err := d.Set("project", project); err != nil {
+		return fmt.Errorf("Error setting project: %s", err)
+	}
+
+	return nil
+}
+
+func resourceSpannerDatabaseSchemaUpdate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+
+	if err := resourceSpannerDatabaseSchemaApply(d, config, d.Timeout(schema.TimeoutUpdate)); err != nil {
+		return err
+	}
+
+	return resourceSpannerDatabaseSchemaRead(d, meta)
+}
+
+func resourceSpannerDatabaseSchemaDelete(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[WARN] Spanner database schema %s removed from state, but the schema of the database was left unchanged.", d.Id())
+	d.SetId("")
+	return nil
+}
+
+func resourceSpannerDatabaseSchemaImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	config := meta.(*transport_tpg.Config)
+	if err := tpgresource.ParseImportId([]string{
+		"projects/(?P<project>[^/]+)/instances/(?P<instance>[^/]+)/databases/(?P<database>[^/]+)",
+		"(?P<project>[^/]+)/(?P<instance>[^/]+)/(?P<database>[^/]+)",
+		"(?P<instance>[^/]+)/(?P<database>[^/]+)",
+	}, d, config); err != nil {
+		return nil, err
+	}
+
+	id, err := tpgresource.ReplaceVars(d, config, "projects/{{project}}/instances/{{instance}}/databases/{{database}}")
+	if err != nil {
+		return nil, fmt.Errorf("Error constructing id: %s", err)
+	}
+	d.SetId(id)
+
+	if err := d.Set("allow_drop", false); err != nil {
+		return nil, fmt.Errorf("Error setting allow_drop: %s", err)
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceSpannerDatabaseSchemaApply reads the database's schema, and runs
+// the statements that migrate it to ddl as a single schema update.
+func resourceSpannerDatabaseSchemaApply(d *schema.ResourceData, config *transport_tpg.Config, timeout time.Duration) error {
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	project, err := tpgresource.GetProject(d, config)
+	if err != nil {
+		return err
+	}
+
+	current, err := getSpannerDatabaseDdl(d, config, project, userAgent)
+	if err != nil {
+		return fmt.Errorf("Error reading schema of database %s: %s", d.Id(), err)
+	}
+	desired := tpgresource.ConvertStringArr(d.Get("ddl").([]interface{}))
+	statements, err := planSpannerDatabaseSchema(current, desired, d.Get("allow_drop").(bool))
+	if err != nil {
+		return err
+	}
+
+	if len(statements) > 0 {
+		url, err := tpgresource.ReplaceVars(d, config, "{{SpannerBasePath}}projects/{{project}}/instances/{{instance}}/databases/{{database}}/ddl")
+		if err != nil {
+			return err
+		}
+
+		log.Printf("[DEBUG] Updating schema of database %s: %#v", d.Id(), statements)
+		res, err := transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
+			Config:    config,
+			Method:    "PATCH",
+			Project:   project,
+			RawURL:    url,
+			UserAgent: userAgent,
+			Body: map[string]interface{}{
+				"statements": statements,
+			},
+			Timeout: timeout,
+		})
+		if err != nil {
+			return fmt.Errorf("Error updating schema of database %s: %s", d.Id(), err)
+		}
+
+		if err := SpannerOperationWaitTime(config, res, project, "Updating database schema", userAgent, timeout); err != nil {
+			return err
+		}
+	}
+
+	// Like the plan, keep the statements of the last apply that ran any when
+	// neither ddl nor the schema changed.
+	if len(statements) > 0 || d.HasChange("ddl") {
+		if err := d.Set("migration_ddl", statements); err != nil {
+			return fmt.Errorf("Error setting migration_ddl: %s", err)
+		}
+	}
+	return nil
+}
+
+func getSpannerDatabaseDdl(d *schema.ResourceData, config *transport_tpg.Config, project, userAgent string) ([]string, error) {
+	url, err := tpgresource.ReplaceVars(d, config, "{{SpannerBasePath}}projects/{{project}}/instances/{{instance}}/databases/{{database}}/ddl")
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := transport_tpg.SendRequest(transport_tpg.SendRequestOptions{
+		Config:    config,
+		Method:    "GET",
+		Project:   project,
+		RawURL:    url,
+		UserAgent: userAgent,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var statements []string
+	if v, ok := res["statements"].([]interface{}); ok {
+		statements = tpgresource.ConvertStringArr(v)
+	}
+	return statements, nil
+}
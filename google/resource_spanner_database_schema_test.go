@@ -0,0 +1,82 @@
+package google
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-provider-google/google/acctest"
+)
+
+func TestAccSpannerDatabaseSchema_update(t *testing.T) {
+	t.Parallel()
+
+	rnd := RandString(t, 10)
+	instanceName := fmt.Sprintf("tf-test-%s", rnd)
+	databaseName := fmt.Sprintf("tfgen_%s", rnd)
+
+	VcrTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.AccTestPreCheck(t) },
+		ProtoV5ProviderFactories: ProtoV5ProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSpannerDatabaseSchema(instanceName, databaseName, `
+    "CREATE TABLE Singers (SingerId INT64 NOT NULL, Name STRING(1024)) PRIMARY KEY (SingerId)",
+`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("google_spanner_database_schema.schema", "current_ddl.#", "1"),
+				),
+			},
+			{
+				Config: testAccSpannerDatabaseSchema(instanceName, databaseName, `
+    "CREATE TABLE Singers (SingerId INT64 NOT NULL, Name STRING(MAX), BirthDate DATE) PRIMARY KEY (SingerId)",
+    "CREATE INDEX SingersByName ON Singers (Name)",
+`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("google_spanner_database_schema.schema", "current_ddl.#", "2"),
+					resource.TestCheckResourceAttr("google_spanner_database_schema.schema", "migration_ddl.#", "3"),
+				),
+			},
+			{
+				// Reformatting the schema runs no statements.
+				Config: testAccSpannerDatabaseSchema(instanceName, databaseName, `
+    "CREATE TABLE Singers (\n  SingerId INT64 NOT NULL,\n  Name STRING(MAX),\n  BirthDate DATE\n) PRIMARY KEY (SingerId)",
+    "CREATE INDEX SingersByName ON Singers (Name)",
+`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("google_spanner_database_schema.schema", "current_ddl.#", "2"),
+					resource.TestCheckResourceAttr("google_spanner_database_schema.schema", "migration_ddl.#", "0"),
+				),
+			},
+			{
+				ResourceName:            "google_spanner_database_schema.schema",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"ddl", "migration_ddl"},
+			},
+		},
+	})
+}
+
+func testAccSpannerDatabaseSchema(instanceName, databaseName, ddl string) string {
+	return fmt.Sprintf(`
+resource "google_spanner_instance" "basic" {
+  name         = "%s"
+  config       = "regional-us-central1"
+  display_name = "%s-display"
+  num_nodes    = 1
+}
+
+resource "google_spanner_database" "basic" {
+  instance            = google_spanner_instance.basic.name
+  name                = "%s"
+  deletion_protection = false
+}
+
+resource "google_spanner_database_schema" "schema" {
+  instance = google_spanner_instance.basic.name
+  database = google_spanner_database.basic.name
+  ddl = [%s  ]
+}
+`, instanceName, instanceName, databaseName, ddl)
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strconv"
 	"strings"
 	"time"
 
@@ -119,6 +120,7 @@ func ResourceSqlDatabaseInstance() *schema.Resource {
 			customdiff.IfValueChange("instance_type", isReplicaPromoteRequested, checkPromoteConfigurationsAndUpdateDiff),
 			privateNetworkCustomizeDiff,
 			pitrSupportDbCustomizeDiff,
+			databaseVersionUpgradeCustomizeDiff,
 		),
 
 		Schema: map[string]*schema.Schema{
@@ -841,6 +843,28 @@ is set to true. Defaults to ZONAL.`,
 				Computed:    true,
 				Description: `The URI of the created resource.`,
 			},
+			"major_version_upgrade": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: `Configuration for in-place major version upgrades performed when database_version changes.`,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"backup_before_upgrade": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     true,
+							Description: `Whether to take an on-demand backup of the instance before upgrading it. Defaults to true.`,
+						},
+						"rollback_on_failure": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: `Whether to restore the instance from the backup taken before the upgrade if the upgrade fails after it started changing the instance, while the instance is still on the previous version. An instance left on the new version is not restored, and database_version is set to the version it runs. Requires backup_before_upgrade.`,
+						},
+					},
+				},
+			},
 			"restore_backup_context": {
 				Type:     schema.TypeList,
 				Optional: true,
@@ -1603,15 +1627,7 @@ func resourceSqlDatabaseInstanceUpdate(d *schema.ResourceData, meta interface{})
 	// Check if the database version is being updated, because patching database version is an atomic operation and can not be
 	// performed with other fields, we first patch database version before updating the rest of the fields.
 	if d.HasChange("database_version") {
-		instance = &sqladmin.DatabaseInstance{DatabaseVersion: databaseVersion}
-		err = transport_tpg.RetryTimeDuration(func() (rerr error) {
-			op, rerr = config.NewSqlAdminClient(userAgent).Instances.Patch(project, d.Get("name").(string), instance).Do()
-			return rerr
-		}, d.Timeout(schema.TimeoutUpdate), transport_tpg.IsSqlOperationInProgressError)
-		if err != nil {
-			return fmt.Errorf("Error, failed to patch instance settings for %s: %s", instance.Name, err)
-		}
-		err = SqlAdminOperationWaitTime(config, op, project, "Patch Instance", userAgent, d.Timeout(schema.TimeoutUpdate))
+		err = sqlDatabaseInstanceUpgradeDatabaseVersion(d, config, userAgent, project)
 		if err != nil {
 			return err
 		}
@@ -2227,6 +2243,270 @@ func sqlDatabaseInstanceRestoreFromBackup(d *schema.ResourceData, config *transp
 	return nil
 }
 
+// sqlDatabaseInstanceUpgradeDatabaseVersion patches the database version of the
+// instance. Major version upgrades are preceded by an on-demand backup, unless
+// disabled in major_version_upgrade, which is used to restore the instance if the
+// upgrade fails after it started changing the instance, the instance is still
+// on the previous version, and rollback_on_failure is set.
+func sqlDatabaseInstanceUpgradeDatabaseVersion(d *schema.ResourceData, config *transport_tpg.Config, userAgent, project string) error {
+	o, n := d.GetChange("database_version")
+	oldVersion, newVersion := o.(string), n.(string)
+	name := d.Get("name").(string)
+
+	backupBeforeUpgrade := true
+	rollbackOnFailure := false
+	if v, ok := d.GetOk("major_version_upgrade"); ok && len(v.([]interface{})) > 0 && v.([]interface{})[0] != nil {
+		upgrade := v.([]interface{})[0].(map[string]interface{})
+		backupBeforeUpgrade = upgrade["backup_before_upgrade"].(bool)
+		rollbackOnFailure = upgrade["rollback_on_failure"].(bool)
+	}
+
+	var backupId int64
+	if isMajorDatabaseVersionUpgrade(oldVersion, newVersion) && backupBeforeUpgrade {
+		if sqlDatabaseIsMaster(d) {
+			var err error
+			backupId, err = sqlDatabaseInstanceBackupBeforeUpgrade(d, config, userAgent, project, name, newVersion)
+			if err != nil {
+				return err
+			}
+		} else {
+			log.Printf("[DEBUG] Skipping backup of SQL database instance %s before upgrade, replicas can not be backed up", name)
+		}
+	}
+
+	log.Printf("[DEBUG] Upgrading SQL database instance %s from %s to %s", name, oldVersion, newVersion)
+	var op *sqladmin.Operation
+	instance := &sqladmin.DatabaseInstance{DatabaseVersion: newVersion}
+	err := transport_tpg.RetryTimeDuration(func() (rerr error) {
+		op, rerr = config.NewSqlAdminClient(userAgent).Instances.Patch(project, name, instance).Do()
+		return rerr
+	}, d.Timeout(schema.TimeoutUpdate), transport_tpg.IsSqlOperationInProgressError)
+	patched := err == nil
+	if patched {
+		op, err = sqlAdminOperationWaitWithProgress(config, op, project, fmt.Sprintf("Upgrade Instance %s to %s", name, newVersion), userAgent, d.Timeout(schema.TimeoutUpdate))
+	}
+	if err == nil {
+		return nil
+	}
+
+	err = sqlDatabaseVersionUpgradeError(name, oldVersion, newVersion, err)
+	// Record the version the instance is left running, as a failed upgrade
+	// may still have changed it.
+	var current *sqladmin.DatabaseInstance
+	var gerr error
+	version := oldVersion
+	if patched {
+		current, gerr = config.NewSqlAdminClient(userAgent).Instances.Get(project, name).Do()
+		if gerr == nil && current.DatabaseVersion != "" {
+			version = current.DatabaseVersion
+		}
+	}
+	if serr := d.Set("database_version", version); serr != nil {
+		return fmt.Errorf("Error re-setting database_version: %s", serr)
+	}
+	if !rollbackOnFailure || backupId == 0 {
+		return err
+	}
+	// Restoring the backup loses every write made since it was taken, so it's
+	// only done when the upgrade got to change the instance.
+	if !patched {
+		return fmt.Errorf("%s\nThe upgrade was rejected, so the instance was not restored from backup %d.", err, backupId)
+	}
+	if gerr != nil {
+		return fmt.Errorf("%s\nReading the instance to decide whether to restore it from backup %d also failed: %s", err, backupId, gerr)
+	}
+	// Cloud SQL only restores a backup into an instance running the version
+	// it was taken on.
+	if current.DatabaseVersion != oldVersion {
+		return fmt.Errorf("%s\nThe instance is now running %s, so backup %d of %s can not be restored into it. Restore it into a new instance running %s instead.", err, current.DatabaseVersion, backupId, oldVersion, oldVersion)
+	}
+	if !sqlDatabaseUpgradeNeedsRestore(op, current, oldVersion) {
+		return fmt.Errorf("%s\nThe instance is still running %s, so it was not restored from backup %d.", err, oldVersion, backupId)
+	}
+
+	log.Printf("[DEBUG] Restoring SQL database instance %s from backup %d after failed upgrade", name, backupId)
+	restoreContext := []interface{}{
+		map[string]interface{}{
+			"backup_run_id": int(backupId),
+			"instance_id":   name,
+			"project":       project,
+		},
+	}
+	if rerr := sqlDatabaseInstanceRestoreFromBackup(d, config, userAgent, project, name, restoreContext); rerr != nil {
+		return fmt.Errorf("%s\nRestoring the instance from backup %d taken before the upgrade also failed: %s", err, backupId, rerr)
+	}
+	return fmt.Errorf("%s\nThe instance was restored from backup %d taken before the upgrade.", err, backupId)
+}
+
+// sqlDatabaseUpgradeNeedsRestore returns whether a failed upgrade operation
+// left the instance on its previous version, which the backup taken before
+// the upgrade can be restored into, but not runnable. Upgrades failing their
+// pre-checks before the operation started, or rolled back by Cloud SQL, leave
+// the instance running its previous version with all of its data. Instances
+// left on the new version can't be restored from a backup of the previous one.
+func sqlDatabaseUpgradeNeedsRestore(op *sqladmin.Operation, instance *sqladmin.DatabaseInstance, oldVersion string) bool {
+	if op == nil || op.StartTime == "" {
+		return false
+	}
+	return instance.DatabaseVersion == oldVersion && instance.State != "RUNNABLE"
+}
+
+// sqlDatabaseInstanceBackupBeforeUpgrade takes an on-demand backup of the
+// instance and returns its ID.
+func sqlDatabaseInstanceBackupBeforeUpgrade(d *schema.ResourceData, config *transport_tpg.Config, userAgent, project, name, newVersion string) (int64, error) {
+	description := fmt.Sprintf("Backup taken by Terraform before upgrading to %s at %s", newVersion, time.Now().UTC().Format(time.RFC3339))
+	log.Printf("[DEBUG] Taking backup of SQL database instance %s before upgrade", name)
+
+	var op *sqladmin.Operation
+	err := transport_tpg.RetryTimeDuration(func() (rerr error) {
+		op, rerr = config.NewSqlAdminClient(userAgent).BackupRuns.Insert(project, name, &sqladmin.BackupRun{Description: description}).Do()
+		return rerr
+	}, d.Timeout(schema.TimeoutUpdate), transport_tpg.IsSqlOperationInProgressError)
+	if err != nil {
+		return 0, fmt.Errorf("Error, failed to back up instance %s before upgrade: %s", name, err)
+	}
+	op, err = sqlAdminOperationWaitWithProgress(config, op, project, fmt.Sprintf("Backup Instance %s", name), userAgent, d.Timeout(schema.TimeoutUpdate))
+	if err != nil {
+		return 0, fmt.Errorf("Error, failed to back up instance %s before upgrade: %s", name, err)
+	}
+	if op != nil && op.BackupContext != nil && op.BackupContext.BackupId != 0 {
+		return op.BackupContext.BackupId, nil
+	}
+
+	// Not every operation reports its backup context, so fall back to finding
+	// the backup run by its description.
+	runs, err := config.NewSqlAdminClient(userAgent).BackupRuns.List(project, name).Do()
+	if err != nil {
+		return 0, fmt.Errorf("Error, failed to list backups of instance %s: %s", name, err)
+	}
+	for _, run := range runs.Items {
+		if run.Description == description {
+			return run.Id, nil
+		}
+	}
+	return 0, fmt.Errorf("Error, unable to find the backup of instance %s taken before upgrade", name)
+}
+
+// sqlDatabaseVersionUpgradeError lists the individual errors returned for a
+// failed upgrade, which include the results of the upgrade pre-checks.
+func sqlDatabaseVersionUpgradeError(name, oldVersion, newVersion string, err error) error {
+	var lines []string
+	var opErr SqlAdminOperationError
+	var gErr *googleapi.Error
+	if errors.As(err, &opErr) {
+		for _, e := range opErr.Errors {
+			lines = append(lines, fmt.Sprintf("  - %s: %s", e.Code, e.Message))
+		}
+	} else if errors.As(err, &gErr) {
+		for _, e := range gErr.Errors {
+			lines = append(lines, fmt.Sprintf("  - %s: %s", e.Reason, e.Message))
+		}
+		if len(lines) == 0 {
+			lines = append(lines, fmt.Sprintf("  - %s", gErr.Message))
+		}
+	}
+	if len(lines) == 0 {
+		return fmt.Errorf("Error, failed to upgrade instance %s from %s to %s: %s", name, oldVersion, newVersion, err)
+	}
+	return fmt.Errorf("Error, failed to upgrade instance %s from %s to %s:\n%s", name, oldVersion, newVersion, strings.Join(lines, "\n"))
+}
+
+// databaseVersionUpgradeCustomizeDiff rejects database version changes that
+// Cloud SQL can not perform in place, such as switching the database engine or
+// downgrading, at plan time rather than after a failed upgrade.
+func databaseVersionUpgradeCustomizeDiff(_ context.Context, diff *schema.ResourceDiff, _ interface{}) error {
+	if v, ok := diff.GetOk("major_version_upgrade"); ok && len(v.([]interface{})) > 0 && v.([]interface{})[0] != nil {
+		upgrade := v.([]interface{})[0].(map[string]interface{})
+		if upgrade["rollback_on_failure"].(bool) && !upgrade["backup_before_upgrade"].(bool) {
+			return fmt.Errorf("major_version_upgrade.0.rollback_on_failure requires major_version_upgrade.0.backup_before_upgrade to be true")
+		}
+	}
+	if diff.Id() == "" || !diff.HasChange("database_version") {
+		return nil
+	}
+	o, n := diff.GetChange("database_version")
+	return checkDatabaseVersionUpgrade(o.(string), n.(string))
+}
+
+func checkDatabaseVersionUpgrade(oldVersion, newVersion string) error {
+	if oldVersion == "" || newVersion == "" {
+		return nil
+	}
+	oldEngine, oldParts := parseSqlDatabaseVersion(oldVersion)
+	newEngine, newParts := parseSqlDatabaseVersion(newVersion)
+	if oldEngine != newEngine {
+		return fmt.Errorf("database_version can not be changed from %s to %s, the database engine can not be changed in place", oldVersion, newVersion)
+	}
+	// A version without a minor version, such as MYSQL_8_0, stands for the
+	// default minor version, so only the parts both versions have are compared.
+	if len(oldParts) > len(newParts) {
+		oldParts = oldParts[:len(newParts)]
+	}
+	if len(newParts) > len(oldParts) {
+		newParts = newParts[:len(oldParts)]
+	}
+	if compareSqlDatabaseVersionParts(newParts, oldParts) < 0 {
+		return fmt.Errorf("database_version can not be changed from %s to %s, Cloud SQL instances can not be downgraded", oldVersion, newVersion)
+	}
+	return nil
+}
+
+// isMajorDatabaseVersionUpgrade returns whether the major version changes,
+// which is the first number of the version for POSTGRES and SQLSERVER and the
+// first two numbers for MYSQL.
+func isMajorDatabaseVersionUpgrade(oldVersion, newVersion string) bool {
+	oldEngine, oldParts := parseSqlDatabaseVersion(oldVersion)
+	newEngine, newParts := parseSqlDatabaseVersion(newVersion)
+	if oldEngine != newEngine {
+		return true
+	}
+	major := 1
+	if oldEngine == "MYSQL" {
+		major = 2
+	}
+	if len(oldParts) > major {
+		oldParts = oldParts[:major]
+	}
+	if len(newParts) > major {
+		newParts = newParts[:major]
+	}
+	return compareSqlDatabaseVersionParts(oldParts, newParts) != 0
+}
+
+// parseSqlDatabaseVersion splits a database version such as MYSQL_8_0_31 or
+// SQLSERVER_2019_STANDARD into its engine and numeric version parts.
+func parseSqlDatabaseVersion(version string) (string, []int) {
+	fields := strings.Split(version, "_")
+	var parts []int
+	for _, f := range fields[1:] {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+	}
+	return fields[0], parts
+}
+
+func compareSqlDatabaseVersionParts(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
 func caseDiffDashSuppress(_, old, new string, _ *schema.ResourceData) bool {
 	postReplaceNew := strings.Replace(new, "-", "_", -1)
 	return strings.ToUpper(postReplaceNew) == strings.ToUpper(old)
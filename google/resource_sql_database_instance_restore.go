@@ -0,0 +1,359 @@
+package google
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/hashicorp/terraform-provider-google/google/tpgresource"
+	transport_tpg "github.com/hashicorp/terraform-provider-google/google/transport"
+	"github.com/hashicorp/terraform-provider-google/google/verify"
+
+	sqladmin "google.golang.org/api/sqladmin/v1beta4"
+)
+
+// ResourceSqlDatabaseInstanceRestore restores a backup run into an existing
+// instance, or a point in time of a source instance into a new instance. Cloud
+// SQL can only recover to a point in time by cloning, so in that case the target
+// instance is created by the restore.
+func ResourceSqlDatabaseInstanceRestore() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceSqlDatabaseInstanceRestoreCreate,
+		Read:   resourceSqlDatabaseInstanceRestoreRead,
+		Update: resourceSqlDatabaseInstanceRestoreUpdate,
+		Delete: resourceSqlDatabaseInstanceRestoreDelete,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(60 * time.Minute),
+			Delete: schema.DefaultTimeout(30 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"instance": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+				Description: `The name of the instance to restore into. When restoring a backup run it must already exist, when
+restoring to a point in time it is created by the restore.`,
+			},
+
+			"project": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: `The ID of the project in which the resource belongs. If it is not provided, the provider project is used.`,
+			},
+
+			"backup_run": {
+				Type:         schema.TypeList,
+				Optional:     true,
+				ForceNew:     true,
+				MaxItems:     1,
+				ExactlyOneOf: []string{"backup_run", "point_in_time"},
+				Description:  `The backup run to restore into the instance.`,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"backup_run_id": {
+							Type:        schema.TypeInt,
+							Required:    true,
+							ForceNew:    true,
+							Description: `The ID of the backup run to restore from.`,
+						},
+						"source_instance": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							ForceNew:    true,
+							Description: `The name of the instance the backup was taken from. Defaults to the target instance.`,
+						},
+						"source_project": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							ForceNew:    true,
+							Description: `The project of the instance the backup was taken from. Defaults to the project of the target instance.`,
+						},
+					},
+				},
+			},
+
+			"point_in_time": {
+				Type:         schema.TypeList,
+				Optional:     true,
+				ForceNew:     true,
+				MaxItems:     1,
+				ExactlyOneOf: []string{"backup_run", "point_in_time"},
+				Description:  `The point in time of a source instance to restore into a new instance.`,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"source_instance": {
+							Type:        schema.TypeString,
+							Required:    true,
+							ForceNew:    true,
+							Description: `The name of the instance to recover from. It must be in the same project as the target instance.`,
+						},
+						"timestamp": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ForceNew:     true,
+							ValidateFunc: verify.ValidateRFC3339Date,
+							ExactlyOneOf: []string{"point_in_time.0.timestamp", "point_in_time.0.bin_log_coordinates"},
+							Description:  `The timestamp to recover to, in RFC3339 UTC "Zulu" format.`,
+						},
+						"bin_log_coordinates": {
+							Type:         schema.TypeList,
+							Optional:     true,
+							ForceNew:     true,
+							MaxItems:     1,
+							ExactlyOneOf: []string{"point_in_time.0.timestamp", "point_in_time.0.bin_log_coordinates"},
+							Description:  `The binary log coordinates to recover to, for MySQL instances.`,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"file_name": {
+										Type:        schema.TypeString,
+										Required:    true,
+										ForceNew:    true,
+										Description: `Name of the binary log file.`,
+									},
+									"position": {
+										Type:        schema.TypeInt,
+										Required:    true,
+										ForceNew:    true,
+										Description: `Position within the binary log file.`,
+									},
+								},
+							},
+						},
+						"database_names": {
+							Type:        schema.TypeList,
+							Optional:    true,
+							ForceNew:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: `(SQL Server only) The databases to recover. All databases are recovered if empty.`,
+						},
+						"allocated_ip_range": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							ForceNew:    true,
+							Description: `The name of the allocated IP range for a private IP target instance.`,
+						},
+					},
+				},
+			},
+
+			"deletion_policy": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "ABANDON",
+				ValidateFunc: validation.StringInSlice([]string{"ABANDON", "DELETE"}, false),
+				Description: `What happens to the instance created by a point in time restore when this resource is destroyed.
+ABANDON leaves it in place and DELETE deletes it. Instances restored from a backup run are never deleted. Possible
+values are: "ABANDON", "DELETE".`,
+			},
+
+			"recovery_point": {
+				Type:     schema.TypeString,
+				Computed: true,
+				Description: `The time the instance data was recovered to, in RFC3339 format. This is the end of the backup run, or
+the requested timestamp. It is empty when recovering to binary log coordinates.`,
+			},
+
+			"restore_time": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The time the restore finished, in RFC3339 format.`,
+			},
+
+			"operation": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: `The name of the Cloud SQL operation that performed the restore.`,
+			},
+		},
+		UseJSONNumber: true,
+	}
+}
+
+func resourceSqlDatabaseInstanceRestoreCreate(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	project, err := tpgresource.GetProject(d, config)
+	if err != nil {
+		return err
+	}
+
+	instance := d.Get("instance").(string)
+
+	var op *sqladmin.Operation
+	var recoveryPoint string
+	if v, ok := d.GetOk("backup_run"); ok {
+		backupRun := v.([]interface{})[0].(map[string]interface{})
+		restoreContext := &sqladmin.RestoreBackupContext{
+			BackupRunId: int64(backupRun["backup_run_id"].(int)),
+			InstanceId:  backupRun["source_instance"].(string),
+			Project:     backupRun["source_project"].(string),
+		}
+		sourceProject, sourceInstance := project, instance
+		if restoreContext.InstanceId != "" {
+			sourceInstance = restoreContext.InstanceId
+		}
+		if restoreContext.Project != "" {
+			sourceProject = restoreContext.Project
+		}
+
+		run, err := config.NewSqlAdminClient(userAgent).BackupRuns.Get(sourceProject, sourceInstance, restoreContext.BackupRunId).Do()
+		if err != nil {
+			return fmt.Errorf("Error reading backup run %d of instance %s: %s", restoreContext.BackupRunId, sourceInstance, err)
+		}
+		recoveryPoint = run.EndTime
+
+		transport_tpg.MutexStore.Lock(instanceMutexKey(project, instance))
+		defer transport_tpg.MutexStore.Unlock(instanceMutexKey(project, instance))
+
+		log.Printf("[DEBUG] Restoring backup run %d of instance %s into instance %s", restoreContext.BackupRunId, sourceInstance, instance)
+		err = transport_tpg.RetryTimeDuration(func() (rerr error) {
+			op, rerr = config.NewSqlAdminClient(userAgent).Instances.RestoreBackup(project, instance, &sqladmin.InstancesRestoreBackupRequest{
+				RestoreBackupContext: restoreContext,
+			}).Do()
+			return rerr
+		}, d.Timeout(schema.TimeoutCreate), transport_tpg.IsSqlOperationInProgressError)
+		if err != nil {
+			return fmt.Errorf("Error, failed to restore instance %s from backup run %d: %s", instance, restoreContext.BackupRunId, err)
+		}
+	} else {
+		pointInTime := d.Get("point_in_time").([]interface{})[0].(map[string]interface{})
+		sourceInstance := pointInTime["source_instance"].(string)
+		cloneContext := expandSqlDatabaseInstanceRestorePointInTime(pointInTime, instance)
+		recoveryPoint = cloneContext.PointInTime
+
+		log.Printf("[DEBUG] Restoring instance %s to a point in time into instance %s", sourceInstance, instance)
+		err = transport_tpg.RetryTimeDuration(func() (rerr error) {
+			op, rerr = config.NewSqlAdminClient(userAgent).Instances.Clone(project, sourceInstance, &sqladmin.InstancesCloneRequest{
+				CloneContext: cloneContext,
+			}).Do()
+			return rerr
+		}, d.Timeout(schema.TimeoutCreate), transport_tpg.IsSqlOperationInProgressError)
+		if err != nil {
+			return fmt.Errorf("Error, failed to restore instance %s to a point in time into %s: %s", sourceInstance, instance, err)
+		}
+	}
+
+	d.SetId(fmt.Sprintf("projects/%s/instances/%s/restores/%s", project, instance, op.Name))
+
+	op, err = sqlAdminOperationWaitWithProgress(config, op, project, fmt.Sprintf("Restore Instance %s", instance), userAgent, d.Timeout(schema.TimeoutCreate))
+	if err != nil {
+		// The restore did not happen, so there is nothing to keep in state.
+		d.SetId("")
+		return err
+	}
+
+	if err := d.Set("operation", op.Name); err != nil {
+		return fmt.Errorf("Error setting operation: %s", err)
+	}
+	if err := d.Set("recovery_point", recoveryPoint); err != nil {
+		return fmt.Errorf("Error setting recovery_point: %s", err)
+	}
+	if err := d.Set("restore_time", op.EndTime); err != nil {
+		return fmt.Errorf("Error setting restore_time: %s", err)
+	}
+
+	log.Printf("[DEBUG] Finished restoring instance %s", instance)
+
+	return resourceSqlDatabaseInstanceRestoreRead(d, meta)
+}
+
+// The restore itself can not be read back, so reading only checks that the
+// target instance still exists.
+func resourceSqlDatabaseInstanceRestoreRead(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	project, err := tpgresource.GetProject(d, config)
+	if err != nil {
+		return err
+	}
+
+	instance := d.Get("instance").(string)
+	_, err = config.NewSqlAdminClient(userAgent).Instances.Get(project, instance).Do()
+	if err != nil {
+		return transport_tpg.HandleNotFoundError(transformSQLDatabaseReadError(err), d, fmt.Sprintf("SQL Database Instance Restore into %q", instance))
+	}
+
+	if err := d.Set("project", project); err != nil {
+		return fmt.Errorf("Error setting project: %s", err)
+	}
+
+	return nil
+}
+
+// Only deletion_policy can be updated, which is only used on delete.
+func resourceSqlDatabaseInstanceRestoreUpdate(d *schema.ResourceData, meta interface{}) error {
+	return resourceSqlDatabaseInstanceRestoreRead(d, meta)
+}
+
+func resourceSqlDatabaseInstanceRestoreDelete(d *schema.ResourceData, meta interface{}) error {
+	config := meta.(*transport_tpg.Config)
+
+	instance := d.Get("instance").(string)
+	if _, ok := d.GetOk("point_in_time"); !ok || d.Get("deletion_policy").(string) != "DELETE" {
+		log.Printf("[DEBUG] Removing SQL Database Instance Restore into %q from state, the instance is left in place.", instance)
+		d.SetId("")
+		return nil
+	}
+
+	userAgent, err := tpgresource.GenerateUserAgentString(d, config.UserAgent)
+	if err != nil {
+		return err
+	}
+
+	project, err := tpgresource.GetProject(d, config)
+	if err != nil {
+		return err
+	}
+
+	transport_tpg.MutexStore.Lock(instanceMutexKey(project, instance))
+	defer transport_tpg.MutexStore.Unlock(instanceMutexKey(project, instance))
+
+	var op *sqladmin.Operation
+	err = transport_tpg.RetryTimeDuration(func() (rerr error) {
+		op, rerr = config.NewSqlAdminClient(userAgent).Instances.Delete(project, instance).Do()
+		return rerr
+	}, d.Timeout(schema.TimeoutDelete), transport_tpg.IsSqlOperationInProgressError)
+	if err != nil {
+		return transport_tpg.HandleNotFoundError(err, d, fmt.Sprintf("SQL Database Instance %q", instance))
+	}
+
+	err = SqlAdminOperationWaitTime(config, op, project, "Delete Instance", userAgent, d.Timeout(schema.TimeoutDelete))
+	if err != nil {
+		return fmt.Errorf("Error, failure waiting for delete of %s: %s", instance, err)
+	}
+
+	d.SetId("")
+	return nil
+}
+
+func expandSqlDatabaseInstanceRestorePointInTime(pointInTime map[string]interface{}, destination string) *sqladmin.CloneContext {
+	cloneContext := &sqladmin.CloneContext{
+		DestinationInstanceName: destination,
+		PointInTime:             pointInTime["timestamp"].(string),
+		AllocatedIpRange:        pointInTime["allocated_ip_range"].(string),
+		DatabaseNames:           tpgresource.ConvertStringArr(pointInTime["database_names"].([]interface{})),
+	}
+	if v := pointInTime["bin_log_coordinates"].([]interface{}); len(v) > 0 && v[0] != nil {
+		coordinates := v[0].(map[string]interface{})
+		cloneContext.BinLogCoordinates = &sqladmin.BinLogCoordinates{
+			BinLogFileName: coordinates["file_name"].(string),
+			BinLogPosition: int64(coordinates["position"].(int)),
+		}
+	}
+	return cloneContext
+}
@@ -0,0 +1,66 @@
+package google
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-provider-google/google/acctest"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccSqlDatabaseInstanceRestore_backupRun(t *testing.T) {
+	// Sqladmin client
+	acctest.SkipIfVcr(t)
+	t.Parallel()
+
+	context := map[string]interface{}{
+		"source_instance": BootstrapSharedSQLInstanceBackupRun(t),
+		"random_suffix":   RandString(t, 10),
+	}
+
+	VcrTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.AccTestPreCheck(t) },
+		ProtoV5ProviderFactories: ProtoV5ProviderFactories(t),
+		CheckDestroy:             testAccSqlDatabaseInstanceDestroyProducer(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSqlDatabaseInstanceRestore_backupRun(context),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("google_sql_database_instance_restore.restore", "recovery_point"),
+					resource.TestCheckResourceAttrSet("google_sql_database_instance_restore.restore", "restore_time"),
+					resource.TestCheckResourceAttrSet("google_sql_database_instance_restore.restore", "operation"),
+				),
+			},
+		},
+	})
+}
+
+func testAccSqlDatabaseInstanceRestore_backupRun(context map[string]interface{}) string {
+	return Nprintf(`
+data "google_sql_backup_run" "backup" {
+  instance    = "%{source_instance}"
+  most_recent = true
+}
+
+resource "google_sql_database_instance" "target" {
+  name             = "tf-test-instance-%{random_suffix}"
+  database_version = "POSTGRES_11"
+  region           = "us-central1"
+
+  settings {
+    tier = "db-f1-micro"
+  }
+
+  deletion_protection = false
+}
+
+resource "google_sql_database_instance_restore" "restore" {
+  instance = google_sql_database_instance.target.name
+
+  backup_run {
+    backup_run_id   = data.google_sql_backup_run.backup.backup_id
+    source_instance = data.google_sql_backup_run.backup.instance
+  }
+}
+`, context)
+}
@@ -62,6 +62,138 @@ func TestMaintenanceVersionDiffSuppress(t *testing.T) {
 	}
 }
 
+func TestCheckDatabaseVersionUpgrade(t *testing.T) {
+	cases := map[string]struct {
+		Old, New    string
+		ExpectError bool
+		Major       bool
+	}{
+		"postgres major upgrade": {
+			Old:   "POSTGRES_14",
+			New:   "POSTGRES_15",
+			Major: true,
+		},
+		"mysql major upgrade": {
+			Old:   "MYSQL_5_7",
+			New:   "MYSQL_8_0",
+			Major: true,
+		},
+		"mysql minor upgrade": {
+			Old: "MYSQL_8_0",
+			New: "MYSQL_8_0_31",
+		},
+		"mysql minor version to default minor version": {
+			Old: "MYSQL_8_0_31",
+			New: "MYSQL_8_0",
+		},
+		"mysql minor downgrade": {
+			Old:         "MYSQL_8_0_31",
+			New:         "MYSQL_8_0_26",
+			ExpectError: true,
+		},
+		"mysql major downgrade": {
+			Old:         "MYSQL_8_0_31",
+			New:         "MYSQL_5_7",
+			ExpectError: true,
+			Major:       true,
+		},
+		"sqlserver edition upgrade": {
+			Old: "SQLSERVER_2017_STANDARD",
+			New: "SQLSERVER_2017_ENTERPRISE",
+		},
+		"sqlserver major upgrade": {
+			Old:   "SQLSERVER_2017_STANDARD",
+			New:   "SQLSERVER_2019_STANDARD",
+			Major: true,
+		},
+		"downgrade": {
+			Old:         "POSTGRES_15",
+			New:         "POSTGRES_14",
+			ExpectError: true,
+			Major:       true,
+		},
+		"engine change": {
+			Old:         "MYSQL_8_0",
+			New:         "POSTGRES_15",
+			ExpectError: true,
+			Major:       true,
+		},
+	}
+
+	for tn, tc := range cases {
+		tc := tc
+		t.Run(tn, func(t *testing.T) {
+			t.Parallel()
+			err := checkDatabaseVersionUpgrade(tc.Old, tc.New)
+			if (err != nil) != tc.ExpectError {
+				t.Fatalf("%q => %q expected error %t, got %v", tc.Old, tc.New, tc.ExpectError, err)
+			}
+			if got := isMajorDatabaseVersionUpgrade(tc.Old, tc.New); got != tc.Major {
+				t.Fatalf("%q => %q expected major upgrade %t, got %t", tc.Old, tc.New, tc.Major, got)
+			}
+		})
+	}
+}
+
+func TestSqlDatabaseUpgradeNeedsRestore(t *testing.T) {
+	t.Parallel()
+
+	started := &sqladmin.Operation{StartTime: "2023-05-01T10:00:00Z", Status: "DONE"}
+	cases := map[string]struct {
+		Op       *sqladmin.Operation
+		Instance *sqladmin.DatabaseInstance
+		Restore  bool
+	}{
+		"rejected before the operation started": {
+			Op:       &sqladmin.Operation{Status: "DONE"},
+			Instance: &sqladmin.DatabaseInstance{DatabaseVersion: "POSTGRES_15", State: "FAILED"},
+		},
+		"no operation": {
+			Instance: &sqladmin.DatabaseInstance{DatabaseVersion: "POSTGRES_15", State: "FAILED"},
+		},
+		"rolled back by Cloud SQL": {
+			Op:       started,
+			Instance: &sqladmin.DatabaseInstance{DatabaseVersion: "POSTGRES_14", State: "RUNNABLE"},
+		},
+		"instance left failed": {
+			Op:       started,
+			Instance: &sqladmin.DatabaseInstance{DatabaseVersion: "POSTGRES_14", State: "FAILED"},
+			Restore:  true,
+		},
+		"instance left on the new version": {
+			Op:       started,
+			Instance: &sqladmin.DatabaseInstance{DatabaseVersion: "POSTGRES_15", State: "RUNNABLE"},
+		},
+		"instance left failed on the new version": {
+			Op:       started,
+			Instance: &sqladmin.DatabaseInstance{DatabaseVersion: "POSTGRES_15", State: "FAILED"},
+		},
+	}
+
+	for tn, tc := range cases {
+		if got := sqlDatabaseUpgradeNeedsRestore(tc.Op, tc.Instance, "POSTGRES_14"); got != tc.Restore {
+			t.Errorf("%s: expected restore %t, got %t", tn, tc.Restore, got)
+		}
+	}
+}
+
+func TestSqlDatabaseVersionUpgradeError(t *testing.T) {
+	t.Parallel()
+
+	err := sqlDatabaseVersionUpgradeError("instance", "POSTGRES_14", "POSTGRES_15", SqlAdminOperationError(sqladmin.OperationErrors{
+		Errors: []*sqladmin.OperationError{
+			{Code: "PG_UPGRADE_FAILURE", Message: "extension pg_foo is not supported"},
+			{Code: "PRECHECK_FAILURE", Message: "unsupported configuration"},
+		},
+	}))
+	expected := `Error, failed to upgrade instance instance from POSTGRES_14 to POSTGRES_15:
+  - PG_UPGRADE_FAILURE: extension pg_foo is not supported
+  - PRECHECK_FAILURE: unsupported configuration`
+	if err.Error() != expected {
+		t.Fatalf("expected %q, got %q", expected, err.Error())
+	}
+}
+
 func TestAccSqlDatabaseInstance_basicInferredName(t *testing.T) {
 	// Randomness
 	acctest.SkipIfVcr(t)
@@ -1568,6 +1700,43 @@ func TestAccSqlDatabaseInstance_rootPasswordShouldBeUpdatable(t *testing.T) {
 	})
 }
 
+func TestAccSqlDatabaseInstance_majorVersionUpgrade(t *testing.T) {
+	t.Parallel()
+
+	instanceName := "tf-test-" + RandString(t, 10)
+
+	VcrTest(t, resource.TestCase{
+		PreCheck:                 func() { acctest.AccTestPreCheck(t) },
+		ProtoV5ProviderFactories: ProtoV5ProviderFactories(t),
+		CheckDestroy:             testAccSqlDatabaseInstanceDestroyProducer(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testGoogleSqlDatabaseInstance_majorVersionUpgrade(instanceName, "POSTGRES_14"),
+			},
+			{
+				ResourceName:            "google_sql_database_instance.instance",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"deletion_protection", "major_version_upgrade"},
+			},
+			{
+				Config: testGoogleSqlDatabaseInstance_majorVersionUpgrade(instanceName, "POSTGRES_15"),
+				Check:  resource.TestCheckResourceAttr("google_sql_database_instance.instance", "database_version", "POSTGRES_15"),
+			},
+			{
+				ResourceName:            "google_sql_database_instance.instance",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"deletion_protection", "major_version_upgrade"},
+			},
+			{
+				Config:      testGoogleSqlDatabaseInstance_majorVersionUpgrade(instanceName, "POSTGRES_14"),
+				ExpectError: regexp.MustCompile(`Cloud SQL instances can not be downgraded`),
+			},
+		},
+	})
+}
+
 func TestAccSqlDatabaseInstance_activationPolicy(t *testing.T) {
 	t.Parallel()
 
@@ -3576,3 +3745,23 @@ resource "google_sql_database_instance" "instance" {
 }
 `, instance, databaseVersion, deletionProtection, activationPolicy)
 }
+
+func testGoogleSqlDatabaseInstance_majorVersionUpgrade(instance, databaseVersion string) string {
+	return fmt.Sprintf(`
+resource "google_sql_database_instance" "instance" {
+  name                = "%s"
+  region              = "us-central1"
+  database_version    = "%s"
+  deletion_protection = false
+
+  major_version_upgrade {
+    backup_before_upgrade = true
+    rollback_on_failure   = true
+  }
+
+  settings {
+    tier = "db-custom-2-13312"
+  }
+}
+`, instance, databaseVersion)
+}
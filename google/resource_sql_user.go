@@ -84,9 +84,10 @@ func ResourceSqlUser() *schema.Resource {
 			},
 
 			"password": {
-				Type:      schema.TypeString,
-				Optional:  true,
-				Sensitive: true,
+				Type:             schema.TypeString,
+				Optional:         true,
+				Sensitive:        true,
+				DiffSuppressFunc: tpgresource.SecretStateDiffSuppress,
 				Description: `The password for the user. Can be updated. For Postgres instances this is a Required field, unless type is set to
                 either CLOUD_IAM_USER or CLOUD_IAM_SERVICE_ACCOUNT.`,
 			},
@@ -185,6 +186,8 @@ func ResourceSqlUser() *schema.Resource {
 				have been granted SQL roles. Possible values are: "ABANDON".`,
 				ValidateFunc: validation.StringInSlice([]string{"ABANDON", ""}, false),
 			},
+
+			"secret_state_mode": tpgresource.SecretStateModeSchema(),
 		},
 		UseJSONNumber: true,
 	}
@@ -361,6 +364,12 @@ func resourceSqlUserRead(d *schema.ResourceData, meta interface{}) error {
 		}
 	}
 
+	// The password can't be read back, so the value already in state is kept,
+	// hashed if needed.
+	if err := tpgresource.SetSecretState(d, config, "password", d.Get("password").(string)); err != nil {
+		return fmt.Errorf("Error setting password: %s", err)
+	}
+
 	d.SetId(fmt.Sprintf("%s/%s/%s", user.Name, user.Host, user.Instance))
 	return nil
 }
@@ -422,6 +431,12 @@ func resourceSqlUserUpdate(d *schema.ResourceData, meta interface{}) error {
 		password := d.Get("password").(string)
 		host := d.Get("host").(string)
 
+		// An unchanged password may only be known by its hash, in which case
+		// it is left out of the request and stays as it is.
+		if tpgresource.IsSecretStateHash(password) {
+			password = ""
+		}
+
 		user := &sqladmin.User{
 			Name:     name,
 			Instance: instance,
@@ -449,10 +464,9 @@ func resourceSqlUserUpdate(d *schema.ResourceData, meta interface{}) error {
 				"in %s: %s", name, instance, err)
 		}
 
-		return resourceSqlUserRead(d, meta)
 	}
 
-	return nil
+	return resourceSqlUserRead(d, meta)
 }
 
 func resourceSqlUserDelete(d *schema.ResourceData, meta interface{}) error {
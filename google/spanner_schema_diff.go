@@ -0,0 +1,581 @@
+package google
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// This file computes the DDL statements that take a Spanner database from
+// one schema to another. Schemas are lists of GoogleSQL DDL statements, as
+// returned by GetDatabaseDdl or written by users. Tables, indexes, views and
+// change streams are compared object by object; any other statement (such as
+// ALTER DATABASE) is run when it is added and otherwise left alone.
+
+type spannerDdlToken struct {
+	// norm is the token as used for comparisons: keywords and identifiers
+	// are case-insensitive in Spanner and quoted identifiers are unquoted.
+	norm       string
+	start, end int
+	quoted     bool
+}
+
+// tokenizeSpannerDdl splits a DDL statement into tokens, dropping whitespace
+// and comments.
+func tokenizeSpannerDdl(stmt string) ([]spannerDdlToken, error) {
+	var tokens []spannerDdlToken
+	for i := 0; i < len(stmt); {
+		c := stmt[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+		case c == '#' || strings.HasPrefix(stmt[i:], "--"):
+			end := strings.IndexByte(stmt[i:], '\n')
+			if end < 0 {
+				return tokens, nil
+			}
+			i += end + 1
+		case strings.HasPrefix(stmt[i:], "/*"):
+			end := strings.Index(stmt[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment in %q", stmt)
+			}
+			i += end + 4
+		case c == '\'' || c == '"' || c == '`':
+			quote := string(c)
+			if c != '`' && strings.HasPrefix(stmt[i:], strings.Repeat(quote, 3)) {
+				quote = strings.Repeat(quote, 3)
+			}
+			j := i + len(quote)
+			for ; j < len(stmt); j++ {
+				if stmt[j] == '\\' {
+					j++
+					continue
+				}
+				if strings.HasPrefix(stmt[j:], quote) {
+					break
+				}
+			}
+			if j >= len(stmt) {
+				return nil, fmt.Errorf("unterminated quoted string in %q", stmt)
+			}
+			end := j + len(quote)
+			token := spannerDdlToken{norm: stmt[i:end], start: i, end: end, quoted: true}
+			if c == '`' {
+				token.norm = strings.ToUpper(stmt[i+1 : j])
+				token.quoted = false
+			}
+			tokens = append(tokens, token)
+			i = end
+		case c == '_' || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c)):
+			j := i
+			for j < len(stmt) && (stmt[j] == '_' || unicode.IsLetter(rune(stmt[j])) || unicode.IsDigit(rune(stmt[j]))) {
+				j++
+			}
+			tokens = append(tokens, spannerDdlToken{norm: strings.ToUpper(stmt[i:j]), start: i, end: j})
+			i = j
+		default:
+			tokens = append(tokens, spannerDdlToken{norm: string(c), start: i, end: i + 1})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+// normalizeSpannerDdlTokens joins tokens for comparison. Trailing commas,
+// which GetDatabaseDdl adds after the last column of a table, are ignored.
+func normalizeSpannerDdlTokens(tokens []spannerDdlToken) string {
+	var parts []string
+	for i, t := range tokens {
+		if t.norm == "," && !t.quoted && (i == len(tokens)-1 || tokens[i+1].norm == ")") {
+			continue
+		}
+		parts = append(parts, t.norm)
+	}
+	return strings.Join(parts, " ")
+}
+
+// spannerDdlElement is a part of a statement, such as a column definition.
+type spannerDdlElement struct {
+	key  string
+	text string
+	norm string
+}
+
+type spannerDdlObject struct {
+	// kind is one of TABLE, INDEX, VIEW, CHANGE STREAM, or empty for
+	// statements that aren't compared.
+	kind     string
+	name     string
+	nameText string
+	text     string
+	norm     string
+
+	// Tables
+	columns           []spannerDdlElement
+	constraints       []spannerDdlElement
+	key               string
+	rowDeletionPolicy *spannerDdlElement
+
+	// Change streams
+	forClause *spannerDdlElement
+	options   *spannerDdlElement
+}
+
+func (o *spannerDdlObject) id() string {
+	if o.kind == "" {
+		return o.norm
+	}
+	return o.kind + " " + o.name
+}
+
+func spannerDdlElementFromTokens(stmt string, tokens []spannerDdlToken) spannerDdlElement {
+	return spannerDdlElement{
+		key:  tokens[0].norm,
+		text: stmt[tokens[0].start:tokens[len(tokens)-1].end],
+		norm: normalizeSpannerDdlTokens(tokens),
+	}
+}
+
+// splitSpannerDdlTokens splits tokens on commas that aren't nested in
+// parentheses or angle brackets, dropping empty parts.
+func splitSpannerDdlTokens(tokens []spannerDdlToken) [][]spannerDdlToken {
+	var parts [][]spannerDdlToken
+	depth, angles, start := 0, 0, 0
+	for i, t := range tokens {
+		if t.quoted {
+			continue
+		}
+		switch t.norm {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		case "<":
+			// Only count the brackets of ARRAY<...> and STRUCT<...>, not
+			// comparisons in CHECK constraints.
+			if i > 0 && (tokens[i-1].norm == "ARRAY" || tokens[i-1].norm == "STRUCT") {
+				angles++
+			}
+		case ">":
+			if angles > 0 {
+				angles--
+			}
+		case ",":
+			if depth == 0 && angles == 0 {
+				if i > start {
+					parts = append(parts, tokens[start:i])
+				}
+				start = i + 1
+			}
+		}
+	}
+	if len(tokens) > start {
+		parts = append(parts, tokens[start:])
+	}
+	return parts
+}
+
+// matchingSpannerDdlParen returns the index of the parenthesis closing the
+// one at tokens[open].
+func matchingSpannerDdlParen(tokens []spannerDdlToken, open int) int {
+	depth := 0
+	for i := open; i < len(tokens); i++ {
+		if tokens[i].quoted {
+			continue
+		}
+		switch tokens[i].norm {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// readName reads the object's possibly dotted name starting at tokens[i],
+// and returns the index of the token following it.
+func (o *spannerDdlObject) readName(tokens []spannerDdlToken, i int) int {
+	if i >= len(tokens) {
+		return i
+	}
+	start := i
+	o.name = tokens[i].norm
+	i++
+	for i+1 < len(tokens) && tokens[i].norm == "." {
+		o.name += "." + tokens[i+1].norm
+		i += 2
+	}
+	o.nameText = o.text[tokens[start].start:tokens[i-1].end]
+	return i
+}
+
+func tokensHavePrefix(tokens []spannerDdlToken, prefix ...string) bool {
+	if len(tokens) < len(prefix) {
+		return false
+	}
+	for i, p := range prefix {
+		if tokens[i].quoted || tokens[i].norm != p {
+			return false
+		}
+	}
+	return true
+}
+
+func parseSpannerDdl(stmt string) (*spannerDdlObject, error) {
+	stmt = strings.TrimRight(strings.TrimSpace(stmt), ";")
+	tokens, err := tokenizeSpannerDdl(stmt)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty DDL statement")
+	}
+
+	o := &spannerDdlObject{
+		text: stmt,
+		norm: normalizeSpannerDdlTokens(tokens),
+	}
+
+	switch {
+	case tokensHavePrefix(tokens, "CREATE", "TABLE"):
+		o.kind = "TABLE"
+		i := o.readName(tokens, 2)
+		if i >= len(tokens) || tokens[i].norm != "(" {
+			return nil, fmt.Errorf("unable to parse table definition %q", stmt)
+		}
+		close := matchingSpannerDdlParen(tokens, i)
+		if close < 0 {
+			return nil, fmt.Errorf("unbalanced parentheses in %q", stmt)
+		}
+		for _, part := range splitSpannerDdlTokens(tokens[i+1 : close]) {
+			e := spannerDdlElementFromTokens(stmt, part)
+			switch {
+			case tokensHavePrefix(part, "CONSTRAINT") && len(part) > 1:
+				e.key = "CONSTRAINT " + part[1].norm
+				o.constraints = append(o.constraints, e)
+			case tokensHavePrefix(part, "FOREIGN"), tokensHavePrefix(part, "CHECK"):
+				e.key = e.norm
+				o.constraints = append(o.constraints, e)
+			default:
+				o.columns = append(o.columns, e)
+			}
+		}
+		var key []string
+		for _, part := range splitSpannerDdlTokens(tokens[close+1:]) {
+			if tokensHavePrefix(part, "ROW", "DELETION", "POLICY") {
+				e := spannerDdlElementFromTokens(stmt, part)
+				o.rowDeletionPolicy = &e
+				continue
+			}
+			key = append(key, normalizeSpannerDdlTokens(part))
+		}
+		o.key = strings.Join(key, ", ")
+	case tokensHavePrefix(tokens, "CREATE", "INDEX"):
+		o.kind = "INDEX"
+		o.readName(tokens, 2)
+	case tokensHavePrefix(tokens, "CREATE", "UNIQUE", "INDEX"), tokensHavePrefix(tokens, "CREATE", "NULL_FILTERED", "INDEX"):
+		o.kind = "INDEX"
+		o.readName(tokens, 3)
+	case tokensHavePrefix(tokens, "CREATE", "UNIQUE", "NULL_FILTERED", "INDEX"):
+		o.kind = "INDEX"
+		o.readName(tokens, 4)
+	case tokensHavePrefix(tokens, "CREATE", "VIEW"):
+		o.kind = "VIEW"
+		o.readName(tokens, 2)
+	case tokensHavePrefix(tokens, "CREATE", "OR", "REPLACE", "VIEW"):
+		o.kind = "VIEW"
+		o.readName(tokens, 4)
+	case tokensHavePrefix(tokens, "CREATE", "CHANGE", "STREAM"):
+		o.kind = "CHANGE STREAM"
+		i := o.readName(tokens, 3)
+		rest := tokens[i:]
+		optionsAt := len(rest)
+		for j, t := range rest {
+			if !t.quoted && t.norm == "OPTIONS" {
+				optionsAt = j
+				break
+			}
+		}
+		if optionsAt > 0 {
+			e := spannerDdlElementFromTokens(stmt, rest[:optionsAt])
+			o.forClause = &e
+		}
+		if optionsAt < len(rest) {
+			e := spannerDdlElementFromTokens(stmt, rest[optionsAt:])
+			o.options = &e
+		}
+	}
+
+	return o, nil
+}
+
+// spannerDdlOptionKeys returns the option names set in an OPTIONS (...)
+// clause.
+func spannerDdlOptionKeys(options string) ([]string, error) {
+	tokens, err := tokenizeSpannerDdl(options)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) < 3 || tokens[1].norm != "(" {
+		return nil, nil
+	}
+	var keys []string
+	for _, part := range splitSpannerDdlTokens(tokens[2 : len(tokens)-1]) {
+		keys = append(keys, options[part[0].start:part[0].end])
+	}
+	return keys, nil
+}
+
+// splitSpannerColumnOptions separates a column's OPTIONS clause from the
+// rest of its definition, since the two are changed by different statements.
+func splitSpannerColumnOptions(column spannerDdlElement) (spannerDdlElement, *spannerDdlElement, error) {
+	tokens, err := tokenizeSpannerDdl(column.text)
+	if err != nil {
+		return column, nil, err
+	}
+	for i, t := range tokens {
+		if !t.quoted && t.norm == "OPTIONS" && i > 0 {
+			def := spannerDdlElementFromTokens(column.text, tokens[:i])
+			options := spannerDdlElementFromTokens(column.text, tokens[i:])
+			return def, &options, nil
+		}
+	}
+	return column, nil, nil
+}
+
+func parseSpannerSchema(statements []string) ([]*spannerDdlObject, map[string]*spannerDdlObject, error) {
+	var objects []*spannerDdlObject
+	byId := make(map[string]*spannerDdlObject)
+	for _, stmt := range statements {
+		if strings.TrimSpace(stmt) == "" {
+			continue
+		}
+		o, err := parseSpannerDdl(stmt)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, ok := byId[o.id()]; ok && o.kind != "" {
+			return nil, nil, fmt.Errorf("%s %s is defined more than once", strings.ToLower(o.kind), o.name)
+		}
+		objects = append(objects, o)
+		byId[o.id()] = o
+	}
+	return objects, byId, nil
+}
+
+// diffSpannerSchema returns the statements that take a database with the
+// current schema to the desired one. Objects are dropped first, in the
+// reverse of their current order, and then created or altered in their
+// desired order, so that dependent objects such as interleaved tables and
+// indexes are handled after the objects they depend on.
+func diffSpannerSchema(current, desired []string) ([]string, error) {
+	currentObjects, currentById, err := parseSpannerSchema(current)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing current schema: %s", err)
+	}
+	desiredObjects, desiredById, err := parseSpannerSchema(desired)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing desired schema: %s", err)
+	}
+
+	var statements []string
+	for i := len(currentObjects) - 1; i >= 0; i-- {
+		o := currentObjects[i]
+		if o.kind == "" {
+			continue
+		}
+		d, ok := desiredById[o.id()]
+		switch {
+		case !ok:
+			statements = append(statements, fmt.Sprintf("DROP %s %s", o.kind, o.nameText))
+		case o.kind == "INDEX" && o.norm != d.norm:
+			// Indexes can't be altered, so they're dropped here and
+			// created again below.
+			statements = append(statements, fmt.Sprintf("DROP INDEX %s", o.nameText))
+		case o.kind == "TABLE" && o.key != d.key:
+			return nil, fmt.Errorf("the primary key or interleaving of table %s can't be changed without recreating it, which would delete its data. Remove the table from the schema first to drop it", o.name)
+		}
+	}
+
+	for _, d := range desiredObjects {
+		o, ok := currentById[d.id()]
+		if !ok {
+			statements = append(statements, d.text)
+			continue
+		}
+		if o.norm == d.norm {
+			continue
+		}
+		switch d.kind {
+		case "INDEX":
+			statements = append(statements, d.text)
+		case "VIEW":
+			statements = append(statements, "CREATE OR REPLACE VIEW "+d.text[strings.Index(strings.ToUpper(d.text), "VIEW")+len("VIEW"):])
+		case "CHANGE STREAM":
+			stmts, err := diffSpannerChangeStream(o, d)
+			if err != nil {
+				return nil, err
+			}
+			statements = append(statements, stmts...)
+		case "TABLE":
+			stmts, err := diffSpannerTable(o, d)
+			if err != nil {
+				return nil, err
+			}
+			statements = append(statements, stmts...)
+		}
+	}
+
+	for i, stmt := range statements {
+		statements[i] = strings.TrimSpace(stmt)
+	}
+	return statements, nil
+}
+
+func diffSpannerChangeStream(o, d *spannerDdlObject) ([]string, error) {
+	name := d.nameText
+	var statements []string
+
+	if !spannerDdlElementsEqual(o.forClause, d.forClause) {
+		if d.forClause == nil {
+			statements = append(statements, fmt.Sprintf("ALTER CHANGE STREAM %s DROP FOR ALL", name))
+		} else {
+			statements = append(statements, fmt.Sprintf("ALTER CHANGE STREAM %s SET %s", name, d.forClause.text))
+		}
+	}
+
+	if !spannerDdlElementsEqual(o.options, d.options) {
+		if d.options == nil {
+			// Setting an option to NULL returns it to its default.
+			keys, err := spannerDdlOptionKeys(o.options.text)
+			if err != nil {
+				return nil, err
+			}
+			var reset []string
+			for _, k := range keys {
+				reset = append(reset, k+" = NULL")
+			}
+			statements = append(statements, fmt.Sprintf("ALTER CHANGE STREAM %s SET OPTIONS (%s)", name, strings.Join(reset, ", ")))
+		} else {
+			statements = append(statements, fmt.Sprintf("ALTER CHANGE STREAM %s SET %s", name, d.options.text))
+		}
+	}
+
+	return statements, nil
+}
+
+func diffSpannerTable(o, d *spannerDdlObject) ([]string, error) {
+	table := d.nameText
+	var statements []string
+
+	desiredConstraints := spannerDdlElementsByKey(d.constraints)
+	for _, c := range o.constraints {
+		if dc, ok := desiredConstraints[c.key]; ok && dc.norm == c.norm {
+			continue
+		}
+		if !strings.HasPrefix(c.key, "CONSTRAINT ") {
+			return nil, fmt.Errorf("unnamed constraint %q on table %s can't be dropped. Give it a name with CONSTRAINT", c.text, d.name)
+		}
+		tokens, err := tokenizeSpannerDdl(c.text)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s", table, c.text[tokens[1].start:tokens[1].end]))
+	}
+
+	desiredColumns := spannerDdlElementsByKey(d.columns)
+	for _, c := range o.columns {
+		if _, ok := desiredColumns[c.key]; !ok {
+			tokens, err := tokenizeSpannerDdl(c.text)
+			if err != nil {
+				return nil, err
+			}
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", table, c.text[tokens[0].start:tokens[0].end]))
+		}
+	}
+
+	currentColumns := spannerDdlElementsByKey(o.columns)
+	for _, c := range d.columns {
+		oc, ok := currentColumns[c.key]
+		if !ok {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", table, c.text))
+			continue
+		}
+		if oc.norm == c.norm {
+			continue
+		}
+
+		oldDef, oldOptions, err := splitSpannerColumnOptions(oc)
+		if err != nil {
+			return nil, err
+		}
+		newDef, newOptions, err := splitSpannerColumnOptions(c)
+		if err != nil {
+			return nil, err
+		}
+		if oldDef.norm != newDef.norm {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s", table, newDef.text))
+		}
+		if !spannerDdlElementsEqual(oldOptions, newOptions) {
+			tokens, err := tokenizeSpannerDdl(c.text)
+			if err != nil {
+				return nil, err
+			}
+			column := c.text[tokens[0].start:tokens[0].end]
+			if newOptions == nil {
+				keys, err := spannerDdlOptionKeys(oldOptions.text)
+				if err != nil {
+					return nil, err
+				}
+				var reset []string
+				for _, k := range keys {
+					reset = append(reset, k+" = NULL")
+				}
+				statements = append(statements, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET OPTIONS (%s)", table, column, strings.Join(reset, ", ")))
+			} else {
+				statements = append(statements, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET %s", table, column, newOptions.text))
+			}
+		}
+	}
+
+	currentConstraints := spannerDdlElementsByKey(o.constraints)
+	for _, c := range d.constraints {
+		if oc, ok := currentConstraints[c.key]; ok && oc.norm == c.norm {
+			continue
+		}
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD %s", table, c.text))
+	}
+
+	if !spannerDdlElementsEqual(o.rowDeletionPolicy, d.rowDeletionPolicy) {
+		switch {
+		case d.rowDeletionPolicy == nil:
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s DROP ROW DELETION POLICY", table))
+		case o.rowDeletionPolicy == nil:
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD %s", table, d.rowDeletionPolicy.text))
+		default:
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s REPLACE %s", table, d.rowDeletionPolicy.text))
+		}
+	}
+
+	return statements, nil
+}
+
+func spannerDdlElementsByKey(elements []spannerDdlElement) map[string]spannerDdlElement {
+	m := make(map[string]spannerDdlElement)
+	for _, e := range elements {
+		m[e.key] = e
+	}
+	return m
+}
+
+func spannerDdlElementsEqual(a, b *spannerDdlElement) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.norm == b.norm
+}
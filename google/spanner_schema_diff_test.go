@@ -0,0 +1,149 @@
+package google
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffSpannerSchema(t *testing.T) {
+	t.Parallel()
+
+	// Statements as formatted by GetDatabaseDdl.
+	current := []string{
+		"CREATE TABLE Singers (\n  SingerId INT64 NOT NULL,\n  FirstName STRING(1024),\n  LastName STRING(1024),\n) PRIMARY KEY(SingerId)",
+		"CREATE TABLE Albums (\n  SingerId INT64 NOT NULL,\n  AlbumId INT64 NOT NULL,\n  Title STRING(MAX),\n) PRIMARY KEY(SingerId, AlbumId),\n  INTERLEAVE IN PARENT Singers ON DELETE CASCADE",
+		"CREATE INDEX AlbumsByTitle ON Albums(Title)",
+		"CREATE CHANGE STREAM Everything FOR ALL",
+		"ALTER DATABASE `db` SET OPTIONS (\n  version_retention_period = '1d'\n)",
+	}
+
+	cases := map[string]struct {
+		Desired     []string
+		Expected    []string
+		ExpectError bool
+	}{
+		"unchanged, differently formatted": {
+			Desired: []string{
+				"create table Singers (SingerId INT64 NOT NULL, FirstName STRING(1024), LastName STRING(1024)) PRIMARY KEY (SingerId)",
+				"CREATE TABLE Albums (SingerId INT64 NOT NULL, AlbumId INT64 NOT NULL, Title STRING(MAX)) PRIMARY KEY (SingerId, AlbumId), INTERLEAVE IN PARENT Singers ON DELETE CASCADE",
+				"CREATE INDEX AlbumsByTitle ON Albums (Title)",
+				"CREATE CHANGE STREAM `Everything` FOR ALL",
+			},
+		},
+		"columns": {
+			Desired: []string{
+				"CREATE TABLE Singers (SingerId INT64 NOT NULL, FirstName STRING(MAX), BirthDate DATE) PRIMARY KEY (SingerId)",
+				"CREATE TABLE Albums (SingerId INT64 NOT NULL, AlbumId INT64 NOT NULL, Title STRING(MAX)) PRIMARY KEY (SingerId, AlbumId), INTERLEAVE IN PARENT Singers ON DELETE CASCADE",
+				"CREATE INDEX AlbumsByTitle ON Albums (Title)",
+				"CREATE CHANGE STREAM Everything FOR ALL",
+			},
+			Expected: []string{
+				"ALTER TABLE Singers DROP COLUMN LastName",
+				"ALTER TABLE Singers ALTER COLUMN FirstName STRING(MAX)",
+				"ALTER TABLE Singers ADD COLUMN BirthDate DATE",
+			},
+		},
+		"indexes and change streams": {
+			Desired: []string{
+				"CREATE TABLE Singers (SingerId INT64 NOT NULL, FirstName STRING(1024), LastName STRING(1024)) PRIMARY KEY (SingerId)",
+				"CREATE TABLE Albums (SingerId INT64 NOT NULL, AlbumId INT64 NOT NULL, Title STRING(MAX)) PRIMARY KEY (SingerId, AlbumId), INTERLEAVE IN PARENT Singers ON DELETE CASCADE",
+				"CREATE UNIQUE INDEX AlbumsByTitle ON Albums (Title)",
+				"CREATE INDEX SingersByName ON Singers (LastName, FirstName)",
+				"CREATE CHANGE STREAM Everything FOR Singers OPTIONS (retention_period = '36h')",
+			},
+			Expected: []string{
+				"DROP INDEX AlbumsByTitle",
+				"CREATE UNIQUE INDEX AlbumsByTitle ON Albums (Title)",
+				"CREATE INDEX SingersByName ON Singers (LastName, FirstName)",
+				"ALTER CHANGE STREAM Everything SET FOR Singers",
+				"ALTER CHANGE STREAM Everything SET OPTIONS (retention_period = '36h')",
+			},
+		},
+		"drop and create tables": {
+			Desired: []string{
+				"CREATE TABLE Singers (SingerId INT64 NOT NULL, FirstName STRING(1024), LastName STRING(1024)) PRIMARY KEY (SingerId)",
+				"CREATE TABLE Concerts (ConcertId INT64 NOT NULL, Tags ARRAY<STRING(MAX)>, CONSTRAINT FK_Singer FOREIGN KEY (SingerId) REFERENCES Singers (SingerId)) PRIMARY KEY (ConcertId), ROW DELETION POLICY (OLDER_THAN(ts, INTERVAL 30 DAY))",
+			},
+			Expected: []string{
+				"DROP CHANGE STREAM Everything",
+				"DROP INDEX AlbumsByTitle",
+				"DROP TABLE Albums",
+				"CREATE TABLE Concerts (ConcertId INT64 NOT NULL, Tags ARRAY<STRING(MAX)>, CONSTRAINT FK_Singer FOREIGN KEY (SingerId) REFERENCES Singers (SingerId)) PRIMARY KEY (ConcertId), ROW DELETION POLICY (OLDER_THAN(ts, INTERVAL 30 DAY))",
+			},
+		},
+		"primary key change": {
+			Desired: []string{
+				"CREATE TABLE Singers (SingerId INT64 NOT NULL, FirstName STRING(1024), LastName STRING(1024)) PRIMARY KEY (SingerId, FirstName)",
+			},
+			ExpectError: true,
+		},
+	}
+
+	for tn, tc := range cases {
+		got, err := diffSpannerSchema(current, tc.Desired)
+		if tc.ExpectError {
+			if err == nil {
+				t.Errorf("%s: expected an error", tn)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tn, err)
+			continue
+		}
+		if len(got) == 0 && len(tc.Expected) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.Expected) {
+			t.Errorf("%s: expected\n%q\ngot\n%q", tn, tc.Expected, got)
+		}
+	}
+}
+
+func TestDiffSpannerTable(t *testing.T) {
+	t.Parallel()
+
+	current := []string{
+		"CREATE TABLE Events (\n  Id STRING(36) NOT NULL,\n  Ts TIMESTAMP OPTIONS (\n    allow_commit_timestamp = true\n  ),\n  Amount INT64,\n  CONSTRAINT Positive CHECK(Amount > 0),\n) PRIMARY KEY(Id),\n  ROW DELETION POLICY (OLDER_THAN(Ts, INTERVAL 7 DAY))",
+	}
+	desired := []string{
+		"CREATE TABLE Events (Id STRING(36) NOT NULL, Ts TIMESTAMP, Amount INT64 NOT NULL, CONSTRAINT Positive CHECK (Amount >= 0)) PRIMARY KEY (Id)",
+	}
+	expected := []string{
+		"ALTER TABLE Events DROP CONSTRAINT Positive",
+		"ALTER TABLE Events ALTER COLUMN Ts SET OPTIONS (allow_commit_timestamp = NULL)",
+		"ALTER TABLE Events ALTER COLUMN Amount INT64 NOT NULL",
+		"ALTER TABLE Events ADD CONSTRAINT Positive CHECK (Amount >= 0)",
+		"ALTER TABLE Events DROP ROW DELETION POLICY",
+	}
+
+	got, err := diffSpannerSchema(current, desired)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected\n%q\ngot\n%q", expected, got)
+	}
+}
+
+func TestPlanSpannerDatabaseSchema_allowDrop(t *testing.T) {
+	t.Parallel()
+
+	current := []string{
+		"CREATE TABLE A (Id INT64, Name STRING(MAX)) PRIMARY KEY (Id)",
+		"CREATE INDEX AByName ON A (Name)",
+	}
+
+	if _, err := planSpannerDatabaseSchema(current, []string{"CREATE TABLE A (Id INT64) PRIMARY KEY (Id)"}, false); err == nil {
+		t.Errorf("expected dropping a column to fail without allow_drop")
+	}
+	if _, err := planSpannerDatabaseSchema(current, nil, false); err == nil {
+		t.Errorf("expected dropping a table to fail without allow_drop")
+	}
+	if _, err := planSpannerDatabaseSchema(current, current[:1], false); err != nil {
+		t.Errorf("expected dropping an index to succeed without allow_drop, got %s", err)
+	}
+	if _, err := planSpannerDatabaseSchema(current, nil, true); err != nil {
+		t.Errorf("expected dropping a table to succeed with allow_drop, got %s", err)
+	}
+}
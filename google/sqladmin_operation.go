@@ -150,3 +150,43 @@ func IsSqlInternalError(err error) (bool, string) {
 	}
 	return false, ""
 }
+
+// sqlAdminProgressOperationWaiter logs the state of the operation every time it
+// is polled, so that long running operations such as major version upgrades
+// show progress in the logs.
+type sqlAdminProgressOperationWaiter struct {
+	*SqlAdminOperationWaiter
+	activity string
+	start    time.Time
+}
+
+func (w *sqlAdminProgressOperationWaiter) QueryOp() (interface{}, error) {
+	op, err := w.SqlAdminOperationWaiter.QueryOp()
+	if err == nil {
+		if sqlOp, ok := op.(*sqladmin.Operation); ok {
+			log.Printf("[INFO] %s: operation %s is %s after %s", w.activity, sqlOp.Name, sqlOp.Status, time.Since(w.start).Round(time.Second))
+		}
+	}
+	return op, err
+}
+
+// sqlAdminOperationWaitWithProgress waits for the operation like
+// SqlAdminOperationWaitTime, logging its progress, and returns the completed
+// operation.
+func sqlAdminOperationWaitWithProgress(config *transport_tpg.Config, op *sqladmin.Operation, project, activity, userAgent string, timeout time.Duration) (*sqladmin.Operation, error) {
+	w := &sqlAdminProgressOperationWaiter{
+		SqlAdminOperationWaiter: &SqlAdminOperationWaiter{
+			Service: config.NewSqlAdminClient(userAgent),
+			Project: project,
+		},
+		activity: activity,
+		start:    time.Now(),
+	}
+	if err := w.SetOp(op); err != nil {
+		return nil, err
+	}
+	if err := tpgresource.OperationWait(w, activity, timeout, config.PollInterval); err != nil {
+		return w.Op, err
+	}
+	return w.Op, nil
+}
@@ -0,0 +1,105 @@
+package tpgresource
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+
+	transport_tpg "github.com/hashicorp/terraform-provider-google/google/transport"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	SecretStateModePlaintext = "plaintext"
+	SecretStateModeHash      = "hash"
+
+	secretStateHashPrefix = "$sha256$"
+	secretStateSaltLength = 16
+)
+
+// SecretStateModeSchema is the per-resource override of the provider's
+// secret_state_mode setting.
+func SecretStateModeSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		ValidateFunc: validation.StringInSlice([]string{SecretStateModePlaintext, SecretStateModeHash}, false),
+		Description: `How secret values of this resource are stored in state. With "plaintext" they are stored as is,
+with "hash" only a salted hash of them is stored. If it is not provided, the provider's secret_state_mode is used.`,
+	}
+}
+
+// SecretStateHashed reports whether secret values should be hashed in state,
+// taking the resource's secret_state_mode over the provider's.
+func SecretStateHashed(d TerraformResourceData, config *transport_tpg.Config) bool {
+	if v, ok := d.GetOk("secret_state_mode"); ok {
+		return v.(string) == SecretStateModeHash
+	}
+	return config.SecretStateMode == SecretStateModeHash
+}
+
+// SetSecretState stores a secret value in field according to the secret state
+// mode. If the field already holds a hash of the same value, the hash is kept
+// so that state doesn't change on every refresh. A value that is already a
+// hash can't be recovered, so it is left alone in either mode.
+func SetSecretState(d TerraformResourceData, config *transport_tpg.Config, field, value string) error {
+	if IsSecretStateHash(value) {
+		return nil
+	}
+	if !SecretStateHashed(d, config) {
+		return d.Set(field, value)
+	}
+	if current, ok := d.Get(field).(string); ok && SecretStateHashMatches(current, value) {
+		return nil
+	}
+	hash, err := HashSecretState(value)
+	if err != nil {
+		return err
+	}
+	return d.Set(field, hash)
+}
+
+// SecretStateDiffSuppress suppresses the diff of a secret field whose state
+// holds a hash of the configured value.
+func SecretStateDiffSuppress(_, old, new string, _ *schema.ResourceData) bool {
+	return IsSecretStateHash(old) && SecretStateHashMatches(old, new)
+}
+
+// HashSecretState returns a salted hash of value, in the form
+// $sha256$<hex salt>$<hex digest>.
+func HashSecretState(value string) (string, error) {
+	salt := make([]byte, secretStateSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	return secretStateHash(salt, value), nil
+}
+
+func IsSecretStateHash(v string) bool {
+	return strings.HasPrefix(v, secretStateHashPrefix)
+}
+
+// SecretStateHashMatches reports whether hash is a hash of value.
+func SecretStateHashMatches(hash, value string) bool {
+	if !IsSecretStateHash(hash) {
+		return false
+	}
+	parts := strings.Split(strings.TrimPrefix(hash, secretStateHashPrefix), "$")
+	if len(parts) != 2 {
+		return false
+	}
+	salt, err := hex.DecodeString(parts[0])
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(secretStateHash(salt, value)), []byte(hash)) == 1
+}
+
+func secretStateHash(salt []byte, value string) string {
+	sum := sha256.Sum256(append(append([]byte{}, salt...), value...))
+	return secretStateHashPrefix + hex.EncodeToString(salt) + "$" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,151 @@
+package tpgresource
+
+import (
+	"testing"
+
+	transport_tpg "github.com/hashicorp/terraform-provider-google/google/transport"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestSecretStateHashMatches(t *testing.T) {
+	hash, err := HashSecretState("hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := HashSecretState("hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash == other {
+		t.Errorf("expected hashes of the same value to be salted differently, got %q twice", hash)
+	}
+
+	cases := map[string]struct {
+		Hash, Value string
+		Expected    bool
+	}{
+		"same value":      {Hash: hash, Value: "hunter2", Expected: true},
+		"other salt":      {Hash: other, Value: "hunter2", Expected: true},
+		"different value": {Hash: hash, Value: "hunter3", Expected: false},
+		"empty value":     {Hash: hash, Value: "", Expected: false},
+		"plaintext":       {Hash: "hunter2", Value: "hunter2", Expected: false},
+		"malformed hash":  {Hash: "$sha256$nothex$abc", Value: "hunter2", Expected: false},
+		"truncated hash":  {Hash: "$sha256$00", Value: "hunter2", Expected: false},
+		"empty hash":      {Hash: "", Value: "", Expected: false},
+	}
+
+	for tn, tc := range cases {
+		if got := SecretStateHashMatches(tc.Hash, tc.Value); got != tc.Expected {
+			t.Errorf("%s: expected %t, got %t", tn, tc.Expected, got)
+		}
+	}
+}
+
+func TestSetSecretState(t *testing.T) {
+	resourceSchema := map[string]*schema.Schema{
+		"password": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"secret_state_mode": SecretStateModeSchema(),
+	}
+
+	cases := map[string]struct {
+		ProviderMode string
+		ResourceMode string
+		Current      string
+		Value        string
+		ExpectHash   bool
+		ExpectValue  string
+	}{
+		"plaintext by default": {
+			Value:       "hunter2",
+			ExpectValue: "hunter2",
+		},
+		"hash from provider": {
+			ProviderMode: SecretStateModeHash,
+			Value:        "hunter2",
+			ExpectHash:   true,
+		},
+		"resource overrides provider": {
+			ProviderMode: SecretStateModeHash,
+			ResourceMode: SecretStateModePlaintext,
+			Value:        "hunter2",
+			ExpectValue:  "hunter2",
+		},
+		"hash from resource": {
+			ResourceMode: SecretStateModeHash,
+			Value:        "hunter2",
+			ExpectHash:   true,
+		},
+		"existing hash is not rehashed": {
+			ResourceMode: SecretStateModeHash,
+			Current:      "$sha256$00$0f5e8f9db2c7e5cef32ec7e1bb4b5a0a8d0c0a3b6fe4d8a2cd7e1d4c3b2a1908",
+			Value:        "$sha256$00$0f5e8f9db2c7e5cef32ec7e1bb4b5a0a8d0c0a3b6fe4d8a2cd7e1d4c3b2a1908",
+			ExpectValue:  "$sha256$00$0f5e8f9db2c7e5cef32ec7e1bb4b5a0a8d0c0a3b6fe4d8a2cd7e1d4c3b2a1908",
+		},
+	}
+
+	for tn, tc := range cases {
+		d := schema.TestResourceDataRaw(t, resourceSchema, map[string]interface{}{
+			"password":          tc.Current,
+			"secret_state_mode": tc.ResourceMode,
+		})
+		config := &transport_tpg.Config{SecretStateMode: tc.ProviderMode}
+
+		if err := SetSecretState(d, config, "password", tc.Value); err != nil {
+			t.Fatalf("%s: %s", tn, err)
+		}
+
+		got := d.Get("password").(string)
+		if tc.ExpectHash {
+			if !SecretStateHashMatches(got, tc.Value) {
+				t.Errorf("%s: expected a hash of %q, got %q", tn, tc.Value, got)
+			}
+			continue
+		}
+		if got != tc.ExpectValue {
+			t.Errorf("%s: expected %q, got %q", tn, tc.ExpectValue, got)
+		}
+	}
+
+	// Refreshing an unchanged value keeps the existing hash.
+	d := schema.TestResourceDataRaw(t, resourceSchema, map[string]interface{}{
+		"secret_state_mode": SecretStateModeHash,
+	})
+	config := &transport_tpg.Config{}
+	if err := SetSecretState(d, config, "password", "hunter2"); err != nil {
+		t.Fatal(err)
+	}
+	first := d.Get("password").(string)
+	if err := SetSecretState(d, config, "password", "hunter2"); err != nil {
+		t.Fatal(err)
+	}
+	if second := d.Get("password").(string); second != first {
+		t.Errorf("expected hash %q to be kept, got %q", first, second)
+	}
+}
+
+func TestSecretStateDiffSuppress(t *testing.T) {
+	hash, err := HashSecretState("hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		Old, New string
+		Expected bool
+	}{
+		"hash of new value":     {Old: hash, New: "hunter2", Expected: true},
+		"hash of another value": {Old: hash, New: "hunter3", Expected: false},
+		"cleared value":         {Old: hash, New: "", Expected: false},
+		"plaintext change":      {Old: "hunter2", New: "hunter3", Expected: false},
+	}
+
+	for tn, tc := range cases {
+		if got := SecretStateDiffSuppress("password", tc.Old, tc.New, nil); got != tc.Expected {
+			t.Errorf("%s: expected %t, got %t", tn, tc.Expected, got)
+		}
+	}
+}
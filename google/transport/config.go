@@ -169,6 +169,14 @@ type Config struct {
 	UserProjectOverride                bool
 	RequestReason                      string
 	RequestTimeout                     time.Duration
+	// SecretStateMode controls whether secret values are stored in state as
+	// is, or as salted hashes. See tpgresource.SetSecretState.
+	SecretStateMode string
+	// BigQueryDryRun enables dry running the SQL of BigQuery resources during
+	// plan, failing the plan if the dry run estimates more than
+	// BigQueryDryRunMaxBytesBilled bytes processed, when that is set.
+	BigQueryDryRun               bool
+	BigQueryDryRunMaxBytesBilled int64
 	// PollInterval is passed to resource.StateChangeConf in common_operation.go
 	// It controls the interval at which we poll for successful operations
 	PollInterval time.Duration